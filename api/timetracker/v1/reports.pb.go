@@ -0,0 +1,582 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v4.25.3
+// source: api/timetracker/v1/reports.proto
+
+package timetrackerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// границы периода в формате "2006-01-02" или "2006-01-02 15:04:05"
+type PersonWorktimeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PersonId string `protobuf:"bytes,1,opt,name=person_id,json=personId,proto3" json:"person_id,omitempty"`
+	Start    string `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End      string `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *PersonWorktimeRequest) Reset() {
+	*x = PersonWorktimeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_timetracker_v1_reports_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PersonWorktimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonWorktimeRequest) ProtoMessage() {}
+
+func (x *PersonWorktimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_timetracker_v1_reports_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonWorktimeRequest.ProtoReflect.Descriptor instead.
+func (*PersonWorktimeRequest) Descriptor() ([]byte, []int) {
+	return file_api_timetracker_v1_reports_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PersonWorktimeRequest) GetPersonId() string {
+	if x != nil {
+		return x.PersonId
+	}
+	return ""
+}
+
+func (x *PersonWorktimeRequest) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *PersonWorktimeRequest) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+type TaskTime struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TaskId   string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TaskName string `protobuf:"bytes,2,opt,name=task_name,json=taskName,proto3" json:"task_name,omitempty"`
+	// длительность в виде "12:30"
+	TotalTime string `protobuf:"bytes,3,opt,name=total_time,json=totalTime,proto3" json:"total_time,omitempty"`
+}
+
+func (x *TaskTime) Reset() {
+	*x = TaskTime{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_timetracker_v1_reports_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskTime) ProtoMessage() {}
+
+func (x *TaskTime) ProtoReflect() protoreflect.Message {
+	mi := &file_api_timetracker_v1_reports_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskTime.ProtoReflect.Descriptor instead.
+func (*TaskTime) Descriptor() ([]byte, []int) {
+	return file_api_timetracker_v1_reports_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TaskTime) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskTime) GetTaskName() string {
+	if x != nil {
+		return x.TaskName
+	}
+	return ""
+}
+
+func (x *TaskTime) GetTotalTime() string {
+	if x != nil {
+		return x.TotalTime
+	}
+	return ""
+}
+
+type PersonWorktimeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tasks []*TaskTime `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+}
+
+func (x *PersonWorktimeResponse) Reset() {
+	*x = PersonWorktimeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_timetracker_v1_reports_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PersonWorktimeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PersonWorktimeResponse) ProtoMessage() {}
+
+func (x *PersonWorktimeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_timetracker_v1_reports_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PersonWorktimeResponse.ProtoReflect.Descriptor instead.
+func (*PersonWorktimeResponse) Descriptor() ([]byte, []int) {
+	return file_api_timetracker_v1_reports_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PersonWorktimeResponse) GetTasks() []*TaskTime {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type TeamWorktimeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TeamId string `protobuf:"bytes,1,opt,name=team_id,json=teamId,proto3" json:"team_id,omitempty"`
+	Start  string `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End    string `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+}
+
+func (x *TeamWorktimeRequest) Reset() {
+	*x = TeamWorktimeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_timetracker_v1_reports_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TeamWorktimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamWorktimeRequest) ProtoMessage() {}
+
+func (x *TeamWorktimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_timetracker_v1_reports_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamWorktimeRequest.ProtoReflect.Descriptor instead.
+func (*TeamWorktimeRequest) Descriptor() ([]byte, []int) {
+	return file_api_timetracker_v1_reports_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TeamWorktimeRequest) GetTeamId() string {
+	if x != nil {
+		return x.TeamId
+	}
+	return ""
+}
+
+func (x *TeamWorktimeRequest) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *TeamWorktimeRequest) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+type MemberTime struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PersonId  string      `protobuf:"bytes,1,opt,name=person_id,json=personId,proto3" json:"person_id,omitempty"`
+	Surname   string      `protobuf:"bytes,2,opt,name=surname,proto3" json:"surname,omitempty"`
+	Name      string      `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	TotalTime string      `protobuf:"bytes,4,opt,name=total_time,json=totalTime,proto3" json:"total_time,omitempty"`
+	Tasks     []*TaskTime `protobuf:"bytes,5,rep,name=tasks,proto3" json:"tasks,omitempty"`
+}
+
+func (x *MemberTime) Reset() {
+	*x = MemberTime{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_timetracker_v1_reports_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MemberTime) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberTime) ProtoMessage() {}
+
+func (x *MemberTime) ProtoReflect() protoreflect.Message {
+	mi := &file_api_timetracker_v1_reports_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberTime.ProtoReflect.Descriptor instead.
+func (*MemberTime) Descriptor() ([]byte, []int) {
+	return file_api_timetracker_v1_reports_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MemberTime) GetPersonId() string {
+	if x != nil {
+		return x.PersonId
+	}
+	return ""
+}
+
+func (x *MemberTime) GetSurname() string {
+	if x != nil {
+		return x.Surname
+	}
+	return ""
+}
+
+func (x *MemberTime) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MemberTime) GetTotalTime() string {
+	if x != nil {
+		return x.TotalTime
+	}
+	return ""
+}
+
+func (x *MemberTime) GetTasks() []*TaskTime {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type TeamWorktimeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Members []*MemberTime `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+}
+
+func (x *TeamWorktimeResponse) Reset() {
+	*x = TeamWorktimeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_timetracker_v1_reports_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TeamWorktimeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamWorktimeResponse) ProtoMessage() {}
+
+func (x *TeamWorktimeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_timetracker_v1_reports_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamWorktimeResponse.ProtoReflect.Descriptor instead.
+func (*TeamWorktimeResponse) Descriptor() ([]byte, []int) {
+	return file_api_timetracker_v1_reports_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TeamWorktimeResponse) GetMembers() []*MemberTime {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+var File_api_timetracker_v1_reports_proto protoreflect.FileDescriptor
+
+var file_api_timetracker_v1_reports_proto_rawDesc = []byte{
+	0x0a, 0x20, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65,
+	0x72, 0x2f, 0x76, 0x31, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x0e, 0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e,
+	0x76, 0x31, 0x22, 0x5c, 0x0a, 0x15, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x57, 0x6f, 0x72, 0x6b,
+	0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70,
+	0x65, 0x72, 0x73, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x6e, 0x64,
+	0x22, 0x5f, 0x0a, 0x08, 0x54, 0x61, 0x73, 0x6b, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x17, 0x0a, 0x07,
+	0x74, 0x61, 0x73, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74,
+	0x61, 0x73, 0x6b, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x61, 0x73, 0x6b, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x61, 0x73, 0x6b, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x69, 0x6d,
+	0x65, 0x22, 0x48, 0x0a, 0x16, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x57, 0x6f, 0x72, 0x6b, 0x74,
+	0x69, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x05, 0x74,
+	0x61, 0x73, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74, 0x69, 0x6d,
+	0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x61, 0x73, 0x6b,
+	0x54, 0x69, 0x6d, 0x65, 0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x22, 0x56, 0x0a, 0x13, 0x54,
+	0x65, 0x61, 0x6d, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x65, 0x61, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x65, 0x61, 0x6d, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x65, 0x6e, 0x64, 0x22, 0xa6, 0x01, 0x0a, 0x0a, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x54, 0x69,
+	0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x49, 0x64, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x75, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x73, 0x75, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x05,
+	0x74, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x74, 0x69,
+	0x6d, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x61, 0x73,
+	0x6b, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x22, 0x4c, 0x0a, 0x14,
+	0x54, 0x65, 0x61, 0x6d, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x54, 0x69, 0x6d,
+	0x65, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x32, 0xcb, 0x01, 0x0a, 0x0d, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x5f, 0x0a, 0x0e,
+	0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x25,
+	0x2e, 0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x65, 0x72, 0x73, 0x6f, 0x6e, 0x57, 0x6f, 0x72,
+	0x6b, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a,
+	0x0c, 0x54, 0x65, 0x61, 0x6d, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x23, 0x2e,
+	0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x54,
+	0x65, 0x61, 0x6d, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x24, 0x2e, 0x74, 0x69, 0x6d, 0x65, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x61, 0x6d, 0x57, 0x6f, 0x72, 0x6b, 0x74, 0x69, 0x6d, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2e, 0x5a, 0x2c, 0x74, 0x69, 0x6d, 0x65,
+	0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x74, 0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x3b, 0x74, 0x69, 0x6d, 0x65, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x65, 0x72, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_api_timetracker_v1_reports_proto_rawDescOnce sync.Once
+	file_api_timetracker_v1_reports_proto_rawDescData = file_api_timetracker_v1_reports_proto_rawDesc
+)
+
+func file_api_timetracker_v1_reports_proto_rawDescGZIP() []byte {
+	file_api_timetracker_v1_reports_proto_rawDescOnce.Do(func() {
+		file_api_timetracker_v1_reports_proto_rawDescData = protoimpl.X.CompressGZIP(file_api_timetracker_v1_reports_proto_rawDescData)
+	})
+	return file_api_timetracker_v1_reports_proto_rawDescData
+}
+
+var file_api_timetracker_v1_reports_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_api_timetracker_v1_reports_proto_goTypes = []any{
+	(*PersonWorktimeRequest)(nil),  // 0: timetracker.v1.PersonWorktimeRequest
+	(*TaskTime)(nil),               // 1: timetracker.v1.TaskTime
+	(*PersonWorktimeResponse)(nil), // 2: timetracker.v1.PersonWorktimeResponse
+	(*TeamWorktimeRequest)(nil),    // 3: timetracker.v1.TeamWorktimeRequest
+	(*MemberTime)(nil),             // 4: timetracker.v1.MemberTime
+	(*TeamWorktimeResponse)(nil),   // 5: timetracker.v1.TeamWorktimeResponse
+}
+var file_api_timetracker_v1_reports_proto_depIdxs = []int32{
+	1, // 0: timetracker.v1.PersonWorktimeResponse.tasks:type_name -> timetracker.v1.TaskTime
+	1, // 1: timetracker.v1.MemberTime.tasks:type_name -> timetracker.v1.TaskTime
+	4, // 2: timetracker.v1.TeamWorktimeResponse.members:type_name -> timetracker.v1.MemberTime
+	0, // 3: timetracker.v1.ReportService.PersonWorktime:input_type -> timetracker.v1.PersonWorktimeRequest
+	3, // 4: timetracker.v1.ReportService.TeamWorktime:input_type -> timetracker.v1.TeamWorktimeRequest
+	2, // 5: timetracker.v1.ReportService.PersonWorktime:output_type -> timetracker.v1.PersonWorktimeResponse
+	5, // 6: timetracker.v1.ReportService.TeamWorktime:output_type -> timetracker.v1.TeamWorktimeResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_api_timetracker_v1_reports_proto_init() }
+func file_api_timetracker_v1_reports_proto_init() {
+	if File_api_timetracker_v1_reports_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_api_timetracker_v1_reports_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*PersonWorktimeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_timetracker_v1_reports_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*TaskTime); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_timetracker_v1_reports_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*PersonWorktimeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_timetracker_v1_reports_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*TeamWorktimeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_timetracker_v1_reports_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*MemberTime); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_timetracker_v1_reports_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*TeamWorktimeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_api_timetracker_v1_reports_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_timetracker_v1_reports_proto_goTypes,
+		DependencyIndexes: file_api_timetracker_v1_reports_proto_depIdxs,
+		MessageInfos:      file_api_timetracker_v1_reports_proto_msgTypes,
+	}.Build()
+	File_api_timetracker_v1_reports_proto = out.File
+	file_api_timetracker_v1_reports_proto_rawDesc = nil
+	file_api_timetracker_v1_reports_proto_goTypes = nil
+	file_api_timetracker_v1_reports_proto_depIdxs = nil
+}
@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.3
+// source: api/timetracker/v1/reports.proto
+
+package timetrackerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReportService_PersonWorktime_FullMethodName = "/timetracker.v1.ReportService/PersonWorktime"
+	ReportService_TeamWorktime_FullMethodName   = "/timetracker.v1.ReportService/TeamWorktime"
+)
+
+// ReportServiceClient is the client API for ReportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReportService отчеты о времени работы
+type ReportServiceClient interface {
+	// PersonWorktime время работы человека по задачам за период
+	PersonWorktime(ctx context.Context, in *PersonWorktimeRequest, opts ...grpc.CallOption) (*PersonWorktimeResponse, error)
+	// TeamWorktime время работы участников команды за период
+	TeamWorktime(ctx context.Context, in *TeamWorktimeRequest, opts ...grpc.CallOption) (*TeamWorktimeResponse, error)
+}
+
+type reportServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReportServiceClient(cc grpc.ClientConnInterface) ReportServiceClient {
+	return &reportServiceClient{cc}
+}
+
+func (c *reportServiceClient) PersonWorktime(ctx context.Context, in *PersonWorktimeRequest, opts ...grpc.CallOption) (*PersonWorktimeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PersonWorktimeResponse)
+	err := c.cc.Invoke(ctx, ReportService_PersonWorktime_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) TeamWorktime(ctx context.Context, in *TeamWorktimeRequest, opts ...grpc.CallOption) (*TeamWorktimeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TeamWorktimeResponse)
+	err := c.cc.Invoke(ctx, ReportService_TeamWorktime_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReportServiceServer is the server API for ReportService service.
+// All implementations must embed UnimplementedReportServiceServer
+// for forward compatibility.
+//
+// ReportService отчеты о времени работы
+type ReportServiceServer interface {
+	// PersonWorktime время работы человека по задачам за период
+	PersonWorktime(context.Context, *PersonWorktimeRequest) (*PersonWorktimeResponse, error)
+	// TeamWorktime время работы участников команды за период
+	TeamWorktime(context.Context, *TeamWorktimeRequest) (*TeamWorktimeResponse, error)
+	mustEmbedUnimplementedReportServiceServer()
+}
+
+// UnimplementedReportServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReportServiceServer struct{}
+
+func (UnimplementedReportServiceServer) PersonWorktime(context.Context, *PersonWorktimeRequest) (*PersonWorktimeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PersonWorktime not implemented")
+}
+func (UnimplementedReportServiceServer) TeamWorktime(context.Context, *TeamWorktimeRequest) (*TeamWorktimeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TeamWorktime not implemented")
+}
+func (UnimplementedReportServiceServer) mustEmbedUnimplementedReportServiceServer() {}
+func (UnimplementedReportServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeReportServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReportServiceServer will
+// result in compilation errors.
+type UnsafeReportServiceServer interface {
+	mustEmbedUnimplementedReportServiceServer()
+}
+
+func RegisterReportServiceServer(s grpc.ServiceRegistrar, srv ReportServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReportServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReportService_ServiceDesc, srv)
+}
+
+func _ReportService_PersonWorktime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PersonWorktimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).PersonWorktime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_PersonWorktime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).PersonWorktime(ctx, req.(*PersonWorktimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_TeamWorktime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TeamWorktimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).TeamWorktime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReportService_TeamWorktime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).TeamWorktime(ctx, req.(*TeamWorktimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReportService_ServiceDesc is the grpc.ServiceDesc for ReportService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReportService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "timetracker.v1.ReportService",
+	HandlerType: (*ReportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PersonWorktime",
+			Handler:    _ReportService_PersonWorktime_Handler,
+		},
+		{
+			MethodName: "TeamWorktime",
+			Handler:    _ReportService_TeamWorktime_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/timetracker/v1/reports.proto",
+}
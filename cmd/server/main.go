@@ -2,33 +2,144 @@ package main
 
 import (
   "context"
+  "errors"
   "fmt"
+  "github.com/jessevdk/go-flags"
+  "log/slog"
+  "net/url"
   "os"
   "os/signal"
+  "slices"
+  "sync"
   "syscall"
   "timetracker/internal/bl"
+  "timetracker/internal/bl/webhooks"
   "timetracker/internal/config"
   "timetracker/internal/config/logger"
   "timetracker/internal/db"
+  "timetracker/internal/db/memory"
+  "timetracker/internal/db/sqlite"
+  "timetracker/internal/io/grpc"
   "timetracker/internal/io/http"
+  "timetracker/internal/io/http/links"
+  "timetracker/internal/utils/keyset"
+  "timetracker/internal/utils/var/endpoint"
 )
 
 func main() {
-  conf, _ := config.InitConfServ()
+  conf, err := config.InitConfServ()
+  if err != nil {
+    var flagsErr *flags.Error
+    if errors.As(err, &flagsErr) {
+      // справку или ошибку разбора флагов go-flags уже вывел
+      if flagsErr.Type == flags.ErrHelp {
+        os.Exit(0)
+      }
+      os.Exit(1)
+    }
+    fmt.Fprintln(os.Stderr, err)
+    os.Exit(1)
+  }
+  if conf.Options.PrintConfig {
+    if err = conf.Print(os.Stdout); err != nil {
+      fmt.Fprintln(os.Stderr, err)
+      os.Exit(1)
+    }
+    return
+  }
 
-  lg := logger.New(conf.Options.Log)
+  level := new(slog.LevelVar)
+  level.Set(conf.Level())
+  lg := logger.New(level)
 
   ctx, cancel := context.WithCancel(context.Background())
   fin := make(chan struct{})
   done := make(chan os.Signal, 1)
   signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-  d := db.New(conf.Options.DbString())
-  blRepo := bl.New(d)
-  fmt.Println(conf.Options.DbString())
-  serv := http.New(conf.Options.ServStr(), lg, blRepo, fin)
+  keys, err := keyset.Load(conf.Options.JwtKeys, conf.Options.JwtIssuer, conf.Options.JwtAudience)
+  if err != nil {
+    lg.Error("ошибка загрузки ключей JWT", slog.String("error", err.Error()))
+    os.Exit(1)
+  }
+
+  lnk, err := links.New(conf.Options.PublicURL, conf.Options.TrustedProxies)
+  if err != nil {
+    lg.Error("ошибка настройки внешнего адреса", slog.String("error", err.Error()))
+    os.Exit(1)
+  }
+
+  storage, dsn, err := conf.Options.Storage()
+  if err != nil {
+    lg.Error("ошибка выбора хранилища", slog.String("error", err.Error()))
+    os.Exit(1)
+  }
+  var store *db.Store
+  var blRepo *bl.BL
+  switch storage {
+  case config.StorageMemory:
+    // один экземпляр без базы: события остаются в шине этого процесса
+    store = memory.New()
+    blRepo = bl.New(store, keys, conf.Policy, lg)
+    lg.Warn("данные хранятся в памяти и будут потеряны при остановке")
+  case config.StorageSQLite:
+    // файл базы открывает один экземпляр, события тоже остаются в его шине
+    s, err := sqlite.Open(dsn)
+    if err != nil {
+      lg.Error("ошибка открытия базы sqlite", slog.String("error", err.Error()))
+      os.Exit(1)
+    }
+    defer s.Close()
+    store = &s.Store
+    blRepo = bl.New(store, keys, conf.Policy, lg)
+  default:
+    d := db.New(dsn)
+    d.SetPool(conf.Options.DbMaxOpen, conf.Options.DbMaxIdle, conf.Options.DbConnLifetime)
+    store = &d.Store
+    blRepo = bl.New(store, keys, conf.Policy, lg)
+    // события задач расходятся по всем экземплярам через LISTEN/NOTIFY
+    blRepo.Bus.SetNotifier(d)
+    go func() {
+      if err := d.Listen(ctx, lg, blRepo.Bus.Receive); err != nil {
+        lg.Error("ошибка получения событий других экземпляров", slog.String("error", err.Error()))
+      }
+    }()
+    // в строке подключения пароль, в лог попадают только схема и хост
+    if u, err := url.Parse(dsn); err == nil && u.Host != "" {
+      lg.Info("подключение к базе", slog.String("scheme", u.Scheme), slog.String("host", u.Host))
+    }
+  }
+  if err = blRepo.Auth.Bootstrap(ctx, conf.Options.BootstrapKey); err != nil {
+    lg.Error("ошибка создания ключа администратора", slog.String("error", err.Error()))
+    os.Exit(1)
+  }
+  if conf.Options.NoWebhooks {
+    lg.Info("отправка вебхуков отключена")
+  } else {
+    go webhooks.NewWorker(store, lg).Run(ctx)
+  }
+  timeouts := http.Timeouts{Read: conf.Options.ReadTimeout, Idle: conf.Options.IdleTimeout}
+  serv := http.New(conf.Options.ServStr(), timeouts, lg, blRepo, lnk, fin)
+  // /info сервис вызывает сам, ссылка строится так же, как остальные внешние ссылки
+  if endpoint.GetInfoUrl, err = lnk.Local(conf.Options.ServStr(), "people.info"); err != nil {
+    lg.Error("ошибка построения адреса /info", slog.String("error", err.Error()))
+    os.Exit(1)
+  }
 
   serv.Run()
+  var grpcServ interface{ Stop(ctx context.Context) }
+  if !conf.Options.NoGrpc {
+    g := grpc.New(conf.Options.GrpcStr(), lg, blRepo)
+    if err = g.Run(); err != nil {
+      lg.Error("ошибка запуска gRPC сервера", slog.String("error", err.Error()))
+      os.Exit(1)
+    }
+    grpcServ = g
+  }
+
+  hup := make(chan os.Signal, 1)
+  signal.Notify(hup, syscall.SIGHUP)
+  go reload(conf, hup, level, blRepo, lg)
 
   lg.Info("Server Started")
 
@@ -37,6 +148,43 @@ func main() {
   lg.Info("Выключение сервера...")
 
   defer cancel()
-  serv.Stop(ctx)
+  // оба сервера выключаются одновременно и дожидаются текущих запросов в пределах общего срока
+  stopCtx, stop := context.WithTimeout(ctx, conf.Options.ShutdownTimeout)
+  defer stop()
+  var wg sync.WaitGroup
+  wg.Add(1)
+  go func() {
+    defer wg.Done()
+    serv.Stop(stopCtx)
+  }()
+  if grpcServ != nil {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      grpcServ.Stop(stopCtx)
+    }()
+  }
+  wg.Wait()
+
+}
 
+// reload по SIGHUP перечитывает настройки и применяет уровень логирования и политику доступа.
+// Остальные параметры применяются только при перезапуске, их изменение попадает в лог.
+// Настройки с ошибкой не применяются, сервер продолжает работать с прежними
+func reload(conf *config.ConfSrv, hup <-chan os.Signal, level *slog.LevelVar, blRepo *bl.BL, lg *slog.Logger) {
+  for range hup {
+    next, err := conf.Reload()
+    if err != nil {
+      lg.Error("ошибка перечитывания настроек, действуют прежние", slog.String("error", err.Error()))
+      continue
+    }
+    level.Set(next.Level())
+    blRepo.Guard.SetPolicy(next.Policy)
+    for _, key := range conf.Changed(next) {
+      if !slices.Contains(config.Reloadable, key) {
+        lg.Warn("параметр изменен, но применится только после перезапуска", slog.String("key", key))
+      }
+    }
+    lg.Info("настройки перечитаны", slog.String("log", next.Options.Log))
+  }
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "io"
+  "net/http"
+  "net/url"
+  "strings"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/io/http/models"
+)
+
+// client обращается к HTTP API от имени человека из настроек
+type client struct {
+  conf *config
+  http *http.Client
+}
+
+func newClient(conf *config) *client {
+  return &client{conf: conf, http: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (c *client) do(method, path string, body, res interface{}) error {
+  var reader io.Reader
+  if body != nil {
+    data, err := json.Marshal(body)
+    if err != nil {
+      return err
+    }
+    reader = bytes.NewReader(data)
+  }
+  req, err := http.NewRequest(method, strings.TrimRight(c.conf.Server, "/")+path, reader)
+  if err != nil {
+    return err
+  }
+  req.Header.Set("Authorization", "Bearer "+c.conf.Token)
+  if c.conf.OrganizationID != "" {
+    req.Header.Set("X-Organization-ID", c.conf.OrganizationID)
+  }
+  if body != nil {
+    req.Header.Set("Content-Type", "application/json")
+  }
+  resp, err := c.http.Do(req)
+  if err != nil {
+    return fmt.Errorf("сервер недоступен: %v", err)
+  }
+  defer resp.Body.Close()
+  data, err := io.ReadAll(resp.Body)
+  if err != nil {
+    return err
+  }
+  if resp.StatusCode >= http.StatusBadRequest {
+    var apiErr models.ErrorResponse
+    if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+      return fmt.Errorf("%s (%d)", apiErr.Error, resp.StatusCode)
+    }
+    return fmt.Errorf("ответ сервера %s", resp.Status)
+  }
+  if res == nil {
+    return nil
+  }
+  return json.Unmarshal(data, res)
+}
+
+// tasks все задачи человека постранично в порядке создания
+func (c *client) tasks() ([]models.TaskResp, error) {
+  var res []models.TaskResp
+  cursor := ""
+  for {
+    query := url.Values{"limit": {"100"}}
+    if cursor != "" {
+      query.Set("cursor", cursor)
+    }
+    var page models.TasksResp
+    if err := c.do(http.MethodGet, "/v1/people/"+c.conf.PersonID+"/tasks?"+query.Encode(), nil, &page); err != nil {
+      return nil, err
+    }
+    res = append(res, page.Tasks...)
+    if page.NextCursor == "" {
+      return res, nil
+    }
+    cursor = page.NextCursor
+  }
+}
+
+func (c *client) createTask(name string) (*models.TaskResp, error) {
+  var task models.TaskResp
+  err := c.do(http.MethodPost, "/v1/people/"+c.conf.PersonID+"/tasks", models.TaskCreate{Name: name}, &task)
+  if err != nil {
+    return nil, err
+  }
+  return &task, nil
+}
+
+// action start, pause или complete задачи
+func (c *client) action(task *models.TaskResp, action string) error {
+  return c.do(http.MethodPost, "/v1/tasks/"+task.IdTask+"/"+action, nil, nil)
+}
+
+// worktime время по задачам за [start, end)
+func (c *client) worktime(start, end time.Time) ([]dto.TaskTimeResult, error) {
+  var res []dto.TaskTimeResult
+  period := models.DateStartEnd{Start: start.Format(time.DateOnly), End: end.Format(time.DateOnly)}
+  err := c.do(http.MethodPost, "/v1/people/"+c.conf.PersonID+"/worktime", period, &res)
+  return res, err
+}
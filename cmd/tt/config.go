@@ -0,0 +1,66 @@
+package main
+
+import (
+  "encoding/json"
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+)
+
+// config настройки клиента, хранятся в json файле с правами 0600
+type config struct {
+  Server         string `json:"server"`
+  PersonID       string `json:"person_id"`
+  Token          string `json:"token"`
+  OrganizationID string `json:"organization_id,omitempty"`
+}
+
+// configPath путь к файлу настроек: --config, TT_CONFIG или <каталог настроек пользователя>/tt/config.json
+func configPath(flag string) (string, error) {
+  if flag != "" {
+    return flag, nil
+  }
+  if env := os.Getenv("TT_CONFIG"); env != "" {
+    return env, nil
+  }
+  dir, err := os.UserConfigDir()
+  if err != nil {
+    return "", fmt.Errorf("не удалось найти каталог настроек: %v", err)
+  }
+  return filepath.Join(dir, "tt", "config.json"), nil
+}
+
+func loadConfig(path string) (*config, error) {
+  var conf config
+  data, err := os.ReadFile(path)
+  if errors.Is(err, os.ErrNotExist) {
+    return &conf, nil
+  }
+  if err != nil {
+    return nil, err
+  }
+  if err = json.Unmarshal(data, &conf); err != nil {
+    return nil, fmt.Errorf("ошибка чтения %s: %v", path, err)
+  }
+  return &conf, nil
+}
+
+func (c *config) save(path string) error {
+  data, err := json.MarshalIndent(c, "", "  ")
+  if err != nil {
+    return err
+  }
+  if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+    return err
+  }
+  return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// check требует настройки, без которых нельзя обратиться к серверу
+func (c *config) check() error {
+  if c.Server == "" || c.PersonID == "" || c.Token == "" {
+    return errors.New("не заданы сервер, человек или токен, выполните tt config --server ... --person ... --token ...")
+  }
+  return nil
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+  "errors"
+  "fmt"
+  "github.com/jessevdk/go-flags"
+  "os"
+  "strings"
+  "time"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils/const/status"
+)
+
+// tt - клиент учета времени для ежедневной работы с таймером из терминала
+type options struct {
+  Config string `short:"c" long:"config" description:"файл настроек, по умолчанию $TT_CONFIG или ~/.config/tt/config.json"`
+}
+
+var opts options
+
+func main() {
+  parser := flags.NewParser(&opts, flags.Default)
+  parser.AddCommand("config", "Настройки клиента", "Сохраняет адрес сервера, id человека и токен (JWT или API-ключ) в файл настроек", &configCmd{})
+  parser.AddCommand("start", "Начать задачу", "Запускает таймер задачи по названию, задача создается, если ее нет. Задача в работе ставится на паузу", &startCmd{})
+  parser.AddCommand("pause", "Пауза", "Ставит на паузу задачу в работе", &pauseCmd{})
+  parser.AddCommand("done", "Завершить задачу", "Завершает задачу по названию или задачу в работе", &doneCmd{})
+  parser.AddCommand("status", "Текущая задача", "Показывает задачу в работе и время за сегодня", &statusCmd{})
+  parser.AddCommand("report", "Отчет", "Время по задачам за сегодня, неделю или период", &reportCmd{})
+  parser.AddCommand("tasks", "Список задач", "Незавершенные задачи, с --all все", &tasksCmd{})
+  parser.AddCommand("completion", "Автодополнение bash", "Печатает скрипт автодополнения: source <(tt completion)", &completionCmd{})
+
+  if _, err := parser.Parse(); err != nil {
+    var flagsErr *flags.Error
+    if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+      os.Exit(0)
+    }
+    os.Exit(1)
+  }
+}
+
+// connect читает настройки и возвращает клиент сервера
+func connect() (*client, error) {
+  path, err := configPath(opts.Config)
+  if err != nil {
+    return nil, err
+  }
+  conf, err := loadConfig(path)
+  if err != nil {
+    return nil, err
+  }
+  if err = conf.check(); err != nil {
+    return nil, err
+  }
+  return newClient(conf), nil
+}
+
+type configCmd struct {
+  Server       string `long:"server" description:"адрес сервера, например https://tt.example.com"`
+  Person       string `long:"person" description:"id человека"`
+  Token        string `long:"token" description:"JWT или API-ключ"`
+  Organization string `long:"org" description:"id организации для вызывающих без организации"`
+}
+
+func (c *configCmd) Execute(_ []string) error {
+  path, err := configPath(opts.Config)
+  if err != nil {
+    return err
+  }
+  conf, err := loadConfig(path)
+  if err != nil {
+    return err
+  }
+  for _, field := range []struct {
+    value string
+    dst   *string
+  }{{c.Server, &conf.Server}, {c.Person, &conf.PersonID}, {c.Token, &conf.Token}, {c.Organization, &conf.OrganizationID}} {
+    if field.value != "" {
+      *field.dst = field.value
+    }
+  }
+  if err = conf.save(path); err != nil {
+    return err
+  }
+  token := "не задан"
+  if conf.Token != "" {
+    token = "задан"
+  }
+  fmt.Printf("%s\nсервер: %s\nчеловек: %s\nтокен: %s\n", path, conf.Server, conf.PersonID, token)
+  return nil
+}
+
+// taskName название задачи, дополняется названиями незавершенных задач с сервера
+type taskName string
+
+func (t *taskName) Complete(match string) []flags.Completion {
+  c, err := connect()
+  if err != nil {
+    return nil
+  }
+  tasks, err := c.tasks()
+  if err != nil {
+    return nil
+  }
+  var res []flags.Completion
+  for _, task := range tasks {
+    if task.TaskStatus != status.Complete && strings.HasPrefix(task.TaskName, match) {
+      res = append(res, flags.Completion{Item: task.TaskName})
+    }
+  }
+  return res
+}
+
+type taskArgs struct {
+  Task []taskName `positional-arg-name:"задача" description:"название задачи, можно из нескольких слов"`
+}
+
+func (a taskArgs) name() string {
+  words := make([]string, 0, len(a.Task))
+  for _, word := range a.Task {
+    words = append(words, string(word))
+  }
+  return strings.Join(words, " ")
+}
+
+// find незавершенная задача с названием name
+func find(tasks []models.TaskResp, name string) *models.TaskResp {
+  for i := range tasks {
+    if tasks[i].TaskName == name && tasks[i].TaskStatus != status.Complete {
+      return &tasks[i]
+    }
+  }
+  return nil
+}
+
+// current задача в работе
+func current(tasks []models.TaskResp) *models.TaskResp {
+  for i := range tasks {
+    if tasks[i].TaskStatus == status.Work {
+      return &tasks[i]
+    }
+  }
+  return nil
+}
+
+type startCmd struct {
+  Args taskArgs `positional-args:"yes" required:"yes"`
+}
+
+func (s *startCmd) Execute(_ []string) error {
+  name := s.Args.name()
+  c, err := connect()
+  if err != nil {
+    return err
+  }
+  tasks, err := c.tasks()
+  if err != nil {
+    return err
+  }
+  if running := current(tasks); running != nil {
+    if running.TaskName == name {
+      fmt.Printf("уже в работе: %s\n", name)
+      return nil
+    }
+    if err = c.action(running, "pause"); err != nil {
+      return err
+    }
+    fmt.Printf("на паузе: %s\n", running.TaskName)
+  }
+  task := find(tasks, name)
+  if task == nil {
+    if task, err = c.createTask(name); err != nil {
+      return err
+    }
+    fmt.Printf("новая задача: %s\n", name)
+  }
+  if err = c.action(task, "start"); err != nil {
+    return err
+  }
+  fmt.Printf("в работе: %s\n", name)
+  return nil
+}
+
+type pauseCmd struct{}
+
+func (p *pauseCmd) Execute(_ []string) error {
+  c, err := connect()
+  if err != nil {
+    return err
+  }
+  tasks, err := c.tasks()
+  if err != nil {
+    return err
+  }
+  running := current(tasks)
+  if running == nil {
+    return errors.New("нет задачи в работе")
+  }
+  if err = c.action(running, "pause"); err != nil {
+    return err
+  }
+  fmt.Printf("на паузе: %s\n", running.TaskName)
+  return nil
+}
+
+type doneCmd struct {
+  Args taskArgs `positional-args:"yes"`
+}
+
+func (d *doneCmd) Execute(_ []string) error {
+  c, err := connect()
+  if err != nil {
+    return err
+  }
+  tasks, err := c.tasks()
+  if err != nil {
+    return err
+  }
+  var task *models.TaskResp
+  if name := d.Args.name(); name != "" {
+    if task = find(tasks, name); task == nil {
+      return fmt.Errorf("нет незавершенной задачи %s", name)
+    }
+  } else if task = current(tasks); task == nil {
+    return errors.New("нет задачи в работе, укажите название")
+  }
+  if err = c.action(task, "complete"); err != nil {
+    return err
+  }
+  fmt.Printf("завершена: %s\n", task.TaskName)
+  return nil
+}
+
+type statusCmd struct{}
+
+func (s *statusCmd) Execute(_ []string) error {
+  c, err := connect()
+  if err != nil {
+    return err
+  }
+  tasks, err := c.tasks()
+  if err != nil {
+    return err
+  }
+  if running := current(tasks); running != nil {
+    fmt.Printf("в работе: %s\n", running.TaskName)
+  } else {
+    fmt.Println("нет задачи в работе")
+  }
+  today := day(time.Now())
+  times, err := c.worktime(today, today.AddDate(0, 0, 1))
+  if err != nil {
+    return err
+  }
+  // идущий интервал сервер учитывает только после паузы или завершения
+  fmt.Printf("сегодня: %s\n", formatDuration(total(times)))
+  return nil
+}
+
+type reportCmd struct {
+  Week bool   `short:"w" long:"week" description:"текущая неделя с понедельника"`
+  From string `long:"from" description:"начало периода, 2006-01-02"`
+  To   string `long:"to" description:"конец периода включительно, 2006-01-02"`
+}
+
+func (r *reportCmd) Execute(_ []string) error {
+  start := day(time.Now())
+  end := start.AddDate(0, 0, 1)
+  switch {
+  case r.From != "" || r.To != "":
+    var err error
+    if start, err = time.ParseInLocation(time.DateOnly, r.From, time.Local); err != nil {
+      return errors.New("некорректное значение --from, пример 2024-01-31")
+    }
+    if r.To != "" {
+      to, err := time.ParseInLocation(time.DateOnly, r.To, time.Local)
+      if err != nil {
+        return errors.New("некорректное значение --to, пример 2024-01-31")
+      }
+      end = to.AddDate(0, 0, 1)
+    }
+  case r.Week:
+    // неделя начинается с понедельника
+    start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+  }
+
+  c, err := connect()
+  if err != nil {
+    return err
+  }
+  times, err := c.worktime(start, end)
+  if err != nil {
+    return err
+  }
+  fmt.Printf("%s - %s\n", start.Format(time.DateOnly), end.AddDate(0, 0, -1).Format(time.DateOnly))
+  for _, t := range times {
+    fmt.Printf("%10s  %s\n", formatDuration(parseInterval(t.TotalTime)), t.TaskName)
+  }
+  fmt.Printf("%10s  всего\n", formatDuration(total(times)))
+  return nil
+}
+
+type tasksCmd struct {
+  All bool `short:"a" long:"all" description:"вместе с завершенными"`
+}
+
+func (t *tasksCmd) Execute(_ []string) error {
+  c, err := connect()
+  if err != nil {
+    return err
+  }
+  tasks, err := c.tasks()
+  if err != nil {
+    return err
+  }
+  for _, task := range tasks {
+    if task.TaskStatus == status.Complete && !t.All {
+      continue
+    }
+    fmt.Printf("%-9s %s\n", task.TaskStatus, task.TaskName)
+  }
+  return nil
+}
+
+type completionCmd struct{}
+
+func (c *completionCmd) Execute(_ []string) error {
+  fmt.Print(`_tt_completion() {
+  local args=("${COMP_WORDS[@]:1:$COMP_CWORD}")
+  local IFS=$'\n'
+  COMPREPLY=($(GO_FLAGS_COMPLETION=1 ${COMP_WORDS[0]} "${args[@]}"))
+  return 0
+}
+complete -F _tt_completion tt
+`)
+  return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+  "fmt"
+  "strconv"
+  "strings"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+func day(t time.Time) time.Time {
+  return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// parseInterval разбирает интервал Postgres из отчета: "01:30:00", "1 day 02:00:00.5"
+func parseInterval(s string) time.Duration {
+  var d time.Duration
+  fields := strings.Fields(s)
+  for i := 0; i+1 < len(fields); i += 2 {
+    if strings.HasPrefix(fields[i+1], "day") {
+      n, _ := strconv.Atoi(fields[i])
+      d += time.Duration(n) * 24 * time.Hour
+    }
+  }
+  if len(fields) == 0 {
+    return d
+  }
+  parts := strings.Split(fields[len(fields)-1], ":")
+  if len(parts) != 3 {
+    return d
+  }
+  h, _ := strconv.Atoi(parts[0])
+  m, _ := strconv.Atoi(parts[1])
+  sec, _ := strconv.ParseFloat(parts[2], 64)
+  return d + time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(sec*float64(time.Second))
+}
+
+func total(times []dto.TaskTimeResult) time.Duration {
+  var d time.Duration
+  for _, t := range times {
+    d += parseInterval(t.TotalTime)
+  }
+  return d
+}
+
+func formatDuration(d time.Duration) string {
+  d = d.Round(time.Minute)
+  return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
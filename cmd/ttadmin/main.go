@@ -0,0 +1,227 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "github.com/jessevdk/go-flags"
+  "os"
+  "os/signal"
+  "strconv"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/config"
+  "timetracker/internal/db"
+)
+
+// ttadmin - обслуживание базы: миграции схемы, тестовые данные и исправление таймеров
+var opts config.OptionsDb
+
+func main() {
+  parser := flags.NewParser(&opts, flags.Default)
+  migrate, _ := parser.AddCommand("migrate", "Миграции схемы", "Применение и откат встроенных миграций", &struct{}{})
+  migrate.AddCommand("up", "Применить все", "Применяет все непримененные миграции", &upCmd{})
+  migrate.AddCommand("down", "Откатить", "Откатывает последнюю миграцию, с --all все", &downCmd{})
+  migrate.AddCommand("to", "Перейти к версии", "Применяет или откатывает миграции до указанной версии", &toCmd{})
+  migrate.AddCommand("version", "Текущая версия", "Показывает версию схемы и признак незавершенной миграции", &versionCmd{})
+  migrate.AddCommand("force", "Записать версию", "Записывает версию без выполнения миграций, снимает признак незавершенной миграции", &forceCmd{})
+  parser.AddCommand("seed", "Тестовые данные", "Создает людей, задачи и интервалы времени для нагрузочного тестирования", &seedCmd{})
+  parser.AddCommand("recalc", "Пересчет статусов", "Исправляет статусы задач, разошедшиеся с таймерами", &recalcCmd{})
+  parser.AddCommand("vacuum-stale-timers", "Закрыть забытые таймеры", "Закрывает таймеры, открытые дольше --older-than, и ставит задачи на паузу", &vacuumCmd{})
+
+  if _, err := parser.Parse(); err != nil {
+    var flagsErr *flags.Error
+    if errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp {
+      os.Exit(0)
+    }
+    os.Exit(1)
+  }
+}
+
+// migrator открывает мигратор и печатает версию схемы после выполнения fn
+func migrator(fn func(m *db.Migrator) error) error {
+  m, err := db.NewMigrator(opts.DbString())
+  if err != nil {
+    return err
+  }
+  defer m.Close()
+  if err = fn(m); err != nil {
+    return err
+  }
+  return printVersion(m)
+}
+
+func printVersion(m *db.Migrator) error {
+  version, dirty, err := m.Version()
+  if err != nil {
+    return err
+  }
+  if dirty {
+    fmt.Printf("версия %d, миграция не завершена: исправьте схему и выполните migrate force\n", version)
+    return nil
+  }
+  fmt.Printf("версия %d\n", version)
+  return nil
+}
+
+type upCmd struct{}
+
+func (c *upCmd) Execute(_ []string) error {
+  return migrator(func(m *db.Migrator) error { return m.Up() })
+}
+
+type downCmd struct {
+  All bool `long:"all" description:"откатить все миграции"`
+}
+
+func (c *downCmd) Execute(_ []string) error {
+  return migrator(func(m *db.Migrator) error { return m.Down(c.All) })
+}
+
+type versionArg struct {
+  Args struct {
+    Version string `positional-arg-name:"версия" required:"yes"`
+  } `positional-args:"yes"`
+}
+
+func (a versionArg) parse() (uint, error) {
+  version, err := strconv.ParseUint(a.Args.Version, 10, 32)
+  if err != nil {
+    return 0, fmt.Errorf("неверная версия %q", a.Args.Version)
+  }
+  return uint(version), nil
+}
+
+type toCmd struct {
+  versionArg
+}
+
+func (c *toCmd) Execute(_ []string) error {
+  version, err := c.parse()
+  if err != nil {
+    return err
+  }
+  if version == 0 {
+    return errors.New("для отката всех миграций используйте migrate down --all")
+  }
+  return migrator(func(m *db.Migrator) error { return m.To(version) })
+}
+
+type versionCmd struct{}
+
+func (c *versionCmd) Execute(_ []string) error {
+  return migrator(func(m *db.Migrator) error { return nil })
+}
+
+type forceCmd struct {
+  versionArg
+}
+
+func (c *forceCmd) Execute(_ []string) error {
+  version, err := c.parse()
+  if err != nil {
+    return err
+  }
+  return migrator(func(m *db.Migrator) error { return m.Force(int(version)) })
+}
+
+// connect подключается к базе, ctx отменяется по Ctrl+C
+func connect() (context.Context, *db.DbRepo, func(), error) {
+  d, err := db.Open(opts.DbString())
+  if err != nil {
+    return nil, nil, nil, err
+  }
+  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+  return ctx, d, func() {
+    stop()
+    _ = d.Close()
+  }, nil
+}
+
+type seedCmd struct {
+  Organization string `long:"org" description:"id организации, по умолчанию default"`
+  People       int    `long:"people" description:"число людей" default:"100"`
+  Tasks        int    `long:"tasks" description:"задач у каждого человека" default:"10"`
+  Entries      int    `long:"entries" description:"интервалов времени у каждой задачи" default:"5"`
+  Seed         uint64 `long:"seed" description:"начальное значение генератора, 0 - случайное"`
+}
+
+func (c *seedCmd) Execute(_ []string) error {
+  if c.People < 0 || c.Tasks < 0 || c.Entries < 0 {
+    return errors.New("число людей, задач и интервалов не может быть отрицательным")
+  }
+  if c.Organization == "" {
+    c.Organization = dto.DefaultOrganization
+  }
+  ctx, d, closeDb, err := connect()
+  if err != nil {
+    return err
+  }
+  defer closeDb()
+
+  start := time.Now()
+  res, err := d.Seed(ctx, db.SeedOptions{
+    Organization: c.Organization,
+    People:       c.People,
+    Tasks:        c.Tasks,
+    Entries:      c.Entries,
+    Seed:         c.Seed,
+  })
+  if err != nil {
+    return err
+  }
+  fmt.Printf("людей: %d, задач: %d, интервалов: %d за %s\n", res.People, res.Tasks, res.Entries, time.Since(start).Round(time.Millisecond))
+  return nil
+}
+
+type recalcCmd struct {
+  Organization string `long:"org" description:"id организации, по умолчанию все"`
+  DryRun       bool   `long:"dry-run" description:"только показать число задач для исправления"`
+}
+
+func (c *recalcCmd) Execute(_ []string) error {
+  ctx, d, closeDb, err := connect()
+  if err != nil {
+    return err
+  }
+  defer closeDb()
+
+  n, err := d.Recalc(ctx, c.Organization, c.DryRun)
+  if err != nil {
+    return err
+  }
+  if c.DryRun {
+    fmt.Printf("задач с неверным статусом: %d\n", n)
+    return nil
+  }
+  fmt.Printf("исправлено задач: %d\n", n)
+  return nil
+}
+
+type vacuumCmd struct {
+  Organization string        `long:"org" description:"id организации, по умолчанию все"`
+  OlderThan    time.Duration `long:"older-than" description:"таймер считается забытым, если открыт дольше" default:"12h"`
+  DryRun       bool          `long:"dry-run" description:"только показать число забытых таймеров"`
+}
+
+func (c *vacuumCmd) Execute(_ []string) error {
+  if c.OlderThan <= 0 {
+    return errors.New("--older-than должен быть больше нуля")
+  }
+  ctx, d, closeDb, err := connect()
+  if err != nil {
+    return err
+  }
+  defer closeDb()
+
+  n, err := d.VacuumStaleTimers(ctx, c.Organization, c.OlderThan, c.DryRun)
+  if err != nil {
+    return err
+  }
+  if c.DryRun {
+    fmt.Printf("забытых таймеров: %d\n", n)
+    return nil
+  }
+  fmt.Printf("закрыто таймеров: %d\n", n)
+  return nil
+}
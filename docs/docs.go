@@ -15,6 +15,46 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/graphql": {
+            "post": {
+                "description": "Люди, их задачи, интервалы работы и итоги за один запрос, схема - internal/io/graphql/schema.graphql.\nСписки постраничные, как в REST: first до 100, after - nextCursor, total при count: true.\nВложенность запроса не больше 10 полей, слишком большой по оценке запрос отклоняется до выполнения.\nОшибки отдельных полей возвращаются в errors со статусом 200",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Запрос GraphQL",
+                "parameters": [
+                    {
+                        "description": "Запрос",
+                        "name": "query",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Результат запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректное тело запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/info": {
             "get": {
                 "description": "Получение информации о человеке по серии и номеру паспорта",
@@ -66,78 +106,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/people": {
+        "/v1/admin/api-keys": {
             "get": {
-                "description": "Получение списка людей с возможностью фильтрации по различным полям и пагинацией",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает выпущенные API-ключи без открытых значений. Доступ определяется действием apikey.manage политики",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "people"
-                ],
-                "summary": "Получение списка людей с фильтрацией и пагинацией",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Фамилия человека",
-                        "name": "surname",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Имя человека",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Отчество человека",
-                        "name": "patronymic",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Адрес человека",
-                        "name": "address",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Номер паспорта человека",
-                        "name": "passport_number",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Номер страницы (по умолчанию 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Количество записей на странице (по умолчанию 10)",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "admin"
                 ],
+                "summary": "Список API-ключей",
                 "responses": {
                     "200": {
-                        "description": "Список людей",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PeopleResp"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.ApiKey"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Некорректные параметры запроса",
+                    "401": {
+                        "description": "Требуется аутентификация",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -145,7 +141,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Создание новой записи о человеке по номеру паспорта",
+                "description": "Выпускает API-ключ, открытое значение ключа возвращается только в этом ответе. Доступ определяется действием apikey.manage политики",
                 "consumes": [
                     "application/json"
                 ],
@@ -153,17 +149,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "people"
+                    "admin"
                 ],
-                "summary": "Создание человека",
+                "summary": "Создание API-ключа",
                 "parameters": [
                     {
-                        "description": "Паспортные данные",
-                        "name": "passport",
+                        "description": "Параметры ключа",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.Passport"
+                            "$ref": "#/definitions/models.ApiKeyCreate"
                         }
                     }
                 ],
@@ -171,7 +167,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Person"
+                            "$ref": "#/definitions/dto.ApiKey"
                         }
                     },
                     "400": {
@@ -180,8 +176,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Конфликт данных",
+                    "401": {
+                        "description": "Требуется аутентификация",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -189,56 +191,46 @@ const docTemplate = `{
                 }
             }
         },
-        "/people/{uuidP}/{uuidT}/complete": {
-            "get": {
-                "description": "Завершает задачу для человека по его UUID и UUID задачи",
-                "consumes": [
-                    "application/json"
-                ],
+        "/v1/admin/api-keys/{id}": {
+            "delete": {
+                "description": "Удаляет API-ключ по идентификатору. Доступ определяется действием apikey.manage политики",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "admin"
                 ],
-                "summary": "Завершение задачи для человека",
+                "summary": "Отзыв API-ключа",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID человека",
-                        "name": "uuidP",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "UUID задачи",
-                        "name": "uuidT",
+                        "description": "Идентификатор ключа",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешное завершение задачи",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/models.Ok"
                         }
                     },
                     "400": {
-                        "description": "Некорректные параметры запроса",
+                        "description": "Неверный идентификатор",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Человек или задача с указанным UUID не найдены",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "404": {
+                        "description": "Ключ не найден",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -246,66 +238,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/people/{uuidP}/{uuidT}/pause": {
+        "/v1/admin/organizations": {
             "get": {
-                "description": "Приостанавливает таймер для задачи указанного человека по его UUID и UUID задачи",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Возвращает все организации. Доступно администраторам без привязки к организации",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
-                ],
-                "summary": "Приостановка таймера для задачи",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "UUID человека",
-                        "name": "uuidP",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "UUID задачи",
-                        "name": "uuidT",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Список организаций",
                 "responses": {
                     "200": {
-                        "description": "Успешная приостановка таймера для задачи",
-                        "schema": {
-                            "$ref": "#/definitions/models.Ok"
-                        }
-                    },
-                    "400": {
-                        "description": "Некорректные параметры запроса",
-                        "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Человек или задача с указанным UUID не найдены",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ErrorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.Organization"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/people/{uuidP}/{uuidT}/start": {
-            "get": {
-                "description": "Начинает таймер для задачи указанного человека по его UUID и UUID задачи",
+            },
+            "post": {
+                "description": "Создает организацию, данные которой изолированы от остальных. Доступно администраторам без привязки к организации",
                 "consumes": [
                     "application/json"
                 ],
@@ -313,56 +275,72 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "admin"
                 ],
-                "summary": "Начало таймера для задачи",
+                "summary": "Создание организации",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "UUID человека",
-                        "name": "uuidP",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "UUID задачи",
-                        "name": "uuidT",
-                        "in": "path",
-                        "required": true
+                        "description": "Название организации",
+                        "name": "org",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrganizationCreate"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Успешное начало таймера для задачи",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Ok"
+                            "$ref": "#/definitions/dto.Organization"
                         }
                     },
                     "400": {
-                        "description": "Некорректные параметры запроса",
+                        "description": "Неверный запрос",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Человек или задача с указанным UUID не найдены",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    }
+                }
+            }
+        },
+        "/v1/admin/webhooks": {
+            "get": {
+                "description": "Возвращает подписки организации без секретов. Доступ определяется действием webhook.manage политики",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Список вебхуков",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.Webhook"
+                            }
+                        }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/people/{uuid}": {
-            "get": {
-                "description": "Получение информации о человеке по его уникальному идентификатору",
+            },
+            "post": {
+                "description": "Регистрирует адрес, на который отправляются события выбранных типов: person.created, person.updated, person.deleted,\ntask.created, task.started, task.paused, task.completed, timer.started, timer.stopped, entry.added.\nЗапрос подписывается заголовком X-Webhook-Signature: sha256=hex(HMAC-SHA256(secret, \"\u003cX-Webhook-Timestamp\u003e.\u003cтело\u003e\")).\nБез secret он генерируется, секрет возвращается только в этом ответе. Доступ определяется действием webhook.manage политики",
                 "consumes": [
                     "application/json"
                 ],
@@ -370,56 +348,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "people"
+                    "admin"
                 ],
-                "summary": "Получение информации о человеке по UUID",
+                "summary": "Создание вебхука",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Уникальный идентификатор человека (UUID)",
-                        "name": "uuid",
-                        "in": "path",
-                        "required": true
+                        "description": "Адрес, секрет и типы событий",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookCreate"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Информация о человеке",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Person"
+                            "$ref": "#/definitions/dto.Webhook"
                         }
                     },
                     "400": {
-                        "description": "Неверный формат UUID",
+                        "description": "Неверный запрос",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Человек с указанным UUID не найден",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/v1/admin/webhooks/{id}": {
             "delete": {
-                "description": "Удаление записи о человеке по UUID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Удаляет подписку вместе с журналом доставок. Доступ определяется действием webhook.manage политики",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "people"
+                    "admin"
                 ],
-                "summary": "Удаление человека",
+                "summary": "Удаление вебхука",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID человека для удаления",
-                        "name": "uuid",
+                        "description": "Идентификатор вебхука",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -432,70 +411,185 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Неверный UUID",
+                        "description": "Неверный идентификатор",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Человек не найден",
+                        "description": "Вебхук не найден",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Обновляет информацию о человеке по его UUID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
+            }
+        },
+        "/v1/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Возвращает последние доставки событий подписке: статус pending, delivered или failed, число попыток,\nкод последнего ответа и ошибку. Неудачные попытки повторяются с растущей паузой, после 10 попыток доставка failed.\nЖурнал хранится 30 дней. Доступ определяется действием webhook.manage политики",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "people"
+                    "admin"
                 ],
-                "summary": "Обновление информации о человеке",
+                "summary": "Журнал доставок вебхука",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID человека",
-                        "name": "uuid",
+                        "description": "Идентификатор вебхука",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Информация о человеке для обновления",
-                        "name": "body",
+                        "type": "string",
+                        "description": "Только доставки в статусе pending, delivered или failed",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей, по умолчанию и не больше 200",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/batch": {
+            "post": {
+                "description": "Выполняет по порядку операции create_person, create_task, start, pause, complete и entry.\nПри atomic все операции идут в одной транзакции и при первой ошибке откатываются, ответ получает статус этой ошибки.\nБез atomic операции независимы, у каждой свой статус в results.\nВ person_id и task_id можно сослаться на запись, созданную ранее в пакете: \"$0\" - id результата операции 0",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "batch"
+                ],
+                "summary": "Пакет операций",
+                "parameters": [
+                    {
+                        "description": "Операции пакета",
+                        "name": "batch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.Person"
+                            "$ref": "#/definitions/models.BatchReq"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ идемпотентности",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Обновленная информация о человеке",
+                        "description": "Результаты операций",
                         "schema": {
-                            "$ref": "#/definitions/dto.Person"
+                            "$ref": "#/definitions/models.BatchResp"
                         }
                     },
                     "400": {
-                        "description": "Некорректные параметры запроса",
+                        "description": "Некорректный пакет",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Человек с указанным UUID не найден",
+                    "403": {
+                        "description": "Пакет atomic откатан: операции не хватило прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Пакет atomic откатан из-за ошибки операции",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/events": {
+            "get": {
+                "description": "Отдает события task.status, timer.started, timer.stopped и entry.added по задачам, которые вызывающему разрешено читать.\nПоле id события можно передать в Last-Event-ID при переподключении, чтобы получить пропущенные события",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Поток событий задач (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Только события задач человека",
+                        "name": "person_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только события задач участников команды",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Поток событий",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Внутренняя ошибка сервера",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -503,9 +597,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/people/{uuid}/create-task": {
-            "post": {
-                "description": "Создает новую задачу для человека по его UUID",
+        "/v1/people": {
+            "get": {
+                "description": "Получение списка людей с возможностью фильтрации по различным полям и пагинацией",
                 "consumes": [
                     "application/json"
                 ],
@@ -513,32 +607,94 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "people"
                 ],
-                "summary": "Создание новой задачи для человека",
+                "summary": "Получение списка людей с фильтрацией и пагинацией",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "UUID человека",
-                        "name": "uuid",
-                        "in": "path",
-                        "required": true
+                        "description": "Фамилия человека, * - любые символы без учета регистра, несколько значений через запятую",
+                        "name": "surname",
+                        "in": "query"
                     },
                     {
-                        "description": "Данные для создания задачи",
-                        "name": "task",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.TaskCreate"
-                        }
+                        "type": "string",
+                        "description": "Имя человека, * - любые символы без учета регистра, несколько значений через запятую",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Отчество человека, * - любые символы без учета регистра, несколько значений через запятую",
+                        "name": "patronymic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Адрес человека, * - любые символы без учета регистра",
+                        "name": "address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Номер паспорта человека, несколько значений через запятую",
+                        "name": "passport_number",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID команды, в которой состоит человек",
+                        "name": "team_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Есть задача в работе",
+                        "name": "has_active_task",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Добавлен после даты (2006-01-02 или RFC3339)",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: поля surname, name, patronymic, created_at через запятую, - перед полем для убывания",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице (по умолчанию 10, не больше 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Посчитать общее количество записей",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы для постраничного вывода по смещению (устарело, используйте cursor)",
+                        "name": "page",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Созданная задача",
+                        "description": "Список людей",
                         "schema": {
-                            "$ref": "#/definitions/models.Ok"
+                            "$ref": "#/definitions/models.PeopleResp"
                         }
                     },
                     "400": {
@@ -547,8 +703,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Человек с указанным UUID не найден",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -560,11 +716,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/people/{uuid}/worktime": {
+            },
             "post": {
-                "description": "Возвращает список задач с временем работы в указанном диапазоне для указанного человека по его UUID",
+                "description": "Создание новой записи о человеке по номеру паспорта",
                 "consumes": [
                     "application/json"
                 ],
@@ -572,34 +726,79 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "people"
                 ],
-                "summary": "Получение списка задач с временем работы",
+                "summary": "Создание человека",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "UUID человека",
-                        "name": "uuid",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Временной диапазон",
-                        "name": "body",
+                        "description": "Паспортные данные",
+                        "name": "passport",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.DateStartEnd"
+                            "$ref": "#/definitions/dto.Passport"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список задач с временем работы",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный запрос",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Конфликт данных",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/people/duplicates": {
+            "get": {
+                "description": "Пары людей с одинаковыми фамилией, именем и отчеством и похожим адресом",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Поиск дубликатов",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "Минимальная похожесть адресов от 0 до 1 (по умолчанию 0.5)",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество пар (по умолчанию 10, не больше 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Вероятные дубликаты",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.TaskTimeResult"
+                                "$ref": "#/definitions/dto.Duplicate"
                             }
                         }
                     },
@@ -609,8 +808,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Человек с указанным UUID не найден или нет задач в указанном диапазоне",
+                    "403": {
+                        "description": "Недостаточно прав",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -623,51 +822,1579 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.Passport": {
-            "type": "object",
-            "properties": {
-                "passportNumber": {
-                    "type": "string"
-                }
-            }
         },
-        "dto.Person": {
-            "type": "object",
-            "properties": {
-                "address": {
+        "/v1/people/search": {
+            "get": {
+                "description": "Поиск по словам фамилии, имени, отчества и адреса с учетом опечаток, результаты упорядочены по релевантности, совпадения выделены тегом \u003cb\u003e",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Поиск людей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Строка поиска, например 'Иванов Петр'",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество результатов (по умолчанию 10, не больше 100). Постраничного вывода нет: cursor, page и count отклоняются с 400",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Найденные люди",
+                        "schema": {
+                            "$ref": "#/definitions/models.PeopleSearchResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/people/{uuid}": {
+            "get": {
+                "description": "Получение информации о человеке по его уникальному идентификатору",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Получение информации о человеке по UUID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Уникальный идентификатор человека (UUID)",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag из предыдущего ответа",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Информация о человеке",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия записи"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Запись не изменилась"
+                    },
+                    "400": {
+                        "description": "Неверный формат UUID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек с указанным UUID не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаление записи о человеке по UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Удаление человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека для удаления",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag записи, удаление только если она не менялась",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный UUID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Частичное обновление по JSON Merge Patch (RFC 7396): непереданные поля не меняются, null очищает отчество или руководителя. Неизвестные поля и некорректные значения дают 400 со списком ошибок",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Обновление информации о человеке",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonPatch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag записи, обновление только если она не менялась",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленная информация о человеке",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Person"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Новая версия записи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек с указанным UUID не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Запись изменена, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/people/{uuid}/merge": {
+            "post": {
+                "description": "Переносит задачи и время работы человека source_id к человеку из пути в одной транзакции, source_id помечается удаленным, слияние пишется в журнал аудита",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "people"
+                ],
+                "summary": "Слияние дубликатов",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека, который остается",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "UUID дубликата",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PeopleMerge"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Итог слияния",
+                        "schema": {
+                            "$ref": "#/definitions/dto.MergeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/people/{uuid}/tasks": {
+            "get": {
+                "description": "Получение задач человека в порядке создания с постраничным выводом по курсору",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Получение списка задач человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор следующей страницы из next_cursor предыдущего ответа",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице (по умолчанию 10, не больше 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Посчитать общее количество задач",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список задач",
+                        "schema": {
+                            "$ref": "#/definitions/models.TasksResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает новую задачу для человека по его UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Создание новой задачи для человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные для создания задачи",
+                        "name": "task",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Созданная задача",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Версия задачи"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек с указанным UUID не найден",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/people/{uuid}/worktime": {
+            "post": {
+                "description": "Возвращает список задач с временем работы в указанном диапазоне для указанного человека по его UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Получение списка задач с временем работы",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID человека",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Временной диапазон",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DateStartEnd"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Список задач с временем работы",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.TaskTimeResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек с указанным UUID не найден или нет задач в указанном диапазоне",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{uuidT}/complete": {
+            "post": {
+                "description": "Завершает задачу по ее UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Завершение задачи для человека",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID задачи",
+                        "name": "uuidT",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи, действие только если она не менялась",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешное завершение задачи",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Задача с указанным UUID не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Задача изменена, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{uuidT}/entries": {
+            "post": {
+                "description": "Добавляет завершенный интервал работы над задачей, интервал не должен пересекаться с уже учтенным временем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Ручной учет времени",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID задачи",
+                        "name": "uuidT",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Начало и конец интервала",
+                        "name": "entry",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TimeEntry"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Добавленный интервал",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TimeTask"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректный интервал",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{uuidT}/pause": {
+            "post": {
+                "description": "Приостанавливает таймер для задачи по ее UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Приостановка таймера для задачи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID задачи",
+                        "name": "uuidT",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи, действие только если она не менялась",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешная приостановка таймера для задачи",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Задача с указанным UUID не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Задача изменена, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/{uuidT}/start": {
+            "post": {
+                "description": "Начинает таймер для задачи по ее UUID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Начало таймера для задачи",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID задачи",
+                        "name": "uuidT",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag задачи, действие только если она не менялась",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Успешное начало таймера для задачи",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Задача с указанным UUID не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Задача изменена, ETag не совпадает",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Внутренняя ошибка сервера",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/teams": {
+            "get": {
+                "description": "Возвращает команды организации, руководителю только его команды",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Список команд",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.Team"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создает команду с руководителем",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Создание команды",
+                "parameters": [
+                    {
+                        "description": "Название и руководитель команды",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/teams/{uuid}": {
+            "get": {
+                "description": "Возвращает команду и UUID ее участников",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Получение команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID команды",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат UUID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Команда не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет команду, люди из нее не удаляются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Удаление команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID команды",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат UUID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Команда не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменяет название и руководителя команды, пустые поля не меняются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Обновление команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID команды",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые значения",
+                        "name": "team",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TeamCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Team"
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Команда не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/teams/{uuid}/members/{uuidP}": {
+            "put": {
+                "description": "Добавляет человека в команду, повторное добавление не является ошибкой",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Добавление участника команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID команды",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID человека",
+                        "name": "uuidP",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат UUID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Команда или человек не найдены",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Исключает человека из команды",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Исключение участника команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID команды",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID человека",
+                        "name": "uuidP",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Ok"
+                        }
+                    },
+                    "400": {
+                        "description": "Неверный формат UUID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Человек не состоит в команде",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/teams/{uuid}/worktime": {
+            "post": {
+                "description": "Возвращает время работы каждого участника команды по задачам в указанном диапазоне",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "teams"
+                ],
+                "summary": "Трудозатраты команды",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "UUID команды",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Временной диапазон",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DateStartEnd"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.TeamTimeResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Некорректные параметры запроса",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Недостаточно прав",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Команда не найдена",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.ApiKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BatchOp": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "passportNumber": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.Duplicate": {
+            "type": "object",
+            "properties": {
+                "address_similarity": {
+                    "type": "number"
+                },
+                "first": {
+                    "$ref": "#/definitions/dto.Person"
+                },
+                "second": {
+                    "$ref": "#/definitions/dto.Person"
+                }
+            }
+        },
+        "dto.Event": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "team_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.MergeResult": {
+            "type": "object",
+            "properties": {
+                "audit_id": {
+                    "type": "string"
+                },
+                "moved_tasks": {
+                    "type": "integer"
+                },
+                "source_id": {
+                    "type": "string"
+                },
+                "target": {
+                    "$ref": "#/definitions/dto.Person"
+                }
+            }
+        },
+        "dto.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Passport": {
+            "type": "object",
+            "properties": {
+                "passportNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Person": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "passportNumber": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.PersonMatch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "passportNumber": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "surname": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.TaskTimeResult": {
+            "type": "object",
+            "properties": {
+                "idtask": {
+                    "type": "string"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "total_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Team": {
+            "type": "object",
+            "properties": {
+                "id": {
                     "type": "string"
                 },
-                "id": {
+                "manager_id": {
                     "type": "string"
                 },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "name": {
                     "type": "string"
-                },
-                "passportNumber": {
+                }
+            }
+        },
+        "dto.TeamTimeResult": {
+            "type": "object",
+            "properties": {
+                "idperson": {
                     "type": "string"
                 },
-                "patronymic": {
+                "name": {
                     "type": "string"
                 },
                 "surname": {
                     "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TaskTimeResult"
+                    }
+                },
+                "total_time": {
+                    "type": "string"
                 }
             }
         },
-        "dto.TaskTimeResult": {
+        "dto.TimeTask": {
             "type": "object",
             "properties": {
-                "idtask": {
+                "end_time": {
                     "type": "string"
                 },
-                "task_name": {
+                "id": {
+                    "type": "integer"
+                },
+                "id_task": {
                     "type": "string"
                 },
-                "total_time": {
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
                     "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApiKeyCreate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "person_id": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchOpResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "result": {},
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchReq": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BatchOp"
+                    }
+                }
+            }
+        },
+        "models.BatchResp": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean"
+                },
+                "committed": {
+                    "type": "boolean"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchOpResult"
+                    }
                 }
             }
         },
@@ -690,6 +2417,45 @@ const docTemplate = `{
                 }
             }
         },
+        "models.GraphQLError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "models.GraphQLReq": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "models.GraphQLResp": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GraphQLError"
+                    }
+                }
+            }
+        },
         "models.Ok": {
             "type": "object",
             "properties": {
@@ -701,12 +2467,31 @@ const docTemplate = `{
                 }
             }
         },
+        "models.OrganizationCreate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PeopleMerge": {
+            "type": "object",
+            "properties": {
+                "source_id": {
+                    "type": "string"
+                }
+            }
+        },
         "models.PeopleResp": {
             "type": "object",
             "properties": {
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "offset": {
                     "type": "integer"
                 },
@@ -721,6 +2506,43 @@ const docTemplate = `{
                 }
             }
         },
+        "models.PeopleSearchResp": {
+            "type": "object",
+            "properties": {
+                "query": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.PersonMatch"
+                    }
+                }
+            }
+        },
+        "models.PersonPatch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "manager_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "passportNumber": {
+                    "type": "string"
+                },
+                "patronymic": {
+                    "type": "string"
+                },
+                "surname": {
+                    "type": "string"
+                }
+            }
+        },
         "models.TaskCreate": {
             "type": "object",
             "properties": {
@@ -731,6 +2553,109 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.TaskResp": {
+            "type": "object",
+            "properties": {
+                "id_person": {
+                    "type": "string"
+                },
+                "id_task": {
+                    "type": "string"
+                },
+                "task_name": {
+                    "type": "string"
+                },
+                "task_status": {
+                    "type": "string"
+                },
+                "urls": {
+                    "$ref": "#/definitions/models.UrlTask"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TasksResp": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskResp"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TeamCreate": {
+            "type": "object",
+            "properties": {
+                "manager_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TimeEntry": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string",
+                    "example": "2024-05-01T12:30:00Z"
+                },
+                "start": {
+                    "type": "string",
+                    "example": "2024-05-01T09:00:00Z"
+                }
+            }
+        },
+        "models.UrlTask": {
+            "type": "object",
+            "properties": {
+                "complete_task": {
+                    "type": "string"
+                },
+                "pause_task": {
+                    "type": "string"
+                },
+                "start_task": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookCreate": {
+            "type": "object",
+            "properties": {
+                "event_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task.completed",
+                        "timer.started"
+                    ]
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://payroll.example.com/hooks/timetracker"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,14 @@
+package dto
+
+import "time"
+
+// AuditRecord запись журнала аудита о действии над сущностью
+type AuditRecord struct {
+  ID        string                 `json:"id"`
+  Actor     string                 `json:"actor"`
+  Action    string                 `json:"action"`
+  Entity    string                 `json:"entity"`
+  EntityID  string                 `json:"entity_id"`
+  Details   map[string]interface{} `json:"details,omitempty"`
+  CreatedAt time.Time              `json:"created_at"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+const (
+  AuthApiKey = "apikey"
+  AuthJWT    = "jwt"
+)
+
+const (
+  RoleSelf    = "self"
+  RoleManager = "manager"
+  RoleAdmin   = "admin"
+)
+
+func IsValidRole(role string) bool {
+  return role == RoleSelf || role == RoleManager || role == RoleAdmin
+}
+
+// Principal описывает аутентифицированного вызывающего
+type Principal struct {
+  ID             string `json:"id"`
+  PersonID       string `json:"person_id,omitempty"`
+  OrganizationID string `json:"organization_id,omitempty"`
+  Name           string `json:"name"`
+  Method         string `json:"method"`
+  Role           string `json:"role"`
+}
+
+// Actor обозначение вызывающего в журнале аудита
+func (p *Principal) Actor() string {
+  if p == nil {
+    return "system"
+  }
+  return p.Method + ":" + p.ID
+}
+
+type ApiKey struct {
+  ID             string     `json:"id"`
+  Name           string     `json:"name"`
+  PersonID       string     `json:"person_id,omitempty"`
+  OrganizationID string     `json:"organization_id,omitempty"`
+  Role           string     `json:"role"`
+  Prefix         string     `json:"prefix"`
+  CreatedAt      time.Time  `json:"created_at"`
+  LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+  Key            string     `json:"key,omitempty"`
+}
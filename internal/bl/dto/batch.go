@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// операции пакетного запроса
+const (
+  BatchCreatePerson = "create_person"
+  BatchCreateTask   = "create_task"
+  BatchStart        = "start"
+  BatchPause        = "pause"
+  BatchComplete     = "complete"
+  BatchEntry        = "entry"
+)
+
+// BatchOp операция пакета, нужные поля зависят от Op. В PersonID и TaskID
+// можно сослаться на созданную ранее в пакете запись: "$0" - id результата операции 0
+type BatchOp struct {
+  Op             string     `json:"op"`
+  PassportNumber string     `json:"passportNumber,omitempty"`
+  PersonID       string     `json:"person_id,omitempty"`
+  TaskName       string     `json:"task_name,omitempty"`
+  TaskID         string     `json:"task_id,omitempty"`
+  Version        int        `json:"version,omitempty"`
+  Start          *time.Time `json:"start,omitempty"`
+  End            *time.Time `json:"end,omitempty"`
+}
+
+// BatchResult итог операции пакета: созданная запись или ошибка
+type BatchResult struct {
+  Result interface{}
+  Err    error
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// типы событий потока /v1/events
+const (
+  EventTaskStatus   = "task.status"
+  EventTimerStarted = "timer.started"
+  EventTimerStopped = "timer.stopped"
+  EventEntryAdded   = "entry.added"
+)
+
+// Event изменение задачи или ее таймера. ManagerID и TeamIDs фиксируются
+// на момент изменения и нужны для отбора по команде и правам подписчика
+type Event struct {
+  ID             string    `json:"id"`
+  Type           string    `json:"type"`
+  At             time.Time `json:"at"`
+  OrganizationID string    `json:"organization_id"`
+  TaskID         string    `json:"task_id"`
+  TaskName       string    `json:"task_name,omitempty"`
+  PersonID       string    `json:"person_id"`
+  Status         string    `json:"status,omitempty"`
+  ManagerID      string    `json:"manager_id,omitempty"`
+  TeamIDs        []string  `json:"team_ids,omitempty"`
+}
+
+// EventFilter отбор событий подписчиком, пустые поля не ограничивают
+type EventFilter struct {
+  PersonID string
+  TeamID   string
+}
@@ -0,0 +1,8 @@
+package dto
+
+// IdempotentResponse сохраненный ответ на запрос с Idempotency-Key
+type IdempotentResponse struct {
+  Status  int
+  Headers map[string][]string
+  Body    []byte
+}
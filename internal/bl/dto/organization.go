@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// DefaultOrganization организация, к которой отнесены данные, созданные до разделения
+const DefaultOrganization = "00000000-0000-0000-0000-000000000001"
+
+type Organization struct {
+  ID        string    `json:"id"`
+  Name      string    `json:"name"`
+  CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package dto
+
+// PageRequest параметры страницы списка: курсор из предыдущего ответа
+// или смещение для старых клиентов, Count включает подсчет общего числа записей
+type PageRequest struct {
+  Cursor string
+  Offset int
+  Limit  int
+  Count  bool
+}
+
+type PageInfo struct {
+  NextCursor string
+  Total      *int
+}
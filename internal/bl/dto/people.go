@@ -1,7 +1,11 @@
 package dto
 
+import "time"
+
 type Person struct {
-  ID string
+  ID        string
+  ManagerID string `json:"manager_id,omitempty"`
+  Version   int    `json:"version"`
   People
   Passport
 }
@@ -9,10 +13,61 @@ type Person struct {
 type People struct {
   Surname    string `json:"surname"`
   Name       string `json:"name"`
-  Patronymic string `json:"patronymic"`
+  Patronymic string `json:"patronymic,omitempty"`
   Address    string `json:"address"`
 }
 
 type Passport struct {
   PassportNumber string `json:"passportNumber"`
 }
+
+// PatchString поле JSON Merge Patch: Set - поле есть в теле, Null - передан null
+type PatchString struct {
+  Set   bool
+  Null  bool
+  Value string
+}
+
+// PersonPatch изменения человека по RFC 7396, поля без Set не меняются
+type PersonPatch struct {
+  ID             string
+  Version        int
+  Surname        PatchString
+  Name           PatchString
+  Patronymic     PatchString
+  Address        PatchString
+  PassportNumber PatchString
+  ManagerID      PatchString
+}
+
+// PeopleFilter отбор людей по полям Person, членству в команде и производным данным.
+// Текстовые поля понимают шаблон со * и списки значений через запятую
+type PeopleFilter struct {
+  Person
+  TeamID        string
+  HasActiveTask *bool
+  CreatedAfter  *time.Time
+  Sort          []SortField
+}
+
+// PersonMatch результат поиска: человек, релевантность и фрагмент с выделенными совпадениями
+type PersonMatch struct {
+  Person
+  Rank      float64 `json:"rank"`
+  Highlight string  `json:"highlight"`
+}
+
+// Duplicate пара записей, похожих на одного человека
+type Duplicate struct {
+  First             Person  `json:"first"`
+  Second            Person  `json:"second"`
+  AddressSimilarity float64 `json:"address_similarity"`
+}
+
+// MergeResult итог слияния: источник удален, его задачи перенесены к цели
+type MergeResult struct {
+  Target     Person `json:"target"`
+  SourceID   string `json:"source_id"`
+  MovedTasks int64  `json:"moved_tasks"`
+  AuditID    string `json:"audit_id"`
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+  "fmt"
+  "slices"
+  "strings"
+)
+
+// SortField поле сортировки списка, Desc - по убыванию
+type SortField struct {
+  Field string
+  Desc  bool
+}
+
+// PeopleSortFields поля, по которым можно сортировать список людей
+var PeopleSortFields = []string{"surname", "name", "patronymic", "created_at"}
+
+// ParseSort разбирает параметр вида "surname,-created_at", минус означает сортировку по убыванию
+func ParseSort(value string, allowed []string) ([]SortField, error) {
+  var fields []SortField
+  for _, part := range strings.Split(value, ",") {
+    part = strings.TrimSpace(part)
+    if part == "" {
+      continue
+    }
+    field := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+    if !slices.Contains(allowed, field.Field) {
+      return nil, fmt.Errorf("сортировка по полю %s не поддерживается", field.Field)
+    }
+    if slices.ContainsFunc(fields, func(f SortField) bool { return f.Field == field.Field }) {
+      return nil, fmt.Errorf("поле %s указано в сортировке дважды", field.Field)
+    }
+    fields = append(fields, field)
+  }
+  return fields, nil
+}
+
+// SortKey строковое представление сортировки, курсор действителен только для нее
+func SortKey(fields []SortField) string {
+  parts := make([]string, len(fields))
+  for i, field := range fields {
+    parts[i] = field.Field
+    if field.Desc {
+      parts[i] = "-" + field.Field
+    }
+  }
+  return strings.Join(parts, ",")
+}
@@ -5,6 +5,7 @@ type Task struct {
   IdPerson   string `json:"id_person"`
   TaskName   string `json:"task_name"`
   TaskStatus string `json:"task_status"`
+  Version    int    `json:"version"`
 }
 
 type TaskTimeResult struct {
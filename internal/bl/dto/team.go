@@ -0,0 +1,17 @@
+package dto
+
+type Team struct {
+  ID        string   `json:"id"`
+  Name      string   `json:"name"`
+  ManagerID string   `json:"manager_id,omitempty"`
+  Members   []string `json:"members,omitempty"`
+}
+
+// TeamTimeResult время работы участника команды по задачам
+type TeamTimeResult struct {
+  IDPerson  string           `json:"idperson"`
+  Surname   string           `json:"surname"`
+  Name      string           `json:"name"`
+  TotalTime string           `json:"total_time"`
+  Tasks     []TaskTimeResult `json:"tasks"`
+}
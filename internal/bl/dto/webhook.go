@@ -0,0 +1,79 @@
+package dto
+
+import (
+  "encoding/json"
+  "slices"
+  "time"
+)
+
+// типы событий вебхуков
+const (
+  WebhookPersonCreated = "person.created"
+  WebhookPersonUpdated = "person.updated"
+  WebhookPersonDeleted = "person.deleted"
+  WebhookTaskCreated   = "task.created"
+  WebhookTaskStarted   = "task.started"
+  WebhookTaskPaused    = "task.paused"
+  WebhookTaskCompleted = "task.completed"
+  WebhookTimerStarted  = "timer.started"
+  WebhookTimerStopped  = "timer.stopped"
+  WebhookEntryAdded    = "entry.added"
+)
+
+var webhookEvents = []string{
+  WebhookPersonCreated, WebhookPersonUpdated, WebhookPersonDeleted, WebhookTaskCreated, WebhookTaskStarted,
+  WebhookTaskPaused, WebhookTaskCompleted, WebhookTimerStarted, WebhookTimerStopped,
+  WebhookEntryAdded,
+}
+
+func IsValidWebhookEvent(event string) bool {
+  return slices.Contains(webhookEvents, event)
+}
+
+// статусы доставки вебхука
+const (
+  DeliveryPending   = "pending"
+  DeliveryDelivered = "delivered"
+  DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+  ID         string    `json:"id"`
+  URL        string    `json:"url"`
+  EventTypes []string  `json:"event_types"`
+  CreatedAt  time.Time `json:"created_at"`
+  Secret     string    `json:"secret,omitempty"`
+}
+
+// WebhookEvent тело запроса вебхука
+type WebhookEvent struct {
+  ID             string          `json:"id"`
+  Type           string          `json:"type"`
+  OrganizationID string          `json:"organization_id"`
+  CreatedAt      time.Time       `json:"created_at"`
+  Data           json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery доставка события подписке и итог последней попытки
+type WebhookDelivery struct {
+  ID             int64      `json:"id"`
+  WebhookID      string     `json:"webhook_id"`
+  EventID        int64      `json:"event_id"`
+  EventType      string     `json:"event_type"`
+  Status         string     `json:"status"`
+  Attempts       int        `json:"attempts"`
+  NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+  ResponseStatus *int       `json:"response_status,omitempty"`
+  LastError      string     `json:"last_error,omitempty"`
+  DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+  CreatedAt      time.Time  `json:"created_at"`
+}
+
+// OutgoingWebhook доставка, взятая воркером в работу, Attempts с учетом текущей попытки
+type OutgoingWebhook struct {
+  DeliveryID int64
+  Attempts   int
+  URL        string
+  Secret     string
+  Event      WebhookEvent
+}
@@ -0,0 +1,151 @@
+package events
+
+import (
+  "context"
+  "encoding/json"
+  "github.com/google/uuid"
+  "log/slog"
+  "sync"
+  "timetracker/internal/bl/dto"
+)
+
+const (
+  // recentSize сколько последних событий хранится для переподключений с Last-Event-ID
+  recentSize = 1000
+  // subBuffer очередь подписчика, при переполнении подписка закрывается
+  subBuffer = 256
+)
+
+// Notifier рассылает событие другим экземплярам сервиса
+type Notifier interface {
+  Notify(ctx context.Context, payload []byte) error
+}
+
+// envelope событие в рассылке между экземплярами
+type envelope struct {
+  Origin string    `json:"origin"`
+  Event  dto.Event `json:"event"`
+}
+
+// Bus шина событий процесса. Publish раздает событие подписчикам этого экземпляра
+// и через Notifier остальным, Receive принимает события других экземпляров
+type Bus struct {
+  mu       sync.Mutex
+  subs     map[*Subscription]struct{}
+  recent   []dto.Event
+  origin   string
+  notifier Notifier
+  l        *slog.Logger
+}
+
+func New(log *slog.Logger) *Bus {
+  return &Bus{
+    subs:   map[*Subscription]struct{}{},
+    origin: uuid.NewString(),
+    l:      log.With(slog.String("layer", "events")),
+  }
+}
+
+// SetNotifier включает рассылку событий между экземплярами
+func (b *Bus) SetNotifier(n Notifier) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  b.notifier = n
+}
+
+func (b *Bus) Publish(e dto.Event) {
+  if e.ID == "" {
+    e.ID = uuid.NewString()
+  }
+  b.deliver(e)
+
+  b.mu.Lock()
+  notifier := b.notifier
+  b.mu.Unlock()
+  if notifier == nil {
+    return
+  }
+  payload, err := json.Marshal(envelope{Origin: b.origin, Event: e})
+  if err == nil {
+    err = notifier.Notify(context.Background(), payload)
+  }
+  if err != nil {
+    b.l.Error("ошибка рассылки события", slog.String("event", e.ID), slog.String("error", err.Error()))
+  }
+}
+
+// Receive принимает событие из рассылки, свои события уже розданы в Publish
+func (b *Bus) Receive(payload []byte) {
+  var env envelope
+  if err := json.Unmarshal(payload, &env); err != nil {
+    b.l.Error("некорректное событие из рассылки", slog.String("error", err.Error()))
+    return
+  }
+  if env.Origin == b.origin {
+    return
+  }
+  b.deliver(env.Event)
+}
+
+func (b *Bus) deliver(e dto.Event) {
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  if len(b.recent) == recentSize {
+    b.recent = append(b.recent[:0], b.recent[1:]...)
+  }
+  b.recent = append(b.recent, e)
+  for sub := range b.subs {
+    if !sub.match(e) {
+      continue
+    }
+    select {
+    case sub.ch <- e:
+    default:
+      // подписчик не успевает, он переподключится с Last-Event-ID
+      delete(b.subs, sub)
+      close(sub.ch)
+    }
+  }
+}
+
+// Subscription подписка на события, C закрывается при Close или переполнении очереди
+type Subscription struct {
+  C     <-chan dto.Event
+  ch    chan dto.Event
+  match func(dto.Event) bool
+  bus   *Bus
+}
+
+// Subscribe подписывает на события, для которых match возвращает true. Если lastID
+// найден среди последних событий, сначала отдаются пропущенные после него
+func (b *Bus) Subscribe(match func(dto.Event) bool, lastID string) *Subscription {
+  ch := make(chan dto.Event, subBuffer)
+  sub := &Subscription{C: ch, ch: ch, match: match, bus: b}
+
+  b.mu.Lock()
+  defer b.mu.Unlock()
+  if lastID != "" {
+    for i := len(b.recent) - 1; i >= 0; i-- {
+      if b.recent[i].ID != lastID {
+        continue
+      }
+      for _, e := range b.recent[i+1:] {
+        if match(e) && len(ch) < subBuffer {
+          ch <- e
+        }
+      }
+      break
+    }
+  }
+  b.subs[sub] = struct{}{}
+  return sub
+}
+
+func (s *Subscription) Close() {
+  s.bus.mu.Lock()
+  defer s.bus.mu.Unlock()
+  if _, ok := s.bus.subs[s]; ok {
+    delete(s.bus.subs, s)
+    close(s.ch)
+  }
+}
@@ -0,0 +1,101 @@
+package policy
+
+import (
+  _ "embed"
+  "encoding/json"
+  "fmt"
+  "os"
+  "slices"
+  "timetracker/internal/bl/dto"
+)
+
+type Scope string
+
+const (
+  // ScopeOwn только записи самого вызывающего
+  ScopeOwn Scope = "own"
+  // ScopeTeam записи вызывающего, его подчиненных и участников его команд
+  ScopeTeam Scope = "team"
+  // ScopeAny любые записи
+  ScopeAny Scope = "any"
+)
+
+const (
+  PeopleCreate  = "people.create"
+  PeopleDelete  = "people.delete"
+  PeopleRead    = "people.read"
+  PeopleList    = "people.list"
+  PeopleUpdate  = "people.update"
+  PeopleManager = "people.manager"
+  PeopleMerge   = "people.merge"
+  TaskCreate    = "task.create"
+  TaskRead      = "task.read"
+  TaskTimer     = "task.timer"
+  TaskReport    = "task.report"
+  ApiKeyManage  = "apikey.manage"
+
+  OrganizationManage = "organization.manage"
+  TeamManage         = "team.manage"
+  TeamRead           = "team.read"
+  WebhookManage      = "webhook.manage"
+)
+
+var actions = []string{
+  PeopleCreate, PeopleDelete, PeopleRead, PeopleList, PeopleUpdate, PeopleManager,
+  PeopleMerge, TaskCreate, TaskRead, TaskTimer, TaskReport, ApiKeyManage, OrganizationManage,
+  TeamManage, TeamRead, WebhookManage,
+}
+
+//go:embed default.json
+var defaultPolicy []byte
+
+// Policy таблица доступа: действие -> роль -> область видимости.
+// Роль, отсутствующая у действия, не имеет к нему доступа
+type Policy map[string]map[string]Scope
+
+// Load читает политику из json файла, при пустом пути возвращает политику по умолчанию
+func Load(path string) (Policy, error) {
+  data := defaultPolicy
+  if path != "" {
+    var err error
+    data, err = os.ReadFile(path)
+    if err != nil {
+      return nil, fmt.Errorf("ошибка чтения политики: %w", err)
+    }
+  }
+  return Parse(data)
+}
+
+func Parse(data []byte) (Policy, error) {
+  var p Policy
+  if err := json.Unmarshal(data, &p); err != nil {
+    return nil, fmt.Errorf("ошибка разбора политики: %w", err)
+  }
+  if err := p.Validate(); err != nil {
+    return nil, err
+  }
+  return p, nil
+}
+
+func (p Policy) Validate() error {
+  for action, roles := range p {
+    if !slices.Contains(actions, action) {
+      return fmt.Errorf("неизвестное действие %s в политике", action)
+    }
+    for role, scope := range roles {
+      if !dto.IsValidRole(role) {
+        return fmt.Errorf("неизвестная роль %s у действия %s", role, action)
+      }
+      if scope != ScopeOwn && scope != ScopeTeam && scope != ScopeAny {
+        return fmt.Errorf("неизвестная область %s у действия %s", scope, action)
+      }
+    }
+  }
+  return nil
+}
+
+// Scope возвращает область, доступную роли для действия, false если доступа нет
+func (p Policy) Scope(action, role string) (Scope, bool) {
+  scope, ok := p[action][role]
+  return scope, ok
+}
@@ -1,18 +1,44 @@
 package bl
 
 import (
+  "log/slog"
+  "timetracker/internal/bl/events"
+  "timetracker/internal/bl/policy"
   "timetracker/internal/bl/repo"
   "timetracker/internal/db"
+  "timetracker/internal/utils/keyset"
 )
 
 type BL struct {
-  People repo.IPeopleBL
-  Task   repo.ITaskBL
+  People  repo.IPeopleBL
+  Task    repo.ITaskBL
+  Auth    repo.IAuthBL
+  Org     repo.IOrganizationBL
+  Team    repo.ITeamBL
+  Idem    repo.IIdempotencyBL
+  Batch   repo.IBatchBL
+  Events  repo.IEventsBL
+  Webhook repo.IWebhookBL
+  Bus     *events.Bus
+  Guard   *repo.Guard
 }
 
-func New(db *db.DbRepo) *BL {
+func New(db *db.Store, keys *keyset.KeySet, pol policy.Policy, log *slog.Logger) *BL {
+  guard := repo.NewGuard(db, pol)
+  bus := events.New(log)
+  people := repo.NewPeopleBL(db, guard)
+  task := repo.NewTaskBL(db, guard, bus)
   return &BL{
-    People: repo.NewPeopleBL(db),
-    Task:   repo.NewTaskBL(db),
+    People:  people,
+    Task:    task,
+    Auth:    repo.NewAuthBL(db, keys, guard),
+    Org:     repo.NewOrganizationBL(db, guard),
+    Team:    repo.NewTeamBL(db, guard),
+    Idem:    repo.NewIdempotencyBL(db),
+    Batch:   repo.NewBatchBL(db, people, task),
+    Events:  repo.NewEventsBL(db, guard, bus),
+    Webhook: repo.NewWebhookBL(db, guard),
+    Bus:     bus,
+    Guard:   guard,
   }
 }
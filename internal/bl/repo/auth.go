@@ -0,0 +1,192 @@
+package repo
+
+import (
+  "context"
+  "crypto/rand"
+  "crypto/sha256"
+  "encoding/hex"
+  "fmt"
+  "strings"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/keyset"
+)
+
+const apiKeyPrefix = "tt_"
+
+type IAuthBL interface {
+  AuthenticateKey(ctx context.Context, key string) (*dto.Principal, error)
+  AuthenticateToken(ctx context.Context, token string) (*dto.Principal, error)
+  CreateApiKey(ctx context.Context, key dto.ApiKey) (*dto.ApiKey, error)
+  GetApiKeys(ctx context.Context) ([]dto.ApiKey, error)
+  DeleteApiKey(ctx context.Context, id string) error
+  Bootstrap(ctx context.Context, key string) error
+  ResolveTenant(ctx context.Context, principal *dto.Principal, requested string) (string, error)
+}
+
+type authBL struct {
+  db    *db.Store
+  keys  *keyset.KeySet
+  guard *Guard
+}
+
+func NewAuthBL(db *db.Store, keys *keyset.KeySet, guard *Guard) IAuthBL {
+  return &authBL{db: db, keys: keys, guard: guard}
+}
+
+// IsApiKey отличает API-ключ от JWT в заголовке Authorization
+func IsApiKey(credential string) bool {
+  return strings.HasPrefix(credential, apiKeyPrefix)
+}
+
+func (a *authBL) AuthenticateKey(ctx context.Context, key string) (*dto.Principal, error) {
+  apiKey, err := a.db.ApiKey.GetByHash(ctx, hashKey(key))
+  if err != nil {
+    return nil, err
+  }
+  if apiKey == nil {
+    return nil, fmt.Errorf("%w: неизвестный API-ключ", ErrUnauthorized)
+  }
+  if err = a.db.ApiKey.TouchKey(ctx, apiKey.ID); err != nil {
+    return nil, err
+  }
+  return &dto.Principal{
+    ID:             apiKey.ID,
+    PersonID:       apiKey.PersonID,
+    OrganizationID: apiKey.OrganizationID,
+    Name:           apiKey.Name,
+    Method:         dto.AuthApiKey,
+    Role:           apiKey.Role,
+  }, nil
+}
+
+func (a *authBL) AuthenticateToken(_ context.Context, token string) (*dto.Principal, error) {
+  claims, err := a.keys.Parse(token)
+  if err != nil {
+    return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+  }
+  personID := ""
+  if utils.IsValidUUID(claims.Subject) {
+    personID = claims.Subject
+  }
+  role := claims.Role
+  if role == "" {
+    role = dto.RoleSelf
+  }
+  if !dto.IsValidRole(role) {
+    return nil, fmt.Errorf("%w: неизвестная роль %s", ErrUnauthorized, role)
+  }
+  if claims.Org != "" && !utils.IsValidUUID(claims.Org) {
+    return nil, fmt.Errorf("%w: org %s не валидный", ErrUnauthorized, claims.Org)
+  }
+  return &dto.Principal{
+    ID:             claims.Subject,
+    PersonID:       personID,
+    OrganizationID: claims.Org,
+    Name:           claims.Name,
+    Method:         dto.AuthJWT,
+    Role:           role,
+  }, nil
+}
+
+// CreateApiKey выпускает новый ключ, открытое значение возвращается только здесь
+func (a *authBL) CreateApiKey(ctx context.Context, key dto.ApiKey) (*dto.ApiKey, error) {
+  if err := a.guard.check(ctx, policy.ApiKeyManage, ""); err != nil {
+    return nil, err
+  }
+  if key.Role == "" {
+    key.Role = dto.RoleSelf
+  }
+  if !dto.IsValidRole(key.Role) {
+    return nil, fmt.Errorf("неизвестная роль %s", key.Role)
+  }
+  if key.PersonID != "" {
+    if _, err := a.db.People.GetByUUID(ctx, key.PersonID); err != nil {
+      return nil, err
+    }
+  }
+  key.OrganizationID = utils.TenantFromCtx(ctx)
+  raw, err := generateKey()
+  if err != nil {
+    return nil, err
+  }
+  key.Prefix = raw[:len(apiKeyPrefix)+8]
+  created, err := a.db.ApiKey.CreateKey(ctx, &key, hashKey(raw))
+  if err != nil {
+    return nil, err
+  }
+  created.Key = raw
+  return created, nil
+}
+
+func (a *authBL) GetApiKeys(ctx context.Context) ([]dto.ApiKey, error) {
+  if err := a.guard.check(ctx, policy.ApiKeyManage, ""); err != nil {
+    return nil, err
+  }
+  return a.db.ApiKey.GetKeys(ctx)
+}
+
+func (a *authBL) DeleteApiKey(ctx context.Context, id string) error {
+  if err := a.guard.check(ctx, policy.ApiKeyManage, ""); err != nil {
+    return err
+  }
+  return a.db.ApiKey.DeleteKey(ctx, id)
+}
+
+// ResolveTenant определяет организацию запроса. Вызывающий, привязанный к организации,
+// работает только в ней; выбрать организацию заголовком может только администратор без организации
+func (a *authBL) ResolveTenant(_ context.Context, principal *dto.Principal, requested string) (string, error) {
+  if requested != "" && !utils.IsValidUUID(requested) {
+    return "", fmt.Errorf("организация %s не валидная", requested)
+  }
+  if principal.OrganizationID != "" {
+    if requested != "" && requested != principal.OrganizationID {
+      return "", fmt.Errorf("%w: нет доступа к организации %s", ErrForbidden, requested)
+    }
+    return principal.OrganizationID, nil
+  }
+  if requested != "" {
+    if principal.Role != dto.RoleAdmin {
+      return "", fmt.Errorf("%w: выбирать организацию может только администратор", ErrForbidden)
+    }
+    return requested, nil
+  }
+  return dto.DefaultOrganization, nil
+}
+
+// Bootstrap регистрирует ключ администратора из конфигурации, если его еще нет.
+// Ключ не привязан к организации
+func (a *authBL) Bootstrap(ctx context.Context, key string) error {
+  if key == "" {
+    return nil
+  }
+  if !IsApiKey(key) {
+    return fmt.Errorf("ключ администратора должен начинаться с %s", apiKeyPrefix)
+  }
+  hash := hashKey(key)
+  existing, err := a.db.ApiKey.GetByHash(ctx, hash)
+  if err != nil || existing != nil {
+    return err
+  }
+  _, err = a.db.ApiKey.CreateKey(ctx, &dto.ApiKey{
+    Name:   "bootstrap",
+    Role:   dto.RoleAdmin,
+    Prefix: key[:min(len(key), len(apiKeyPrefix)+8)],
+  }, hash)
+  return err
+}
+
+func generateKey() (string, error) {
+  buf := make([]byte, 24)
+  if _, err := rand.Read(buf); err != nil {
+    return "", fmt.Errorf("ошибка генерации ключа: %v", err)
+  }
+  return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+func hashKey(key string) string {
+  sum := sha256.Sum256([]byte(key))
+  return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+  "context"
+  "errors"
+  "testing"
+  "timetracker/internal/bl/dto"
+)
+
+func TestResolveTenant(t *testing.T) {
+  const own, other = "11111111-1111-1111-1111-111111111111", "22222222-2222-2222-2222-222222222222"
+  cases := map[string]struct {
+    principal dto.Principal
+    requested string
+    want      string
+    forbidden bool
+  }{
+    "своя организация":              {dto.Principal{Role: dto.RoleManager, OrganizationID: own}, "", own, false},
+    "своя организация в заголовке":  {dto.Principal{Role: dto.RoleSelf, OrganizationID: own}, own, own, false},
+    "чужая организация":             {dto.Principal{Role: dto.RoleAdmin, OrganizationID: own}, other, "", true},
+    "администратор без организации": {dto.Principal{Role: dto.RoleAdmin}, other, other, false},
+    "руководитель без организации":  {dto.Principal{Role: dto.RoleManager}, other, "", true},
+    "сотрудник без организации":     {dto.Principal{Role: dto.RoleSelf}, other, "", true},
+    "без заголовка":                 {dto.Principal{Role: dto.RoleSelf}, "", dto.DefaultOrganization, false},
+  }
+  a := &authBL{}
+  for name, c := range cases {
+    t.Run(name, func(t *testing.T) {
+      got, err := a.ResolveTenant(context.Background(), &c.principal, c.requested)
+      if c.forbidden {
+        if !errors.Is(err, ErrForbidden) {
+          t.Fatalf("ожидался отказ, получено %q, %v", got, err)
+        }
+        return
+      }
+      if err != nil || got != c.want {
+        t.Fatalf("ожидалась организация %s, получено %q, %v", c.want, got, err)
+      }
+    })
+  }
+}
@@ -0,0 +1,164 @@
+package repo
+
+import (
+  "context"
+  "fmt"
+  "strconv"
+  "strings"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+)
+
+// MaxBatchOps наибольшее число операций в одном пакете
+const MaxBatchOps = 1000
+
+type IBatchBL interface {
+  Run(ctx context.Context, ops []dto.BatchOp, atomic bool) ([]dto.BatchResult, error)
+}
+
+type batchBL struct {
+  db     *db.Store
+  people IPeopleBL
+  task   ITaskBL
+}
+
+func NewBatchBL(db *db.Store, people IPeopleBL, task ITaskBL) IBatchBL {
+  return &batchBL{db: db, people: people, task: task}
+}
+
+// Run выполняет операции по порядку. При atomic все операции идут в одной транзакции:
+// первая ошибка откатывает пакет, результаты возвращаются до операции с ошибкой включительно,
+// а ошибка указывает ее номер. Без atomic каждая операция выполняется отдельно
+func (b *batchBL) Run(ctx context.Context, ops []dto.BatchOp, atomic bool) ([]dto.BatchResult, error) {
+  if len(ops) == 0 {
+    return nil, fmt.Errorf("%w: пакет пуст", ErrInvalid)
+  }
+  if len(ops) > MaxBatchOps {
+    return nil, fmt.Errorf("%w: в пакете больше %d операций", ErrInvalid, MaxBatchOps)
+  }
+  var invalid []string
+  for i, op := range ops {
+    switch op.Op {
+    case dto.BatchCreatePerson, dto.BatchCreateTask, dto.BatchStart, dto.BatchPause, dto.BatchComplete, dto.BatchEntry:
+    default:
+      invalid = append(invalid, fmt.Sprintf("операция %d: неизвестный тип %q", i, op.Op))
+    }
+  }
+  if len(invalid) > 0 {
+    return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(invalid, "; "))
+  }
+
+  results := make([]dto.BatchResult, 0, len(ops))
+  if !atomic {
+    for _, op := range ops {
+      res, err := b.exec(ctx, op, results, nil)
+      results = append(results, dto.BatchResult{Result: res, Err: err})
+    }
+    return results, nil
+  }
+
+  // данные людей из внешнего сервиса запрашиваются до транзакции, чтобы не держать ее
+  // открытой на время HTTP запросов
+  enriched := make([]*dto.People, len(ops))
+  for i, op := range ops {
+    if op.Op != dto.BatchCreatePerson || utils.PassportValidate(op.PassportNumber) != nil {
+      continue
+    }
+    people, err := b.people.EnrichPeople(ctx, dto.Passport{PassportNumber: op.PassportNumber})
+    if err != nil {
+      return nil, fmt.Errorf("операция %d: %w", i, err)
+    }
+    enriched[i] = &people
+  }
+
+  // opErr ошибка операции, откатившая пакет, в отличие от ошибки самой транзакции
+  var opErr error
+  err := b.db.WithinTx(ctx, func(ctx context.Context) error {
+    for i, op := range ops {
+      res, err := b.exec(ctx, op, results, enriched[i])
+      results = append(results, dto.BatchResult{Result: res, Err: err})
+      if err != nil {
+        opErr = fmt.Errorf("операция %d: %w", i, err)
+        return opErr
+      }
+    }
+    return nil
+  })
+  if opErr != nil {
+    return results, opErr
+  }
+  if err != nil {
+    return nil, err
+  }
+  return results, nil
+}
+
+// exec выполняет операцию op. people - заранее полученные данные создаваемого человека,
+// без них они запрашиваются у внешнего сервиса
+func (b *batchBL) exec(ctx context.Context, op dto.BatchOp, done []dto.BatchResult, people *dto.People) (interface{}, error) {
+  switch op.Op {
+  case dto.BatchCreatePerson:
+    if err := utils.PassportValidate(op.PassportNumber); err != nil {
+      return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+    }
+    passport := dto.Passport{PassportNumber: op.PassportNumber}
+    if people != nil {
+      return b.people.CreateEnrichedPeople(ctx, passport, *people)
+    }
+    return b.people.CreatePeople(ctx, passport)
+  case dto.BatchCreateTask:
+    personID, err := ref(op.PersonID, "person_id", done)
+    if err != nil {
+      return nil, err
+    }
+    return b.task.CreateTask(ctx, &dto.Task{IdPerson: personID, TaskName: op.TaskName})
+  case dto.BatchEntry:
+    taskID, err := ref(op.TaskID, "task_id", done)
+    if err != nil {
+      return nil, err
+    }
+    if op.Start == nil || op.End == nil {
+      return nil, fmt.Errorf("%w: для entry нужны start и end", ErrInvalid)
+    }
+    return b.task.AddEntry(ctx, taskID, *op.Start, *op.End)
+  }
+
+  taskID, err := ref(op.TaskID, "task_id", done)
+  if err != nil {
+    return nil, err
+  }
+  switch op.Op {
+  case dto.BatchStart:
+    err = b.task.StartTask(ctx, "", taskID, op.Version)
+  case dto.BatchPause:
+    err = b.task.PauseTask(ctx, "", taskID, op.Version)
+  default:
+    err = b.task.CompleteTask(ctx, "", taskID, op.Version)
+  }
+  return nil, err
+}
+
+// ref подставляет id результата предыдущей операции вместо ссылки "$N"
+func ref(value, field string, done []dto.BatchResult) (string, error) {
+  if value == "" {
+    return "", fmt.Errorf("%w: не задано поле %s", ErrInvalid, field)
+  }
+  if !strings.HasPrefix(value, "$") {
+    return value, nil
+  }
+  i, err := strconv.Atoi(value[1:])
+  if err != nil || i < 0 || i >= len(done) {
+    return "", fmt.Errorf("%w: %s ссылается на несуществующую операцию %s", ErrInvalid, field, value)
+  }
+  if done[i].Err != nil {
+    return "", fmt.Errorf("%s ссылается на операцию %d, которая завершилась ошибкой", field, i)
+  }
+  switch res := done[i].Result.(type) {
+  case *dto.Person:
+    return res.ID, nil
+  case *dto.Task:
+    return res.IdTask, nil
+  }
+  return "", fmt.Errorf("%w: операция %d не создает запись для %s", ErrInvalid, i, field)
+}
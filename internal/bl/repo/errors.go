@@ -0,0 +1,17 @@
+package repo
+
+import "errors"
+
+var (
+  ErrUnauthorized = errors.New("требуется аутентификация")
+  ErrForbidden    = errors.New("недостаточно прав")
+  ErrInvalid      = errors.New("некорректные данные")
+
+  // ErrPreconditionFailed версия записи не совпала с ожидаемой вызывающим
+  ErrPreconditionFailed = errors.New("запись изменена, версия не совпадает")
+
+  // ErrKeyReused ключ идемпотентности повторно использован с другим запросом
+  ErrKeyReused     = errors.New("ключ идемпотентности уже использован")
+  // ErrKeyInProgress запрос с тем же ключом идемпотентности еще выполняется
+  ErrKeyInProgress = errors.New("запрос с ключом идемпотентности выполняется")
+)
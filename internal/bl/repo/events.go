@@ -0,0 +1,74 @@
+package repo
+
+import (
+  "context"
+  "fmt"
+  "slices"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/events"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+)
+
+type IEventsBL interface {
+  Subscribe(ctx context.Context, filter dto.EventFilter, lastID string) (*events.Subscription, error)
+}
+
+type eventsBL struct {
+  db    *db.Store
+  guard *Guard
+  bus   *events.Bus
+}
+
+func NewEventsBL(db *db.Store, guard *Guard, bus *events.Bus) IEventsBL {
+  return &eventsBL{db: db, guard: guard, bus: bus}
+}
+
+// Subscribe подписывает на события задач организации запроса, которые вызывающему
+// разрешено читать. Команды руководителя определяются в момент подписки
+func (e *eventsBL) Subscribe(ctx context.Context, filter dto.EventFilter, lastID string) (*events.Subscription, error) {
+  principal, scope, err := e.guard.scope(ctx, policy.TaskRead)
+  if err != nil {
+    return nil, err
+  }
+  if scope != policy.ScopeAny && principal.PersonID == "" {
+    return nil, fmt.Errorf("%w: %s", ErrForbidden, policy.TaskRead)
+  }
+  var managed []string
+  if scope == policy.ScopeTeam {
+    teams, err := e.db.Team.GetTeams(ctx, principal.PersonID)
+    if err != nil {
+      return nil, err
+    }
+    for _, team := range teams {
+      managed = append(managed, team.ID)
+    }
+  }
+
+  org := utils.TenantFromCtx(ctx)
+  match := func(ev dto.Event) bool {
+    if ev.OrganizationID != org {
+      return false
+    }
+    if filter.PersonID != "" && ev.PersonID != filter.PersonID {
+      return false
+    }
+    if filter.TeamID != "" && !slices.Contains(ev.TeamIDs, filter.TeamID) {
+      return false
+    }
+    switch scope {
+    case policy.ScopeOwn:
+      return ev.PersonID == principal.PersonID
+    case policy.ScopeTeam:
+      if ev.PersonID == principal.PersonID || ev.ManagerID == principal.PersonID {
+        return true
+      }
+      return slices.ContainsFunc(ev.TeamIDs, func(id string) bool {
+        return slices.Contains(managed, id)
+      })
+    }
+    return true
+  }
+  return e.bus.Subscribe(match, lastID), nil
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+  "context"
+  "fmt"
+  "sync/atomic"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+)
+
+// Guard проверяет права вызывающего из контекста по таблице политики
+type Guard struct {
+  db     *db.Store
+  policy atomic.Pointer[policy.Policy]
+}
+
+func NewGuard(db *db.Store, p policy.Policy) *Guard {
+  g := &Guard{db: db}
+  g.SetPolicy(p)
+  return g
+}
+
+// SetPolicy заменяет таблицу политики, проверки после вызова идут по новой
+func (g *Guard) SetPolicy(p policy.Policy) {
+  g.policy.Store(&p)
+}
+
+// scope возвращает вызывающего и область, в которой ему разрешено действие
+func (g *Guard) scope(ctx context.Context, action string) (*dto.Principal, policy.Scope, error) {
+  p := utils.PrincipalFromCtx(ctx)
+  if p == nil {
+    return nil, "", ErrUnauthorized
+  }
+  scope, ok := g.policy.Load().Scope(action, p.Role)
+  if !ok {
+    return p, "", fmt.Errorf("%w: %s", ErrForbidden, action)
+  }
+  return p, scope, nil
+}
+
+// check разрешает действие над записями человека personID
+func (g *Guard) check(ctx context.Context, action, personID string) error {
+  p, scope, err := g.scope(ctx, action)
+  if err != nil {
+    return err
+  }
+  if scope == policy.ScopeAny {
+    return nil
+  }
+  if p.PersonID != "" && personID != "" {
+    if p.PersonID == personID {
+      return nil
+    }
+    if scope == policy.ScopeTeam {
+      managed, err := g.db.People.IsManagedBy(ctx, personID, p.PersonID)
+      if err != nil {
+        return err
+      }
+      if managed {
+        return nil
+      }
+    }
+  }
+  return fmt.Errorf("%w: %s", ErrForbidden, action)
+}
+
+// checkTeam разрешает действие над командой: в области team только ее руководителю
+func (g *Guard) checkTeam(ctx context.Context, action string, team *dto.Team) error {
+  p, scope, err := g.scope(ctx, action)
+  if err != nil {
+    return err
+  }
+  if scope == policy.ScopeAny {
+    return nil
+  }
+  if scope == policy.ScopeTeam && p.PersonID != "" && team.ManagerID == p.PersonID {
+    return nil
+  }
+  return fmt.Errorf("%w: %s", ErrForbidden, action)
+}
+
+// allowed отбирает людей, над записями которых разрешено действие, одним запросом вместо check по каждому
+func (g *Guard) allowed(ctx context.Context, action string, personIDs []string) (map[string]bool, error) {
+  p, scope, err := g.scope(ctx, action)
+  if err != nil {
+    return nil, err
+  }
+  res := make(map[string]bool, len(personIDs))
+  if scope == policy.ScopeAny {
+    for _, id := range personIDs {
+      res[id] = true
+    }
+    return res, nil
+  }
+  if p.PersonID == "" {
+    return res, nil
+  }
+  for _, id := range personIDs {
+    if id == p.PersonID {
+      res[id] = true
+    }
+  }
+  if scope == policy.ScopeTeam {
+    managed, err := g.db.People.ManagedAmong(ctx, personIDs, p.PersonID)
+    if err != nil {
+      return nil, err
+    }
+    for _, id := range managed {
+      res[id] = true
+    }
+  }
+  return res, nil
+}
+
+// keys ключи множества, собранного allowed
+func keys(set map[string]bool) []string {
+  res := make([]string, 0, len(set))
+  for id := range set {
+    res = append(res, id)
+  }
+  return res
+}
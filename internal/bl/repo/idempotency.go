@@ -0,0 +1,55 @@
+package repo
+
+import (
+  "context"
+  "fmt"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+)
+
+type IIdempotencyBL interface {
+  Begin(ctx context.Context, key, hash string) (*dto.IdempotentResponse, error)
+  Complete(ctx context.Context, key string, resp *dto.IdempotentResponse) error
+  Release(ctx context.Context, key string) error
+}
+
+type idempotencyBL struct {
+  db *db.Store
+}
+
+func NewIdempotencyBL(db *db.Store) IIdempotencyBL {
+  return &idempotencyBL{db: db}
+}
+
+// Begin занимает ключ вызывающего под запрос с хешем hash. Если ключ уже выполнен
+// с тем же запросом, возвращает сохраненный ответ для повтора
+func (i *idempotencyBL) Begin(ctx context.Context, key, hash string) (*dto.IdempotentResponse, error) {
+  if len(key) > 255 {
+    return nil, fmt.Errorf("%w: Idempotency-Key длиннее 255 символов", ErrInvalid)
+  }
+  actor := utils.PrincipalFromCtx(ctx).Actor()
+  claimed, err := i.db.Idem.Claim(ctx, actor, key, hash)
+  if err != nil || claimed {
+    return nil, err
+  }
+  storedHash, resp, err := i.db.Idem.Get(ctx, actor, key)
+  if err != nil {
+    return nil, err
+  }
+  if storedHash != hash {
+    return nil, fmt.Errorf("%w: ключ %s уже использован с другим запросом", ErrKeyReused, key)
+  }
+  if resp == nil {
+    return nil, fmt.Errorf("%w: запрос с ключом %s еще выполняется", ErrKeyInProgress, key)
+  }
+  return resp, nil
+}
+
+func (i *idempotencyBL) Complete(ctx context.Context, key string, resp *dto.IdempotentResponse) error {
+  return i.db.Idem.Complete(ctx, utils.PrincipalFromCtx(ctx).Actor(), key, resp)
+}
+
+func (i *idempotencyBL) Release(ctx context.Context, key string) error {
+  return i.db.Idem.Release(ctx, utils.PrincipalFromCtx(ctx).Actor(), key)
+}
@@ -0,0 +1,55 @@
+package repo
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strings"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+)
+
+type IOrganizationBL interface {
+  CreateOrganization(ctx context.Context, name string) (*dto.Organization, error)
+  GetOrganizations(ctx context.Context) ([]dto.Organization, error)
+}
+
+type organizationBL struct {
+  db    *db.Store
+  guard *Guard
+}
+
+func NewOrganizationBL(db *db.Store, guard *Guard) IOrganizationBL {
+  return &organizationBL{db: db, guard: guard}
+}
+
+// checkGlobal управлять организациями может только вызывающий, не привязанный к организации
+func (o *organizationBL) checkGlobal(ctx context.Context) error {
+  if err := o.guard.check(ctx, policy.OrganizationManage, ""); err != nil {
+    return err
+  }
+  if utils.PrincipalFromCtx(ctx).OrganizationID != "" {
+    return fmt.Errorf("%w: %s", ErrForbidden, policy.OrganizationManage)
+  }
+  return nil
+}
+
+func (o *organizationBL) CreateOrganization(ctx context.Context, name string) (*dto.Organization, error) {
+  if err := o.checkGlobal(ctx); err != nil {
+    return nil, err
+  }
+  name = strings.TrimSpace(name)
+  if name == "" {
+    return nil, errors.New("не заполнено название организации")
+  }
+  return o.db.Org.CreateOrganization(ctx, name)
+}
+
+func (o *organizationBL) GetOrganizations(ctx context.Context) ([]dto.Organization, error) {
+  if err := o.checkGlobal(ctx); err != nil {
+    return nil, err
+  }
+  return o.db.Org.GetOrganizations(ctx)
+}
@@ -2,38 +2,80 @@ package repo
 
 import (
   "context"
+  "errors"
   "fmt"
   "github.com/brianvoe/gofakeit/v7"
-  "log/slog"
   "strings"
   "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/policy"
   "timetracker/internal/db"
+  dbrepo "timetracker/internal/db/repo"
   "timetracker/internal/utils"
   "timetracker/internal/utils/var/endpoint"
 )
 
 type IPeopleBL interface {
   CreatePeople(ctx context.Context, passport dto.Passport) (*dto.Person, error)
+  EnrichPeople(ctx context.Context, passport dto.Passport) (dto.People, error)
+  CreateEnrichedPeople(ctx context.Context, passport dto.Passport, people dto.People) (*dto.Person, error)
   FakePeople(ctx context.Context, series, number string) (dto.People, error)
-  DeletePeople(ctx context.Context, uuid string) error
+  DeletePeople(ctx context.Context, uuid string, version int) error
   GetPeopleUUID(ctx context.Context, uuid string) (*dto.Person, error)
-  UpdatePeople(ctx context.Context, people dto.Person) (*dto.Person, error)
-  GetPeople(ctx context.Context, filter *dto.Person, offset, limit int) ([]dto.Person, int, error)
+  UpdatePeople(ctx context.Context, patch dto.PersonPatch) (*dto.Person, error)
+  GetPeople(ctx context.Context, filter *dto.PeopleFilter, page dto.PageRequest) ([]dto.Person, dto.PageInfo, error)
+  SearchPeople(ctx context.Context, q string, limit int) ([]dto.PersonMatch, error)
+  FindDuplicates(ctx context.Context, threshold float64, limit int) ([]dto.Duplicate, error)
+  MergePeople(ctx context.Context, sourceID, targetID string) (*dto.MergeResult, error)
+  GetPeopleByUUIDs(ctx context.Context, uuids []string) ([]dto.Person, error)
 }
 
 type peopleBL struct {
-  db *db.DbRepo
+  db    *db.Store
+  guard *Guard
 }
 
-func NewPeopleBL(db *db.DbRepo) IPeopleBL {
+func NewPeopleBL(db *db.Store, guard *Guard) IPeopleBL {
   return &peopleBL{
-    db: db,
+    db:    db,
+    guard: guard,
   }
 }
 
 func (p peopleBL) CreatePeople(ctx context.Context, passport dto.Passport) (*dto.Person, error) {
-  ctxLogger := ctx.Value("logger").(*slog.Logger)
-  var people dto.People
+  if existing, err := p.checkCreate(ctx, passport); err != nil {
+    return existing, err
+  }
+  people, err := p.enrich(ctx, passport)
+  if err != nil {
+    return nil, err
+  }
+  return p.insertPeople(ctx, passport, people)
+}
+
+// EnrichPeople данные человека из внешнего сервиса /info, при его недоступности сгенерированные
+func (p peopleBL) EnrichPeople(ctx context.Context, passport dto.Passport) (dto.People, error) {
+  if err := p.guard.check(ctx, policy.PeopleCreate, ""); err != nil {
+    return dto.People{}, err
+  }
+  return p.enrich(ctx, passport)
+}
+
+// CreateEnrichedPeople добавляет человека с данными, заранее полученными EnrichPeople.
+// Внешний сервис не вызывается, поэтому метод не держит открытую транзакцию на время запроса
+func (p peopleBL) CreateEnrichedPeople(ctx context.Context, passport dto.Passport, people dto.People) (*dto.Person, error) {
+  if existing, err := p.checkCreate(ctx, passport); err != nil {
+    return existing, err
+  }
+  return p.insertPeople(ctx, passport, people)
+}
+
+// checkCreate проверяет, что вызывающему разрешено добавлять людей и паспорт еще не занят.
+// Для занятого паспорта возвращает уже добавленного человека вместе с ошибкой
+func (p peopleBL) checkCreate(ctx context.Context, passport dto.Passport) (*dto.Person, error) {
+  ctxLogger := utils.LoggerFromCtx(ctx)
+  if err := p.guard.check(ctx, policy.PeopleCreate, ""); err != nil {
+    return nil, err
+  }
   byPassport, err := p.db.People.GetByPassport(ctx, passport.PassportNumber)
   if byPassport != nil {
     return byPassport, fmt.Errorf("человек с паспортом: %s, уже добавлен", passport)
@@ -43,28 +85,41 @@ func (p peopleBL) CreatePeople(ctx context.Context, passport dto.Passport) (*dto
     ctxLogger.Debug(err.Error())
     return nil, err
   }
+  return nil, nil
+}
 
+func (p peopleBL) enrich(ctx context.Context, passport dto.Passport) (dto.People, error) {
+  ctxLogger := utils.LoggerFromCtx(ctx)
+  var people dto.People
   passportSlice := strings.Split(passport.PassportNumber, " ")
   getQuery := fmt.Sprintf("?passportSerie=%s&passportNumber=%s", passportSlice[0], passportSlice[1])
-  err = utils.GetAPIRequest(ctx, endpoint.GetInfoUrl+getQuery, &people)
+  err := utils.GetAPIRequest(ctx, endpoint.GetInfoUrl+getQuery, &people)
   if err != nil {
     ctxLogger.Debug("/info error")
-    people, err = p.FakePeople(ctx, passportSlice[0], passportSlice[1])
-    if err != nil {
-      return nil, err
-    }
+    return p.FakePeople(ctx, passportSlice[0], passportSlice[1])
   }
+  return people, nil
+}
+
+func (p peopleBL) insertPeople(ctx context.Context, passport dto.Passport, people dto.People) (*dto.Person, error) {
+  ctxLogger := utils.LoggerFromCtx(ctx)
   person := &dto.Person{
     People:   people,
     Passport: passport,
   }
 
-  person.ID, err = p.db.People.CreatePerson(ctx, person)
+  err := p.db.WithinTx(ctx, func(ctx context.Context) (err error) {
+    person.ID, err = p.db.People.CreatePerson(ctx, person)
+    if err != nil {
+      ctxLogger.Debug(err.Error())
+      return err
+    }
+    return enqueue(ctx, p.db, dto.WebhookPersonCreated, person)
+  })
   if err != nil {
-    ctxLogger.Debug(err.Error())
     return nil, err
   }
-  return person, err
+  return person, nil
 }
 
 func (p peopleBL) FakePeople(_ context.Context, series, number string) (dto.People, error) {
@@ -78,15 +133,32 @@ func (p peopleBL) FakePeople(_ context.Context, series, number string) (dto.Peop
   return people, nil
 }
 
-func (p peopleBL) DeletePeople(ctx context.Context, uuid string) error {
-  err := p.db.People.DeleteByPerson(ctx, uuid)
-  if err != nil {
+// DeletePeople удаляет человека, при ненулевой version только если запись не менялась
+func (p peopleBL) DeletePeople(ctx context.Context, uuid string, version int) error {
+  if err := p.guard.check(ctx, policy.PeopleDelete, uuid); err != nil {
     return err
   }
-  return nil
+  if version != 0 {
+    person, err := p.db.People.GetByUUID(ctx, uuid)
+    if err != nil {
+      return err
+    }
+    if person.Version != version {
+      return fmt.Errorf("%w: текущая версия %d", ErrPreconditionFailed, person.Version)
+    }
+  }
+  return p.db.WithinTx(ctx, func(ctx context.Context) error {
+    if err := p.db.People.DeleteByPerson(ctx, uuid, version); err != nil {
+      return err
+    }
+    return enqueue(ctx, p.db, dto.WebhookPersonDeleted, map[string]string{"id": uuid})
+  })
 }
 
 func (p peopleBL) GetPeopleUUID(ctx context.Context, uuid string) (*dto.Person, error) {
+  if err := p.guard.check(ctx, policy.PeopleRead, uuid); err != nil {
+    return nil, err
+  }
   people, err := p.db.People.GetByUUID(ctx, uuid)
   if err != nil {
     return people, err
@@ -94,28 +166,203 @@ func (p peopleBL) GetPeopleUUID(ctx context.Context, uuid string) (*dto.Person,
   return people, nil
 }
 
-func (p peopleBL) UpdatePeople(ctx context.Context, people dto.Person) (*dto.Person, error) {
-  oldPerson, err := p.db.People.GetByUUID(ctx, people.ID)
+// GetPeopleByUUIDs люди по списку UUID одним запросом. Люди, которых вызывающему нельзя читать
+// или которых нет, в ответ не попадают
+func (p peopleBL) GetPeopleByUUIDs(ctx context.Context, uuids []string) ([]dto.Person, error) {
+  allowed, err := p.guard.allowed(ctx, policy.PeopleRead, uuids)
   if err != nil {
     return nil, err
   }
-  oldPerson.Name = UpdateField(people.Name, oldPerson.Name)
-  oldPerson.Surname = UpdateField(people.Surname, oldPerson.Surname)
-  oldPerson.Address = UpdateField(people.Address, oldPerson.Address)
-  oldPerson.Patronymic = UpdateField(people.Patronymic, oldPerson.Patronymic)
-  person, err := p.db.People.UpdatePerson(ctx, oldPerson)
+  if len(allowed) == 0 {
+    return nil, nil
+  }
+  return p.db.People.GetByUUIDs(ctx, keys(allowed))
+}
+
+// UpdatePeople применяет merge patch к человеку: null очищает необязательное поле,
+// измененные поля проверяются, все ошибки проверки возвращаются вместе
+func (p peopleBL) UpdatePeople(ctx context.Context, patch dto.PersonPatch) (*dto.Person, error) {
+  if err := p.guard.check(ctx, policy.PeopleUpdate, patch.ID); err != nil {
+    return nil, err
+  }
+  oldPerson, err := p.db.People.GetByUUID(ctx, patch.ID)
+  if err != nil {
+    return nil, err
+  }
+  if patch.Version != 0 && patch.Version != oldPerson.Version {
+    return nil, fmt.Errorf("%w: текущая версия %d", ErrPreconditionFailed, oldPerson.Version)
+  }
+
+  var invalid []string
+  apply := func(field string, value dto.PatchString, dst *string, required bool) {
+    if !value.Set {
+      return
+    }
+    if value.Null || strings.TrimSpace(value.Value) == "" {
+      if required {
+        invalid = append(invalid, fmt.Sprintf("поле %s не может быть пустым", field))
+        return
+      }
+      *dst = ""
+      return
+    }
+    *dst = value.Value
+  }
+  apply("surname", patch.Surname, &oldPerson.Surname, true)
+  apply("name", patch.Name, &oldPerson.Name, true)
+  apply("patronymic", patch.Patronymic, &oldPerson.Patronymic, false)
+  apply("address", patch.Address, &oldPerson.Address, true)
+
+  passport := oldPerson.PassportNumber
+  apply("passportNumber", patch.PassportNumber, &passport, true)
+  if passport != oldPerson.PassportNumber {
+    if err = utils.PassportValidate(passport); err != nil {
+      invalid = append(invalid, fmt.Sprintf("поле passportNumber: %v", err))
+    } else {
+      byPassport, err := p.db.People.GetByPassport(ctx, passport)
+      if err != nil {
+        return nil, err
+      }
+      if byPassport != nil && byPassport.ID != oldPerson.ID {
+        invalid = append(invalid, fmt.Sprintf("человек с паспортом %s уже добавлен", passport))
+      }
+      oldPerson.PassportNumber = passport
+    }
+  }
+
+  managerID := oldPerson.ManagerID
+  apply("manager_id", patch.ManagerID, &managerID, false)
+  if managerID != oldPerson.ManagerID {
+    if err = p.guard.check(ctx, policy.PeopleManager, patch.ID); err != nil {
+      return nil, err
+    }
+    switch {
+    case managerID == "":
+    case !utils.IsValidUUID(managerID):
+      invalid = append(invalid, fmt.Sprintf("поле manager_id: uuid %s не валидный", managerID))
+    case managerID == patch.ID:
+      invalid = append(invalid, "человек не может быть своим руководителем")
+    default:
+      if _, err = p.db.People.GetByUUID(ctx, managerID); err != nil {
+        invalid = append(invalid, fmt.Sprintf("поле manager_id: %v", err))
+      }
+    }
+    oldPerson.ManagerID = managerID
+  }
+
+  if len(invalid) > 0 {
+    return nil, fmt.Errorf("%w: %s", ErrInvalid, strings.Join(invalid, "; "))
+  }
+
+  var person *dto.Person
+  err = p.db.WithinTx(ctx, func(ctx context.Context) (err error) {
+    // запись обновляется только в прочитанной версии, параллельное изменение не теряется
+    person, err = p.db.People.UpdatePerson(ctx, oldPerson)
+    if errors.Is(err, dbrepo.ErrStale) {
+      return fmt.Errorf("%w: %v", ErrPreconditionFailed, err)
+    }
+    if err != nil {
+      return err
+    }
+    return enqueue(ctx, p.db, dto.WebhookPersonUpdated, person)
+  })
   if err != nil {
     return nil, err
   }
   return person, nil
 }
 
-func (p peopleBL) GetPeople(ctx context.Context, filter *dto.Person, offset, limit int) ([]dto.Person, int, error) {
-  persons, total, err := p.db.People.GetPeople(ctx, filter, offset, limit)
+// listScope сужает фильтр до людей, доступных вызывающему по действию people.list
+func (p peopleBL) listScope(ctx context.Context, filter *dto.PeopleFilter) error {
+  principal, scope, err := p.guard.scope(ctx, policy.PeopleList)
   if err != nil {
-    return nil, 0, err
+    return err
+  }
+  if scope != policy.ScopeAny && principal.PersonID == "" {
+    return fmt.Errorf("%w: %s", ErrForbidden, policy.PeopleList)
+  }
+  switch scope {
+  case policy.ScopeOwn:
+    filter.ID = principal.PersonID
+  case policy.ScopeTeam:
+    filter.ManagerID = principal.PersonID
+  }
+  return nil
+}
+
+func (p peopleBL) GetPeople(ctx context.Context, filter *dto.PeopleFilter, page dto.PageRequest) ([]dto.Person, dto.PageInfo, error) {
+  if err := p.listScope(ctx, filter); err != nil {
+    return nil, dto.PageInfo{}, err
+  }
+  return p.db.People.GetPeople(ctx, filter, page)
+}
+
+func (p peopleBL) SearchPeople(ctx context.Context, q string, limit int) ([]dto.PersonMatch, error) {
+  if strings.TrimSpace(q) == "" {
+    return nil, fmt.Errorf("пустой поисковый запрос")
+  }
+  filter := &dto.PeopleFilter{}
+  if err := p.listScope(ctx, filter); err != nil {
+    return nil, err
+  }
+  return p.db.People.SearchPeople(ctx, filter, q, limit)
+}
+
+func (p peopleBL) FindDuplicates(ctx context.Context, threshold float64, limit int) ([]dto.Duplicate, error) {
+  if err := p.guard.check(ctx, policy.PeopleMerge, ""); err != nil {
+    return nil, err
+  }
+  return p.db.People.FindDuplicates(ctx, threshold, limit)
+}
+
+// MergePeople сливает дубликат sourceID в targetID: задачи и связи переносятся,
+// источник помечается удаленным, слияние записывается в журнал аудита. Все в одной транзакции
+func (p peopleBL) MergePeople(ctx context.Context, sourceID, targetID string) (res *dto.MergeResult, err error) {
+  if err = p.guard.check(ctx, policy.PeopleMerge, ""); err != nil {
+    return nil, err
+  }
+  if sourceID == targetID {
+    return nil, fmt.Errorf("нельзя слить человека с самим собой")
+  }
+
+  var moved int64
+  var auditID string
+  var target *dto.Person
+  err = p.db.WithinTx(ctx, func(ctx context.Context) (err error) {
+    source, err := p.db.People.GetByUUID(ctx, sourceID)
+    if err != nil {
+      return err
+    }
+    // MergePerson заново проверяет обоих под блокировкой, слияние с уже удаленным не пройдет
+    moved, err = p.db.People.MergePerson(ctx, sourceID, targetID)
+    if err != nil {
+      return err
+    }
+    if target, err = p.db.People.GetByUUID(ctx, targetID); err != nil {
+      return err
+    }
+    auditID, err = p.db.Audit.Write(ctx, &dto.AuditRecord{
+      Actor:    utils.PrincipalFromCtx(ctx).Actor(),
+      Action:   policy.PeopleMerge,
+      Entity:   "person",
+      EntityID: targetID,
+      Details: map[string]interface{}{
+        "source_id":       sourceID,
+        "source_passport": source.PassportNumber,
+        "moved_tasks":     moved,
+      },
+    })
+    return err
+  })
+  if err != nil {
+    return nil, err
   }
-  return persons, total, nil
+  return &dto.MergeResult{
+    Target:     *target,
+    SourceID:   sourceID,
+    MovedTasks: moved,
+    AuditID:    auditID,
+  }, nil
 }
 
 func UpdateField(new, old string) string {
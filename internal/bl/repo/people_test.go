@@ -0,0 +1,19 @@
+package repo
+
+import (
+  "testing"
+  "timetracker/internal/bl/dto"
+)
+
+// TestCreatePeopleWithoutLogger контекст пакетных операций и gRPC может быть без логгера запроса
+func TestCreatePeopleWithoutLogger(t *testing.T) {
+  tasks, store, ctx := newTaskBL(t)
+  bl := NewPeopleBL(store, tasks.guard)
+  person, err := bl.CreatePeople(ctx, dto.Passport{PassportNumber: "1234 567890"})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if person.ID == "" || person.Surname == "" {
+    t.Fatalf("человек без данных: %+v", person)
+  }
+}
@@ -2,142 +2,342 @@ package repo
 
 import (
   "context"
+  "errors"
   "fmt"
+  "time"
   "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/events"
+  "timetracker/internal/bl/policy"
   "timetracker/internal/db"
+  dbrepo "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
   "timetracker/internal/utils/const/status"
 )
 
 type ITaskBL interface {
   CreateTask(ctx context.Context, task *dto.Task) (*dto.Task, error)
-  StartTask(ctx context.Context, idP, idT string) error
-  PauseTask(ctx context.Context, idP, idT string) error
-  CompleteTask(ctx context.Context, idP, idT string) error
+  GetTasks(ctx context.Context, idP string, page dto.PageRequest) ([]dto.Task, dto.PageInfo, error)
+  GetTask(ctx context.Context, idP, idT string) (*dto.Task, error)
+  StartTask(ctx context.Context, idP, idT string, version int) error
+  PauseTask(ctx context.Context, idP, idT string, version int) error
+  CompleteTask(ctx context.Context, idP, idT string, version int) error
   TimeTasks(ctx context.Context, id, start, end string) ([]dto.TaskTimeResult, error)
+  AddEntry(ctx context.Context, idT string, start, end time.Time) (*dto.TimeTask, error)
+  GetTasksOfPeople(ctx context.Context, idPs []string, limit int) (map[string][]dto.Task, map[string]dto.PageInfo, error)
+  GetEntries(ctx context.Context, tasks []dto.Task) (map[string][]dto.TimeTask, error)
 }
 
 type taskBL struct {
-  db *db.DbRepo
+  db    *db.Store
+  guard *Guard
+  bus   *events.Bus
 }
 
-func NewTaskBL(db *db.DbRepo) ITaskBL {
-  return &taskBL{db: db}
+func NewTaskBL(db *db.Store, guard *Guard, bus *events.Bus) ITaskBL {
+  return &taskBL{db: db, guard: guard, bus: bus}
 }
 
-func (t *taskBL) CreateTask(ctx context.Context, task *dto.Task) (*dto.Task, error) {
-
-  _, err := t.db.People.GetByUUID(ctx, task.IdPerson)
+// checkTask проверяет, что задача принадлежит человеку (если idP задан) и вызывающему разрешено действие над ней,
+// при ненулевой version еще и что задача не менялась
+func (t *taskBL) checkTask(ctx context.Context, action, idP, idT string, version int) (*dto.Task, error) {
+  task, err := t.db.Task.GetTask(ctx, idT)
   if err != nil {
     return nil, err
   }
-
-  createTask, err := t.db.Task.CreateTask(ctx, task)
-  if err != nil {
+  if idP != "" && task.IdPerson != idP {
+    return nil, fmt.Errorf("задача с id %s не найдена", idT)
+  }
+  if err = t.guard.check(ctx, action, task.IdPerson); err != nil {
     return nil, err
   }
+  if version != 0 && task.Version != version {
+    return nil, fmt.Errorf("%w: текущая версия %d", ErrPreconditionFailed, task.Version)
+  }
+  return task, nil
+}
 
-  return createTask, nil
+// updateStatus меняет статус задачи в транзакции из ctx. При ненулевой version запись меняется только
+// в этой версии, так изменение между checkTask и транзакцией не теряется
+func (t *taskBL) updateStatus(ctx context.Context, idT, st string, version int) error {
+  err := t.db.Task.UpdateStatus(ctx, idT, st, version)
+  if errors.Is(err, dbrepo.ErrStale) {
+    return fmt.Errorf("%w: %v", ErrPreconditionFailed, err)
+  }
+  return err
 }
 
-func (t *taskBL) StartTask(ctx context.Context, idP, idT string) error {
-  ctx, err := t.db.Begin(ctx)
+// publish отправляет события об изменении задачи в шину после фиксации транзакции
+func (t *taskBL) publish(ctx context.Context, task *dto.Task, types ...string) error {
+  person, err := t.db.People.GetByUUID(ctx, task.IdPerson)
   if err != nil {
     return err
   }
-  defer func() {
-    t.db.End(ctx, err)
-  }()
-
-  st, err := t.db.Task.GetTaskStatus(ctx, idT)
+  teams, err := t.db.Team.PersonTeams(ctx, task.IdPerson)
   if err != nil {
     return err
   }
-  if st == status.New || st == status.Pause {
-    err = t.db.TimeTask.StartTimer(ctx, idT)
-    if err != nil {
-      return err
+  at := time.Now()
+  st := task.TaskStatus
+  org := utils.TenantFromCtx(ctx)
+  t.db.AfterCommit(ctx, func() {
+    for _, typ := range types {
+      t.bus.Publish(dto.Event{
+        Type:           typ,
+        At:             at,
+        OrganizationID: org,
+        TaskID:         task.IdTask,
+        TaskName:       task.TaskName,
+        PersonID:       task.IdPerson,
+        Status:         st,
+        ManagerID:      person.ManagerID,
+        TeamIDs:        teams,
+      })
     }
-  } else {
-    err = fmt.Errorf("задача в статусе %s", st)
-    fmt.Println(err)
-    return err
-  }
+  })
+  return nil
+}
 
-  err = t.db.Task.UpdateStatus(ctx, idT, status.Work)
-  if err != nil {
-    return err
+// enqueue пишет события вебхуков об изменении задачи в outbox транзакции из ctx
+func (t *taskBL) enqueue(ctx context.Context, task *dto.Task, types ...string) error {
+  for _, typ := range types {
+    if err := enqueue(ctx, t.db, typ, task); err != nil {
+      return err
+    }
   }
   return nil
 }
 
-func (t *taskBL) PauseTask(ctx context.Context, idP, idT string) error {
-  ctx, err := t.db.Begin(ctx)
+func (t *taskBL) CreateTask(ctx context.Context, task *dto.Task) (*dto.Task, error) {
+  if err := t.guard.check(ctx, policy.TaskCreate, task.IdPerson); err != nil {
+    return nil, err
+  }
+
+  _, err := t.db.People.GetByUUID(ctx, task.IdPerson)
   if err != nil {
-    return err
+    return nil, err
   }
-  defer func() {
-    t.db.End(ctx, err)
-  }()
 
-  st, err := t.db.Task.GetTaskStatus(ctx, idT)
+  var createTask *dto.Task
+  err = t.db.WithinTx(ctx, func(ctx context.Context) (err error) {
+    createTask, err = t.db.Task.CreateTask(ctx, task)
+    if err != nil {
+      return err
+    }
+    if err = t.publish(ctx, createTask, dto.EventTaskStatus); err != nil {
+      return err
+    }
+    return t.enqueue(ctx, createTask, dto.WebhookTaskCreated)
+  })
   if err != nil {
-    return err
+    return nil, err
   }
+  return createTask, nil
+}
 
-  if st != status.Work {
-    err = fmt.Errorf("задача в статусе %s", st)
-    return err
+func (t *taskBL) GetTasks(ctx context.Context, idP string, page dto.PageRequest) ([]dto.Task, dto.PageInfo, error) {
+  if err := t.guard.check(ctx, policy.TaskRead, idP); err != nil {
+    return nil, dto.PageInfo{}, err
   }
+  return t.db.Task.GetTasks(ctx, idP, page)
+}
 
-  err = t.db.TimeTask.StopTimer(ctx, idT)
+// GetTasksOfPeople первые страницы задач нескольких людей одним запросом. Люди, задачи которых
+// вызывающему нельзя читать, в ответ не попадают, у остальных в infos есть запись
+func (t *taskBL) GetTasksOfPeople(ctx context.Context, idPs []string, limit int) (map[string][]dto.Task, map[string]dto.PageInfo, error) {
+  allowed, err := t.guard.allowed(ctx, policy.TaskRead, idPs)
   if err != nil {
-    return err
+    return nil, nil, err
   }
-
-  err = t.db.Task.UpdateStatus(ctx, idT, status.Pause)
+  if len(allowed) == 0 {
+    return map[string][]dto.Task{}, map[string]dto.PageInfo{}, nil
+  }
+  tasks, infos, err := t.db.Task.GetTasksOfPeople(ctx, keys(allowed), limit)
   if err != nil {
-    return err
+    return nil, nil, err
   }
-
-  return nil
+  // у людей без задач пустая страница, отсутствие в infos значит только запрет
+  for id := range allowed {
+    if _, ok := infos[id]; !ok {
+      total := 0
+      infos[id] = dto.PageInfo{Total: &total}
+    }
+  }
+  return tasks, infos, nil
 }
 
-func (t *taskBL) CompleteTask(ctx context.Context, idP, idT string) error {
-  ctx, err := t.db.Begin(ctx)
+// GetEntries интервалы работы задач одним запросом, только задач людей, которые вызывающему разрешено читать
+func (t *taskBL) GetEntries(ctx context.Context, tasks []dto.Task) (map[string][]dto.TimeTask, error) {
+  idPs := make([]string, 0, len(tasks))
+  for _, task := range tasks {
+    idPs = append(idPs, task.IdPerson)
+  }
+  allowed, err := t.guard.allowed(ctx, policy.TaskRead, idPs)
   if err != nil {
-    return err
+    return nil, err
+  }
+  idTs := make([]string, 0, len(tasks))
+  for _, task := range tasks {
+    if allowed[task.IdPerson] {
+      idTs = append(idTs, task.IdTask)
+    }
   }
-  defer func() {
-    t.db.End(ctx, err)
-  }()
+  if len(idTs) == 0 {
+    return map[string][]dto.TimeTask{}, nil
+  }
+  return t.db.TimeTask.GetEntries(ctx, idTs, keys(allowed))
+}
+
+// GetTask задача по UUID, idP как у StartTask: если задан, задача должна принадлежать этому человеку
+func (t *taskBL) GetTask(ctx context.Context, idP, idT string) (*dto.Task, error) {
+  return t.checkTask(ctx, policy.TaskRead, idP, idT, 0)
+}
 
-  st, err := t.db.Task.GetTaskStatus(ctx, idT)
+func (t *taskBL) StartTask(ctx context.Context, idP, idT string, version int) error {
+  task, err := t.checkTask(ctx, policy.TaskTimer, idP, idT, version)
   if err != nil {
     return err
   }
+  return t.db.WithinTx(ctx, func(ctx context.Context) error {
+    st, err := t.db.Task.GetTaskStatus(ctx, idT)
+    if err != nil {
+      return err
+    }
+    if st == status.New || st == status.Pause {
+      err = t.db.TimeTask.StartTimer(ctx, idT)
+      if err != nil {
+        return err
+      }
+    } else {
+      err = fmt.Errorf("задача в статусе %s", st)
+      return err
+    }
 
-  if st != status.Work {
-    err = fmt.Errorf("задача в статусе %s", st)
+    err = t.updateStatus(ctx, idT, status.Work, version)
+    if err != nil {
+      return err
+    }
+    task.TaskStatus, task.Version = status.Work, task.Version+1
+    if err = t.publish(ctx, task, dto.EventTimerStarted, dto.EventTaskStatus); err != nil {
+      return err
+    }
+    err = t.enqueue(ctx, task, dto.WebhookTimerStarted, dto.WebhookTaskStarted)
     return err
-  }
+  })
+}
 
-  err = t.db.TimeTask.StopTimer(ctx, idT)
+func (t *taskBL) PauseTask(ctx context.Context, idP, idT string, version int) error {
+  task, err := t.checkTask(ctx, policy.TaskTimer, idP, idT, version)
   if err != nil {
     return err
   }
+  return t.db.WithinTx(ctx, func(ctx context.Context) error {
+    st, err := t.db.Task.GetTaskStatus(ctx, idT)
+    if err != nil {
+      return err
+    }
 
-  err = t.db.Task.UpdateStatus(ctx, idT, status.Complete)
+    if st != status.Work {
+      err = fmt.Errorf("задача в статусе %s", st)
+      return err
+    }
+
+    err = t.db.TimeTask.StopTimer(ctx, idT)
+    if err != nil {
+      return err
+    }
+
+    err = t.updateStatus(ctx, idT, status.Pause, version)
+    if err != nil {
+      return err
+    }
+
+    task.TaskStatus, task.Version = status.Pause, task.Version+1
+    if err = t.publish(ctx, task, dto.EventTimerStopped, dto.EventTaskStatus); err != nil {
+      return err
+    }
+    err = t.enqueue(ctx, task, dto.WebhookTimerStopped, dto.WebhookTaskPaused)
+    return err
+  })
+}
+
+func (t *taskBL) CompleteTask(ctx context.Context, idP, idT string, version int) error {
+  task, err := t.checkTask(ctx, policy.TaskTimer, idP, idT, version)
   if err != nil {
     return err
   }
+  return t.db.WithinTx(ctx, func(ctx context.Context) error {
+    st, err := t.db.Task.GetTaskStatus(ctx, idT)
+    if err != nil {
+      return err
+    }
 
-  return nil
+    if st != status.Work {
+      err = fmt.Errorf("задача в статусе %s", st)
+      return err
+    }
+
+    err = t.db.TimeTask.StopTimer(ctx, idT)
+    if err != nil {
+      return err
+    }
+
+    err = t.updateStatus(ctx, idT, status.Complete, version)
+    if err != nil {
+      return err
+    }
+
+    task.TaskStatus, task.Version = status.Complete, task.Version+1
+    if err = t.publish(ctx, task, dto.EventTimerStopped, dto.EventTaskStatus); err != nil {
+      return err
+    }
+    err = t.enqueue(ctx, task, dto.WebhookTimerStopped, dto.WebhookTaskCompleted)
+    return err
+  })
 }
 
 func (t *taskBL) TimeTasks(ctx context.Context, id, start, end string) ([]dto.TaskTimeResult, error) {
+  if err := t.guard.check(ctx, policy.TaskReport, id); err != nil {
+    return nil, err
+  }
   times, err := t.db.Task.TaskTimes(ctx, id, start, end)
   if err != nil {
     return nil, err
   }
   return times, nil
 }
+
+// AddEntry вносит вручную завершенный интервал работы над задачей
+func (t *taskBL) AddEntry(ctx context.Context, idT string, start, end time.Time) (*dto.TimeTask, error) {
+  if !end.After(start) {
+    return nil, fmt.Errorf("%w: конец интервала должен быть позже начала", ErrInvalid)
+  }
+  if end.After(time.Now()) {
+    return nil, fmt.Errorf("%w: интервал не может заканчиваться в будущем", ErrInvalid)
+  }
+  task, err := t.checkTask(ctx, policy.TaskTimer, "", idT, 0)
+  if err != nil {
+    return nil, err
+  }
+  var entry *dto.TimeTask
+  err = t.db.WithinTx(ctx, func(ctx context.Context) error {
+    // строка задачи блокируется, как при смене статуса: параллельные интервалы и запуск таймера
+    // проверяются на пересечение по очереди
+    st, err := t.db.Task.GetTaskStatus(ctx, idT)
+    if err != nil {
+      return err
+    }
+    entry, err = t.db.TimeTask.AddEntry(ctx, &dto.TimeTask{IDTask: idT, StartTime: start, EndTime: &end})
+    if err != nil {
+      return err
+    }
+    task.TaskStatus = st
+    if err = t.publish(ctx, task, dto.EventEntryAdded); err != nil {
+      return err
+    }
+    return t.enqueue(ctx, task, dto.WebhookEntryAdded)
+  })
+  if err != nil {
+    return nil, err
+  }
+  return entry, nil
+}
@@ -0,0 +1,414 @@
+package repo
+
+import (
+  "context"
+  "errors"
+  "io"
+  "log/slog"
+  "sync"
+  "sync/atomic"
+  "testing"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/events"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+  "timetracker/internal/db/memory"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+)
+
+// newTaskBL taskBL над пустым хранилищем в памяти и контекст администратора организации по умолчанию
+func newTaskBL(t *testing.T) (*taskBL, *db.Store, context.Context) {
+  t.Helper()
+  pol, err := policy.Load("")
+  if err != nil {
+    t.Fatal(err)
+  }
+  store := memory.New()
+  bl := NewTaskBL(store, NewGuard(store, pol), events.New(slog.New(slog.NewTextHandler(io.Discard, nil))))
+  ctx := utils.WithTenant(context.Background(), dto.DefaultOrganization)
+  ctx = utils.WithPrincipal(ctx, &dto.Principal{ID: "admin", Method: dto.AuthApiKey, Role: dto.RoleAdmin})
+  return bl.(*taskBL), store, ctx
+}
+
+func createPerson(t *testing.T, store *db.Store, ctx context.Context, passport string) string {
+  t.Helper()
+  id, err := store.People.CreatePerson(ctx, &dto.Person{People: dto.People{Surname: "Иванов", Name: "Иван"}, Passport: dto.Passport{PassportNumber: passport}})
+  if err != nil {
+    t.Fatal(err)
+  }
+  return id
+}
+
+func createTask(t *testing.T, bl *taskBL, ctx context.Context, person, name string) *dto.Task {
+  t.Helper()
+  task, err := bl.CreateTask(ctx, &dto.Task{IdPerson: person, TaskName: name})
+  if err != nil {
+    t.Fatal(err)
+  }
+  return task
+}
+
+func taskStatus(t *testing.T, store *db.Store, ctx context.Context, id string) (string, int) {
+  t.Helper()
+  task, err := store.Task.GetTask(ctx, id)
+  if err != nil {
+    t.Fatal(err)
+  }
+  return task.TaskStatus, task.Version
+}
+
+func TestTaskLifecycle(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+  if task.TaskStatus != status.New || task.Version != 1 {
+    t.Fatalf("новая задача: статус %s, версия %d", task.TaskStatus, task.Version)
+  }
+
+  steps := []struct {
+    name string
+    do   func(ctx context.Context, idP, idT string, version int) error
+    want string
+  }{
+    {"start", bl.StartTask, status.Work},
+    {"pause", bl.PauseTask, status.Pause},
+    {"start", bl.StartTask, status.Work},
+    {"complete", bl.CompleteTask, status.Complete},
+  }
+  for i, step := range steps {
+    if err := step.do(ctx, person, task.IdTask, i+1); err != nil {
+      t.Fatalf("%s: %v", step.name, err)
+    }
+    st, version := taskStatus(t, store, ctx, task.IdTask)
+    if st != step.want || version != i+2 {
+      t.Fatalf("%s: статус %s, версия %d, ожидался %s, %d", step.name, st, version, step.want, i+2)
+    }
+  }
+
+  entries, err := bl.GetEntries(ctx, []dto.Task{*task})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got := entries[task.IdTask]; len(got) != 2 || got[0].EndTime == nil || got[1].EndTime == nil {
+    t.Fatalf("ожидались два закрытых интервала, получено %+v", got)
+  }
+}
+
+func TestTaskWrongStatus(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+
+  if err := bl.PauseTask(ctx, person, task.IdTask, 0); err == nil {
+    t.Fatal("пауза новой задачи должна завершиться ошибкой")
+  }
+  if err := bl.CompleteTask(ctx, person, task.IdTask, 0); err == nil {
+    t.Fatal("завершение новой задачи должно завершиться ошибкой")
+  }
+  if err := bl.StartTask(ctx, person, task.IdTask, 0); err != nil {
+    t.Fatal(err)
+  }
+  if err := bl.StartTask(ctx, person, task.IdTask, 0); err == nil {
+    t.Fatal("повторный запуск должен завершиться ошибкой")
+  }
+  if st, version := taskStatus(t, store, ctx, task.IdTask); st != status.Work || version != 2 {
+    t.Fatalf("статус %s, версия %d после отклоненных переходов", st, version)
+  }
+}
+
+func TestTaskTransitions(t *testing.T) {
+  type action func(bl *taskBL) func(ctx context.Context, idP, idT string, version int) error
+  start := func(bl *taskBL) func(context.Context, string, string, int) error { return bl.StartTask }
+  pause := func(bl *taskBL) func(context.Context, string, string, int) error { return bl.PauseTask }
+  complete := func(bl *taskBL) func(context.Context, string, string, int) error { return bl.CompleteTask }
+  // paths действия, которыми задача приводится в исходный статус
+  paths := map[string][]action{
+    status.New:      nil,
+    status.Work:     {start},
+    status.Pause:    {start, pause},
+    status.Complete: {start, complete},
+  }
+  cases := []struct {
+    from   string
+    name   string
+    action action
+    want   string // пусто - переход запрещен
+  }{
+    {status.New, "start", start, status.Work},
+    {status.New, "pause", pause, ""},
+    {status.New, "complete", complete, ""},
+    {status.Work, "start", start, ""},
+    {status.Work, "pause", pause, status.Pause},
+    {status.Work, "complete", complete, status.Complete},
+    {status.Pause, "start", start, status.Work},
+    {status.Pause, "pause", pause, ""},
+    {status.Pause, "complete", complete, ""},
+    {status.Complete, "start", start, ""},
+    {status.Complete, "pause", pause, ""},
+    {status.Complete, "complete", complete, ""},
+  }
+  for _, c := range cases {
+    t.Run(c.from+"/"+c.name, func(t *testing.T) {
+      bl, store, ctx := newTaskBL(t)
+      person := createPerson(t, store, ctx, "1234 567890")
+      task := createTask(t, bl, ctx, person, "отчет")
+      for _, step := range paths[c.from] {
+        if err := step(bl)(ctx, person, task.IdTask, 0); err != nil {
+          t.Fatal(err)
+        }
+      }
+      _, version := taskStatus(t, store, ctx, task.IdTask)
+
+      err := c.action(bl)(ctx, person, task.IdTask, 0)
+      st, got := taskStatus(t, store, ctx, task.IdTask)
+      if c.want == "" {
+        if err == nil {
+          t.Fatalf("переход из %s разрешен", c.from)
+        }
+        if st != c.from || got != version {
+          t.Fatalf("после отклоненного перехода статус %s, версия %d", st, got)
+        }
+        return
+      }
+      if err != nil {
+        t.Fatal(err)
+      }
+      if st != c.want || got != version+1 {
+        t.Fatalf("статус %s, версия %d, ожидался %s, %d", st, got, c.want, version+1)
+      }
+    })
+  }
+}
+
+func TestTaskVersionMismatch(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+
+  if err := bl.StartTask(ctx, person, task.IdTask, 2); !errors.Is(err, ErrPreconditionFailed) {
+    t.Fatalf("ожидалась ErrPreconditionFailed, получено %v", err)
+  }
+  if st, _ := taskStatus(t, store, ctx, task.IdTask); st != status.New {
+    t.Fatalf("статус %s после отклоненного запуска", st)
+  }
+}
+
+// failingStatus репозиторий задач, в котором смена статуса всегда завершается ошибкой
+type failingStatus struct {
+  repo.ITaskRepo
+}
+
+func (f failingStatus) UpdateStatus(context.Context, string, string, int) error {
+  return errors.New("смена статуса недоступна")
+}
+
+func TestTaskRollback(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+
+  store.Task = failingStatus{store.Task}
+  if err := bl.StartTask(ctx, person, task.IdTask, 0); err == nil {
+    t.Fatal("ожидалась ошибка смены статуса")
+  }
+  // таймер запущен в той же транзакции и должен откатиться вместе с ней
+  entries, err := bl.GetEntries(ctx, []dto.Task{*task})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(entries[task.IdTask]) != 0 {
+    t.Fatalf("интервал не откатился: %+v", entries[task.IdTask])
+  }
+  if st, version := taskStatus(t, store, ctx, task.IdTask); st != status.New || version != 1 {
+    t.Fatalf("статус %s, версия %d после отката", st, version)
+  }
+}
+
+// failingCommit транзакции, фиксация которых завершается ошибкой после выполнения fn.
+// Проверяет только, что бизнес-логика возвращает ошибку фиксации, саму фиксацию хранилищ
+// проверяет repotest
+type failingCommit struct {
+  db.ITxRunner
+}
+
+var errCommit = errors.New("ошибка фиксации")
+
+func (f failingCommit) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+  if err := f.ITxRunner.WithinTx(ctx, fn); err != nil {
+    return err
+  }
+  return errCommit
+}
+
+func TestTaskCommitError(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+
+  store.ITxRunner = failingCommit{store.ITxRunner}
+  if err := bl.StartTask(ctx, person, task.IdTask, 0); !errors.Is(err, errCommit) {
+    t.Fatalf("ожидалась ошибка фиксации, получено %v", err)
+  }
+  if created, err := bl.CreateTask(ctx, &dto.Task{IdPerson: person, TaskName: "еще"}); !errors.Is(err, errCommit) || created != nil {
+    t.Fatalf("создание задачи: %+v, %v", created, err)
+  }
+}
+
+func TestTimeTasks(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  other := createPerson(t, store, ctx, "1234 567891")
+  short := createTask(t, bl, ctx, person, "короткая")
+  long := createTask(t, bl, ctx, person, "длинная")
+  foreign := createTask(t, bl, ctx, other, "чужая")
+
+  at := func(s string) time.Time {
+    res, err := time.Parse("2006-01-02 15:04:05", s)
+    if err != nil {
+      t.Fatal(err)
+    }
+    return res
+  }
+  entries := []struct {
+    task       string
+    start, end string
+  }{
+    {short.IdTask, "2026-01-10 09:00:00", "2026-01-10 10:30:00"},
+    {short.IdTask, "2026-01-10 11:00:00", "2026-01-10 12:00:00"},
+    // начинается до периода и учитывается с его начала
+    {long.IdTask, "2026-01-08 20:00:00", "2026-01-11 03:15:30"},
+    // целиком вне периода
+    {long.IdTask, "2026-01-01 09:00:00", "2026-01-01 10:00:00"},
+    {foreign.IdTask, "2026-01-10 09:00:00", "2026-01-10 18:00:00"},
+  }
+  for _, e := range entries {
+    if _, err := bl.AddEntry(ctx, e.task, at(e.start), at(e.end)); err != nil {
+      t.Fatal(err)
+    }
+  }
+  if _, err := bl.AddEntry(ctx, short.IdTask, at("2026-01-10 10:00:00"), at("2026-01-10 10:45:00")); err == nil {
+    t.Fatal("пересекающийся интервал должен быть отклонен")
+  }
+
+  res, err := bl.TimeTasks(ctx, person, "2026-01-09", "2026-01-12")
+  if err != nil {
+    t.Fatal(err)
+  }
+  want := []dto.TaskTimeResult{
+    {IDTask: long.IdTask, TaskName: "длинная", TotalTime: "2 days 03:15:30"},
+    {IDTask: short.IdTask, TaskName: "короткая", TotalTime: "02:30:00"},
+  }
+  if len(res) != len(want) {
+    t.Fatalf("получено %+v, ожидалось %+v", res, want)
+  }
+  for i := range want {
+    if res[i] != want[i] {
+      t.Fatalf("строка %d: получено %+v, ожидалось %+v", i, res[i], want[i])
+    }
+  }
+}
+
+func TestTaskForbidden(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  other := createPerson(t, store, ctx, "1234 567891")
+  own := createTask(t, bl, ctx, person, "своя")
+  foreign := createTask(t, bl, ctx, other, "чужая")
+
+  self := utils.WithPrincipal(ctx, &dto.Principal{ID: "self", PersonID: person, Method: dto.AuthJWT, Role: dto.RoleSelf})
+  if err := bl.StartTask(self, other, foreign.IdTask, 0); !errors.Is(err, ErrForbidden) {
+    t.Fatalf("ожидалась ErrForbidden, получено %v", err)
+  }
+  if _, err := bl.TimeTasks(self, other, "2026-01-01", "2026-01-31"); !errors.Is(err, ErrForbidden) {
+    t.Fatalf("ожидалась ErrForbidden для отчета, получено %v", err)
+  }
+  if err := bl.StartTask(self, person, own.IdTask, 0); err != nil {
+    t.Fatalf("запуск своей задачи: %v", err)
+  }
+}
+
+func TestGuardSetPolicy(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  createTask(t, bl, ctx, person, "отчет")
+
+  pol, err := policy.Parse([]byte(`{"task.read": {"admin": "any"}}`))
+  if err != nil {
+    t.Fatal(err)
+  }
+  bl.guard.SetPolicy(pol)
+  if _, err = bl.CreateTask(ctx, &dto.Task{IdPerson: person, TaskName: "еще"}); !errors.Is(err, ErrForbidden) {
+    t.Fatalf("ожидалась ErrForbidden по новой политике, получено %v", err)
+  }
+  if _, _, err = bl.GetTasks(ctx, person, dto.PageRequest{Limit: 10}); err != nil {
+    t.Fatal(err)
+  }
+}
+
+// recordingWebhooks outbox вебхуков, запоминающий типы событий
+type recordingWebhooks struct {
+  repo.IWebhookRepo
+  mu    sync.Mutex
+  types []string
+}
+
+func (r *recordingWebhooks) Enqueue(ctx context.Context, eventType string, data []byte) error {
+  r.mu.Lock()
+  r.types = append(r.types, eventType)
+  r.mu.Unlock()
+  return r.IWebhookRepo.Enqueue(ctx, eventType, data)
+}
+
+func TestAddEntryNotifies(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+  hooks := &recordingWebhooks{IWebhookRepo: store.Webhook}
+  store.Webhook = hooks
+  sub := bl.bus.Subscribe(func(dto.Event) bool { return true }, "")
+  defer sub.Close()
+
+  end := time.Now().Add(-time.Hour)
+  if _, err := bl.AddEntry(ctx, task.IdTask, end.Add(-time.Hour), end); err != nil {
+    t.Fatal(err)
+  }
+  select {
+  case e := <-sub.C:
+    if e.Type != dto.EventEntryAdded || e.TaskID != task.IdTask || e.Status != status.New {
+      t.Fatalf("неожиданное событие %+v", e)
+    }
+  case <-time.After(time.Second):
+    t.Fatal("событие о внесенном интервале не отправлено")
+  }
+  if len(hooks.types) != 1 || hooks.types[0] != dto.WebhookEntryAdded {
+    t.Fatalf("вебхуки %v, ожидался %s", hooks.types, dto.WebhookEntryAdded)
+  }
+}
+
+func TestAddEntryConcurrentOverlap(t *testing.T) {
+  bl, store, ctx := newTaskBL(t)
+  person := createPerson(t, store, ctx, "1234 567890")
+  task := createTask(t, bl, ctx, person, "отчет")
+
+  // интервалы попарно пересекаются, внести удается только один
+  end := time.Now().Add(-time.Hour)
+  var wg sync.WaitGroup
+  var added atomic.Int32
+  for i := 0; i < 8; i++ {
+    wg.Add(1)
+    go func(i int) {
+      defer wg.Done()
+      start := end.Add(-time.Hour - time.Duration(i)*time.Minute)
+      if _, err := bl.AddEntry(ctx, task.IdTask, start, end.Add(-time.Duration(i)*time.Minute)); err == nil {
+        added.Add(1)
+      }
+    }(i)
+  }
+  wg.Wait()
+  if added.Load() != 1 {
+    t.Fatalf("внесено %d пересекающихся интервалов", added.Load())
+  }
+}
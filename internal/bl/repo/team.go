@@ -0,0 +1,135 @@
+package repo
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "strings"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+)
+
+type ITeamBL interface {
+  CreateTeam(ctx context.Context, team dto.Team) (*dto.Team, error)
+  GetTeam(ctx context.Context, id string) (*dto.Team, error)
+  GetTeams(ctx context.Context) ([]dto.Team, error)
+  UpdateTeam(ctx context.Context, team dto.Team) (*dto.Team, error)
+  DeleteTeam(ctx context.Context, id string) error
+  AddMember(ctx context.Context, teamID, personID string) error
+  RemoveMember(ctx context.Context, teamID, personID string) error
+  TeamTimes(ctx context.Context, teamID, start, end string) ([]dto.TeamTimeResult, error)
+}
+
+type teamBL struct {
+  db    *db.Store
+  guard *Guard
+}
+
+func NewTeamBL(db *db.Store, guard *Guard) ITeamBL {
+  return &teamBL{db: db, guard: guard}
+}
+
+func (t *teamBL) CreateTeam(ctx context.Context, team dto.Team) (*dto.Team, error) {
+  if err := t.guard.check(ctx, policy.TeamManage, ""); err != nil {
+    return nil, err
+  }
+  team.Name = strings.TrimSpace(team.Name)
+  if team.Name == "" {
+    return nil, errors.New("не заполнено название команды")
+  }
+  if team.ManagerID != "" {
+    if _, err := t.db.People.GetByUUID(ctx, team.ManagerID); err != nil {
+      return nil, err
+    }
+  }
+  return t.db.Team.CreateTeam(ctx, &team)
+}
+
+func (t *teamBL) GetTeam(ctx context.Context, id string) (*dto.Team, error) {
+  team, err := t.db.Team.GetTeam(ctx, id)
+  if err != nil {
+    return nil, err
+  }
+  if err = t.guard.checkTeam(ctx, policy.TeamRead, team); err != nil {
+    return nil, err
+  }
+  return team, nil
+}
+
+// GetTeams возвращает команды, руководителю в области team только его собственные
+func (t *teamBL) GetTeams(ctx context.Context) ([]dto.Team, error) {
+  principal, scope, err := t.guard.scope(ctx, policy.TeamRead)
+  if err != nil {
+    return nil, err
+  }
+  switch scope {
+  case policy.ScopeAny:
+    return t.db.Team.GetTeams(ctx, "")
+  case policy.ScopeTeam:
+    if principal.PersonID != "" {
+      return t.db.Team.GetTeams(ctx, principal.PersonID)
+    }
+  }
+  return nil, fmt.Errorf("%w: %s", ErrForbidden, policy.TeamRead)
+}
+
+func (t *teamBL) UpdateTeam(ctx context.Context, team dto.Team) (*dto.Team, error) {
+  if err := t.guard.check(ctx, policy.TeamManage, ""); err != nil {
+    return nil, err
+  }
+  oldTeam, err := t.db.Team.GetTeam(ctx, team.ID)
+  if err != nil {
+    return nil, err
+  }
+  oldTeam.Name = UpdateField(strings.TrimSpace(team.Name), oldTeam.Name)
+  if team.ManagerID != "" && team.ManagerID != oldTeam.ManagerID {
+    if _, err = t.db.People.GetByUUID(ctx, team.ManagerID); err != nil {
+      return nil, err
+    }
+    oldTeam.ManagerID = team.ManagerID
+  }
+  return t.db.Team.UpdateTeam(ctx, oldTeam)
+}
+
+func (t *teamBL) DeleteTeam(ctx context.Context, id string) error {
+  if err := t.guard.check(ctx, policy.TeamManage, ""); err != nil {
+    return err
+  }
+  return t.db.Team.DeleteTeam(ctx, id)
+}
+
+func (t *teamBL) AddMember(ctx context.Context, teamID, personID string) error {
+  if err := t.guard.check(ctx, policy.TeamManage, ""); err != nil {
+    return err
+  }
+  if _, err := t.db.Team.GetTeam(ctx, teamID); err != nil {
+    return err
+  }
+  if _, err := t.db.People.GetByUUID(ctx, personID); err != nil {
+    return err
+  }
+  return t.db.Team.AddMember(ctx, teamID, personID)
+}
+
+func (t *teamBL) RemoveMember(ctx context.Context, teamID, personID string) error {
+  if err := t.guard.check(ctx, policy.TeamManage, ""); err != nil {
+    return err
+  }
+  if _, err := t.db.Team.GetTeam(ctx, teamID); err != nil {
+    return err
+  }
+  return t.db.Team.RemoveMember(ctx, teamID, personID)
+}
+
+// TeamTimes отчет о трудозатратах всей команды, доступен по действию task.report
+func (t *teamBL) TeamTimes(ctx context.Context, teamID, start, end string) ([]dto.TeamTimeResult, error) {
+  team, err := t.db.Team.GetTeam(ctx, teamID)
+  if err != nil {
+    return nil, err
+  }
+  if err = t.guard.checkTeam(ctx, policy.TaskReport, team); err != nil {
+    return nil, err
+  }
+  return t.db.Task.TeamTimes(ctx, teamID, start, end)
+}
@@ -0,0 +1,110 @@
+package repo
+
+import (
+  "context"
+  "crypto/rand"
+  "encoding/hex"
+  "encoding/json"
+  "fmt"
+  "net/url"
+  "slices"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/db"
+)
+
+// maxDeliveries наибольшее число доставок в ответе журнала
+const maxDeliveries = 200
+
+type IWebhookBL interface {
+  CreateWebhook(ctx context.Context, hook dto.Webhook) (*dto.Webhook, error)
+  GetWebhooks(ctx context.Context) ([]dto.Webhook, error)
+  DeleteWebhook(ctx context.Context, id string) error
+  GetDeliveries(ctx context.Context, id, status string, limit int) ([]dto.WebhookDelivery, error)
+}
+
+type webhookBL struct {
+  db    *db.Store
+  guard *Guard
+}
+
+func NewWebhookBL(db *db.Store, guard *Guard) IWebhookBL {
+  return &webhookBL{db: db, guard: guard}
+}
+
+// CreateWebhook регистрирует подписку, без секрета он генерируется.
+// Секрет возвращается только в этом ответе
+func (w *webhookBL) CreateWebhook(ctx context.Context, hook dto.Webhook) (*dto.Webhook, error) {
+  if err := w.guard.check(ctx, policy.WebhookManage, ""); err != nil {
+    return nil, err
+  }
+  u, err := url.Parse(hook.URL)
+  if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+    return nil, fmt.Errorf("%w: url должен быть абсолютным адресом http или https", ErrInvalid)
+  }
+  if len(hook.EventTypes) == 0 {
+    return nil, fmt.Errorf("%w: не заданы типы событий", ErrInvalid)
+  }
+  slices.Sort(hook.EventTypes)
+  hook.EventTypes = slices.Compact(hook.EventTypes)
+  for _, event := range hook.EventTypes {
+    if !dto.IsValidWebhookEvent(event) {
+      return nil, fmt.Errorf("%w: неизвестный тип события %s", ErrInvalid, event)
+    }
+  }
+  if hook.Secret == "" {
+    buf := make([]byte, 32)
+    if _, err := rand.Read(buf); err != nil {
+      return nil, fmt.Errorf("ошибка генерации секрета: %v", err)
+    }
+    hook.Secret = hex.EncodeToString(buf)
+  } else if len(hook.Secret) < 16 {
+    return nil, fmt.Errorf("%w: секрет короче 16 символов", ErrInvalid)
+  }
+
+  created, err := w.db.Webhook.CreateWebhook(ctx, &hook)
+  if err != nil {
+    return nil, err
+  }
+  created.Secret = hook.Secret
+  return created, nil
+}
+
+func (w *webhookBL) GetWebhooks(ctx context.Context) ([]dto.Webhook, error) {
+  if err := w.guard.check(ctx, policy.WebhookManage, ""); err != nil {
+    return nil, err
+  }
+  return w.db.Webhook.GetWebhooks(ctx)
+}
+
+func (w *webhookBL) DeleteWebhook(ctx context.Context, id string) error {
+  if err := w.guard.check(ctx, policy.WebhookManage, ""); err != nil {
+    return err
+  }
+  return w.db.Webhook.DeleteWebhook(ctx, id)
+}
+
+// GetDeliveries журнал доставок подписки, новые сначала
+func (w *webhookBL) GetDeliveries(ctx context.Context, id, status string, limit int) ([]dto.WebhookDelivery, error) {
+  if err := w.guard.check(ctx, policy.WebhookManage, ""); err != nil {
+    return nil, err
+  }
+  switch status {
+  case "", dto.DeliveryPending, dto.DeliveryDelivered, dto.DeliveryFailed:
+  default:
+    return nil, fmt.Errorf("%w: неизвестный статус доставки %s", ErrInvalid, status)
+  }
+  if limit <= 0 || limit > maxDeliveries {
+    limit = maxDeliveries
+  }
+  return w.db.Webhook.GetDeliveries(ctx, id, status, limit)
+}
+
+// enqueue пишет событие вебхука в outbox в транзакции изменения из ctx
+func enqueue(ctx context.Context, d *db.Store, eventType string, data interface{}) error {
+  payload, err := json.Marshal(data)
+  if err != nil {
+    return fmt.Errorf("ошибка сериализации события %s: %v", eventType, err)
+  }
+  return d.Webhook.Enqueue(ctx, eventType, payload)
+}
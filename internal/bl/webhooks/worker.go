@@ -0,0 +1,165 @@
+package webhooks
+
+import (
+  "bytes"
+  "context"
+  "crypto/hmac"
+  "crypto/sha256"
+  "encoding/hex"
+  "encoding/json"
+  "fmt"
+  "io"
+  "log/slog"
+  "math/rand"
+  "net/http"
+  "strconv"
+  "sync"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+)
+
+const (
+  // batchSize сколько доставок воркер берет за один проход
+  batchSize = 50
+  // lease время, на которое взятая доставка скрыта от других экземпляров
+  lease = time.Minute
+  // maxAttempts после стольких неудачных попыток доставка считается неуспешной
+  maxAttempts = 10
+  // retention сколько хранятся события и журнал доставок
+  retention = 30 * 24 * time.Hour
+  poll      = time.Second
+)
+
+// Worker отправляет события из outbox подписчикам. Экземпляры сервиса могут работать
+// одновременно: доставка берется с блокировкой и не отправляется дважды за один lease
+type Worker struct {
+  db     *db.Store
+  client *http.Client
+  l      *slog.Logger
+}
+
+func NewWorker(db *db.Store, log *slog.Logger) *Worker {
+  return &Worker{
+    db: db,
+    client: &http.Client{
+      Timeout: 10 * time.Second,
+      // перенаправление считается неудачной попыткой, адрес подписки нужно исправить
+      CheckRedirect: func(*http.Request, []*http.Request) error {
+        return http.ErrUseLastResponse
+      },
+    },
+    l: log.With(slog.String("layer", "webhooks")),
+  }
+}
+
+// Run обрабатывает доставки до отмены ctx
+func (w *Worker) Run(ctx context.Context) {
+  ticker := time.NewTicker(poll)
+  defer ticker.Stop()
+  lastCleanup := time.Time{}
+  for {
+    select {
+    case <-ctx.Done():
+      return
+    case <-ticker.C:
+    }
+    if time.Since(lastCleanup) > time.Hour {
+      lastCleanup = time.Now()
+      if n, err := w.db.Webhook.Cleanup(ctx, retention); err != nil {
+        w.l.Error("ошибка очистки журнала вебхуков", slog.String("error", err.Error()))
+      } else if n > 0 {
+        w.l.Info("удалены старые события вебхуков", slog.Int64("count", n))
+      }
+    }
+    // пока есть готовые доставки, следующий проход без ожидания
+    for ctx.Err() == nil {
+      claimed, err := w.db.Webhook.Claim(ctx, batchSize, lease)
+      if err != nil {
+        w.l.Error("ошибка выборки доставок", slog.String("error", err.Error()))
+        break
+      }
+      var wg sync.WaitGroup
+      for _, out := range claimed {
+        wg.Add(1)
+        go func(out dto.OutgoingWebhook) {
+          defer wg.Done()
+          w.deliver(ctx, out)
+        }(out)
+      }
+      wg.Wait()
+      if len(claimed) < batchSize {
+        break
+      }
+    }
+  }
+}
+
+func (w *Worker) deliver(ctx context.Context, out dto.OutgoingWebhook) {
+  log := w.l.With(slog.Int64("delivery", out.DeliveryID), slog.String("event", out.Event.Type))
+  status, err := w.send(ctx, out)
+  switch {
+  case err == nil:
+    err = w.db.Webhook.Delivered(ctx, out.DeliveryID, *status)
+  case out.Attempts >= maxAttempts:
+    log.Info("доставка вебхука не удалась", slog.Int("attempts", out.Attempts), slog.String("error", err.Error()))
+    err = w.db.Webhook.Fail(ctx, out.DeliveryID, status, err.Error())
+  default:
+    err = w.db.Webhook.Retry(ctx, out.DeliveryID, status, err.Error(), backoff(out.Attempts))
+  }
+  if err != nil {
+    log.Error("ошибка сохранения итога доставки", slog.String("error", err.Error()))
+  }
+}
+
+// send отправляет событие, ошибка означает, что нужна повторная попытка
+func (w *Worker) send(ctx context.Context, out dto.OutgoingWebhook) (*int, error) {
+  body, err := json.Marshal(out.Event)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка сериализации события: %v", err)
+  }
+  timestamp := time.Now().Unix()
+  req, err := http.NewRequestWithContext(ctx, http.MethodPost, out.URL, bytes.NewReader(body))
+  if err != nil {
+    return nil, fmt.Errorf("ошибка создания запроса: %v", err)
+  }
+  req.Header.Set("Content-Type", "application/json")
+  req.Header.Set("User-Agent", "timetracker-webhooks")
+  req.Header.Set("X-Webhook-Id", out.Event.ID)
+  req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(out.DeliveryID, 10))
+  req.Header.Set("X-Webhook-Event", out.Event.Type)
+  req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+  req.Header.Set("X-Webhook-Signature", Sign(out.Secret, timestamp, body))
+
+  resp, err := w.client.Do(req)
+  if err != nil {
+    return nil, err
+  }
+  defer resp.Body.Close()
+  status := resp.StatusCode
+  if status >= 200 && status < 300 {
+    _, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+    return &status, nil
+  }
+  snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+  return &status, fmt.Errorf("ответ %d: %s", status, bytes.TrimSpace(snippet))
+}
+
+// Sign подпись тела запроса: sha256=hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+// Получатель сверяет ее и отбрасывает запросы со старым X-Webhook-Timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+  mac := hmac.New(sha256.New, []byte(secret))
+  mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+  mac.Write(body)
+  return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff пауза перед следующей попыткой: 30s, 1m, 2m, ... не больше 6 часов, с разбросом ±20%
+func backoff(attempt int) time.Duration {
+  delay := 6 * time.Hour
+  if attempt < 16 {
+    delay = min(30*time.Second<<(attempt-1), delay)
+  }
+  jitter := time.Duration(rand.Int63n(int64(delay)*2/5)) - delay/5
+  return delay + jitter
+}
@@ -2,40 +2,361 @@ package config
 
 import (
   "fmt"
+  "github.com/BurntSushi/toml"
   "github.com/jessevdk/go-flags"
+  "gopkg.in/yaml.v3"
+  "io"
+  "log/slog"
+  "net/url"
+  "os"
+  "path/filepath"
+  "reflect"
+  "slices"
+  "strconv"
+  "strings"
+  "time"
+  "timetracker/internal/bl/policy"
+  "timetracker/internal/config/logger"
 )
 
-type OptionsSrv struct {
-  Host   string `short:"h" long:"host" description:"хост" default:"localhost" env:"HOST"`
-  Port   string `short:"p" long:"port" description:"порт" default:"3000" env:"PORT"`
-  Log    string `long:"logger-create" description:"logger-create output" default:"debug" env:"LOG"`
+// Хранилища сервера, выбираются схемой --dsn
+const (
+  StoragePostgres = "postgres"
+  StorageSQLite   = "sqlite"
+  StorageMemory   = "memory"
+)
+
+// OptionsDb подключение к базе, общее для сервера и ttadmin
+type OptionsDb struct {
   DbHost string `long:"dbhost" description:"the db server host" default:"localhost" env:"DB_HOST"`
   DbPort string `long:"dbport" description:"the db server port" default:"5432" env:"DB_PORT"`
   PgUser string `long:"pguser" description:"the db user" default:"user_postgres" env:"POSTGRES_USER"`
-  PgPass string `long:"pgpass" description:"the db pass" default:"pass" env:"POSTGRES_PASSWORD"`
+  PgPass string `long:"pgpass" description:"the db pass" default:"pass" env:"POSTGRES_PASSWORD" secret:"true"`
   DbName string `long:"dbname" description:"the db name" default:"test" env:"POSTGRES_DB"`
 }
 
+// OptionsSrv настройки сервера. Ключи файла --config совпадают с длинными именами флагов,
+// секреты помечены тегом secret и скрываются в --print-config
+type OptionsSrv struct {
+  Config      string `short:"c" long:"config" description:"файл настроек yaml или toml, его значения перекрываются переменными окружения и флагами" env:"CONFIG"`
+  PrintConfig bool   `long:"print-config" description:"вывести итоговые настройки без секретов и выйти"`
+
+  Host     string `short:"h" long:"host" description:"хост" default:"localhost" env:"HOST"`
+  Port     string `short:"p" long:"port" description:"порт" default:"3000" env:"PORT"`
+  GrpcPort string `long:"grpc-port" description:"порт gRPC" default:"3001" env:"GRPC_PORT"`
+  Log      string `long:"logger-create" description:"уровень логирования: debug, info, warn или error" default:"debug" env:"LOG"`
+  Dsn      string `long:"dsn" description:"хранилище: postgres://..., sqlite:<файл> или memory: (в памяти, данные теряются при остановке), по умолчанию postgres из --db*" env:"DATABASE_URL" secret:"url"`
+  OptionsDb
+
+  DbMaxOpen      int           `long:"db-max-open" description:"предел открытых соединений с postgres, 0 - без предела" default:"10" env:"DB_MAX_OPEN"`
+  DbMaxIdle      int           `long:"db-max-idle" description:"предел простаивающих соединений с postgres, 0 - не держать" default:"5" env:"DB_MAX_IDLE"`
+  DbConnLifetime time.Duration `long:"db-conn-lifetime" description:"срок жизни соединения с postgres, 0 - без срока" default:"30m" env:"DB_CONN_LIFETIME"`
+
+  ReadTimeout     time.Duration `long:"read-timeout" description:"срок чтения HTTP-запроса вместе с телом, 0 - без срока" default:"30s" env:"READ_TIMEOUT"`
+  IdleTimeout     time.Duration `long:"idle-timeout" description:"срок простоя keep-alive соединения HTTP, 0 - как read-timeout" default:"2m" env:"IDLE_TIMEOUT"`
+  ShutdownTimeout time.Duration `long:"shutdown-timeout" description:"срок завершения текущих запросов при остановке" default:"15s" env:"SHUTDOWN_TIMEOUT"`
+
+  JwtKeys      string `long:"jwt-keys" description:"каталог с ключами проверки JWT (<kid>.secret, <kid>.pem)" env:"JWT_KEYS"`
+  JwtIssuer    string `long:"jwt-issuer" description:"ожидаемый издатель JWT" env:"JWT_ISSUER"`
+  JwtAudience  string `long:"jwt-audience" description:"ожидаемая аудитория JWT" env:"JWT_AUDIENCE"`
+  BootstrapKey string `long:"bootstrap-key" description:"API-ключ администратора, создаваемый при старте" env:"BOOTSTRAP_API_KEY" secret:"true"`
+  Policy       string `long:"policy" description:"json файл с таблицей прав доступа, по умолчанию встроенная" env:"POLICY"`
+
+  PublicURL      string `long:"public-url" description:"внешний адрес сервиса для ссылок в ответах, по умолчанию из запроса" env:"PUBLIC_URL"`
+  TrustedProxies string `long:"trusted-proxies" description:"адреса и подсети прокси через запятую, которым разрешено задавать X-Forwarded-Proto/Host" env:"TRUSTED_PROXIES"`
+
+  NoGrpc     bool `long:"no-grpc" description:"не запускать gRPC сервер" env:"NO_GRPC"`
+  NoWebhooks bool `long:"no-webhooks" description:"не отправлять вебхуки из этого экземпляра, очередь остается в хранилище" env:"NO_WEBHOOKS"`
+}
+
+// Reloadable параметры, которые сервер применяет по SIGHUP без перезапуска
+var Reloadable = []string{"logger-create", "policy"}
+
+// notInFile флаги, которые не задаются файлом настроек и не выводятся --print-config
+var notInFile = []string{"help", "config", "print-config"}
+
 type ConfSrv struct {
   Options OptionsSrv
+  // Policy таблица доступа из --policy, проверенная при загрузке
+  Policy policy.Policy
+
+  args    []string
+  options []*flags.Option
 }
 
 func InitConfServ() (*ConfSrv, error) {
-  var conf ConfSrv
+  return Load(os.Args[1:])
+}
+
+// Load читает настройки сервера по возрастанию приоритета: значения по умолчанию, файл --config,
+// переменные окружения, флаги args. Настройки с ошибками не возвращаются
+func Load(args []string) (*ConfSrv, error) {
   var opts OptionsSrv
   parser := flags.NewParser(&opts, flags.Default)
-  _, err := parser.Parse()
+  if _, err := parser.ParseArgs(args); err != nil {
+    return nil, err
+  }
+  options := fileOptions(parser)
+  if opts.Config != "" {
+    if err := applyFile(opts.Config, options); err != nil {
+      return nil, err
+    }
+  }
+  if err := opts.Validate(); err != nil {
+    return nil, err
+  }
+  pol, err := policy.Load(opts.Policy)
   if err != nil {
     return nil, err
   }
-  conf.Options = opts
-  return &conf, nil
+  return &ConfSrv{Options: opts, Policy: pol, args: args, options: options}, nil
+}
+
+// Reload перечитывает настройки с теми же флагами, файл и переменные окружения могли измениться
+func (c *ConfSrv) Reload() (*ConfSrv, error) {
+  return Load(c.args)
+}
+
+// Level уровень логирования, проверенный при загрузке
+func (c *ConfSrv) Level() slog.Level {
+  level, _ := logger.ParseLevel(c.Options.Log)
+  return level
+}
+
+// Changed ключи параметров, значения которых в next отличаются от текущих
+func (c *ConfSrv) Changed(next *ConfSrv) []string {
+  prev := make(map[string]interface{}, len(c.options))
+  for _, o := range c.options {
+    prev[o.LongName] = o.Value()
+  }
+  var res []string
+  for _, o := range next.options {
+    if !reflect.DeepEqual(prev[o.LongName], o.Value()) {
+      res = append(res, o.LongName)
+    }
+  }
+  return res
 }
 
-func (o *OptionsSrv) DbString() string {
+// Print выводит итоговые настройки в yaml, пригодном для --config. Секреты скрыты
+func (c *ConfSrv) Print(w io.Writer) error {
+  doc := &yaml.Node{Kind: yaml.MappingNode}
+  for _, o := range c.options {
+    var key, value yaml.Node
+    key.SetString(o.LongName)
+    if err := value.Encode(printable(o)); err != nil {
+      return err
+    }
+    doc.Content = append(doc.Content, &key, &value)
+  }
+  enc := yaml.NewEncoder(w)
+  enc.SetIndent(2)
+  if err := enc.Encode(doc); err != nil {
+    return err
+  }
+  return enc.Close()
+}
+
+// printable значение параметра для вывода: сроки строкой, как во флагах, секреты скрыты
+func printable(o *flags.Option) interface{} {
+  value := o.Value()
+  s, _ := value.(string)
+  switch o.Field().Tag.Get("secret") {
+  case "true":
+    if s != "" {
+      return "******"
+    }
+  case "url":
+    if u, err := url.Parse(s); err == nil && u.User != nil {
+      return u.Redacted()
+    }
+    // строка подключения вида "host=... password=..."
+    if strings.Contains(s, "password=") {
+      return "******"
+    }
+  }
+  if d, ok := value.(time.Duration); ok {
+    return d.String()
+  }
+  return value
+}
+
+// fileOptions флаги парсера, которые можно задать файлом настроек, в порядке объявления
+func fileOptions(parser *flags.Parser) []*flags.Option {
+  var res []*flags.Option
+  var walk func(g *flags.Group)
+  walk = func(g *flags.Group) {
+    for _, o := range g.Options() {
+      if o.LongName != "" && !slices.Contains(notInFile, o.LongName) {
+        res = append(res, o)
+      }
+    }
+    for _, sub := range g.Groups() {
+      walk(sub)
+    }
+  }
+  walk(parser.Group)
+  return res
+}
+
+// applyFile задает флаги значениями из файла, кроме заданных в командной строке или переменной окружения
+func applyFile(path string, options []*flags.Option) error {
+  values, err := readFile(path)
+  if err != nil {
+    return err
+  }
+  known := make(map[string]bool, len(options))
+  for _, o := range options {
+    known[o.LongName] = true
+    raw, ok := values[o.LongName]
+    if !ok || explicit(o) {
+      continue
+    }
+    value, err := fileValue(raw)
+    if err == nil {
+      err = o.Set(&value)
+    }
+    if err != nil {
+      return fmt.Errorf("параметр %s в файле %s: %v", o.LongName, path, err)
+    }
+  }
+  var unknown []string
+  for key := range values {
+    if !known[key] {
+      unknown = append(unknown, key)
+    }
+  }
+  if len(unknown) > 0 {
+    slices.Sort(unknown)
+    return fmt.Errorf("неизвестные параметры в файле %s: %s", path, strings.Join(unknown, ", "))
+  }
+  return nil
+}
+
+// explicit флаг задан в командной строке или переменной окружения и важнее файла
+func explicit(o *flags.Option) bool {
+  if o.IsSet() && !o.IsSetDefault() {
+    return true
+  }
+  if key := o.EnvKeyWithNamespace(); key != "" {
+    _, ok := os.LookupEnv(key)
+    return ok
+  }
+  return false
+}
+
+// readFile разбирает файл настроек, формат определяется расширением
+func readFile(path string) (map[string]interface{}, error) {
+  data, err := os.ReadFile(path)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка чтения файла настроек: %v", err)
+  }
+  values := map[string]interface{}{}
+  switch strings.ToLower(filepath.Ext(path)) {
+  case ".yaml", ".yml":
+    err = yaml.Unmarshal(data, &values)
+  case ".toml":
+    err = toml.Unmarshal(data, &values)
+  default:
+    return nil, fmt.Errorf("неизвестный формат файла настроек %s, ожидается .yaml, .yml или .toml", path)
+  }
+  if err != nil {
+    return nil, fmt.Errorf("ошибка разбора файла настроек %s: %v", path, err)
+  }
+  return values, nil
+}
+
+// fileValue значение из файла строкой, как его задали бы флагом. Список объединяется через запятую
+func fileValue(raw interface{}) (string, error) {
+  switch v := raw.(type) {
+  case string:
+    return v, nil
+  case bool, int, int64, uint64, float64:
+    return fmt.Sprint(v), nil
+  case []interface{}:
+    items := make([]string, 0, len(v))
+    for _, item := range v {
+      s, err := fileValue(item)
+      if err != nil {
+        return "", err
+      }
+      items = append(items, s)
+    }
+    return strings.Join(items, ","), nil
+  }
+  return "", fmt.Errorf("неподдерживаемое значение %v", raw)
+}
+
+// Validate проверяет настройки целиком и сообщает обо всех ошибках сразу
+func (o *OptionsSrv) Validate() error {
+  var invalid []string
+  ports := []struct{ key, value string }{{"port", o.Port}, {"grpc-port", o.GrpcPort}, {"dbport", o.DbPort}}
+  for _, p := range ports {
+    if n, err := strconv.Atoi(p.value); err != nil || n < 1 || n > 65535 {
+      invalid = append(invalid, fmt.Sprintf("%s: порт %s вне диапазона 1-65535", p.key, p.value))
+    }
+  }
+  if !o.NoGrpc && o.Port == o.GrpcPort {
+    invalid = append(invalid, fmt.Sprintf("port и grpc-port совпадают: %s", o.Port))
+  }
+  if _, err := logger.ParseLevel(o.Log); err != nil {
+    invalid = append(invalid, "logger-create: "+err.Error())
+  }
+  if o.DbMaxOpen < 0 || o.DbMaxIdle < 0 {
+    invalid = append(invalid, "db-max-open и db-max-idle не могут быть отрицательными")
+  } else if o.DbMaxOpen > 0 && o.DbMaxIdle > o.DbMaxOpen {
+    invalid = append(invalid, fmt.Sprintf("db-max-idle %d больше db-max-open %d", o.DbMaxIdle, o.DbMaxOpen))
+  }
+  durations := []struct {
+    key   string
+    value time.Duration
+  }{{"db-conn-lifetime", o.DbConnLifetime}, {"read-timeout", o.ReadTimeout}, {"idle-timeout", o.IdleTimeout}}
+  for _, d := range durations {
+    if d.value < 0 {
+      invalid = append(invalid, fmt.Sprintf("%s: отрицательный срок %s", d.key, d.value))
+    }
+  }
+  if o.ShutdownTimeout <= 0 {
+    invalid = append(invalid, fmt.Sprintf("shutdown-timeout: срок должен быть положительным, задан %s", o.ShutdownTimeout))
+  }
+  if _, _, err := o.Storage(); err != nil {
+    invalid = append(invalid, "dsn: "+err.Error())
+  }
+  if len(invalid) > 0 {
+    return fmt.Errorf("некорректные настройки: %s", strings.Join(invalid, "; "))
+  }
+  return nil
+}
+
+func (o *OptionsDb) DbString() string {
   return fmt.Sprintf("postgresql://%s:%s@%s:%s/%s?sslmode=disable", o.PgUser, o.PgPass, o.DbHost, o.DbPort, o.DbName)
 }
 
+// Storage разбирает --dsn: хранилище и строку подключения к нему (путь к файлу для sqlite).
+// Пустой --dsn означает postgres с параметрами --db*
+func (o *OptionsSrv) Storage() (string, string, error) {
+  scheme, rest, _ := strings.Cut(o.Dsn, ":")
+  switch {
+  case o.Dsn == "":
+    return StoragePostgres, o.DbString(), nil
+  case scheme == "postgres" || scheme == "postgresql":
+    return StoragePostgres, o.Dsn, nil
+  case scheme == "memory":
+    return StorageMemory, "", nil
+  case scheme == "sqlite" || scheme == "sqlite3":
+    path := strings.TrimPrefix(rest, "//")
+    if path == "" {
+      return "", "", fmt.Errorf("в dsn %s не указан файл базы sqlite", o.Dsn)
+    }
+    return StorageSQLite, path, nil
+  }
+  return "", "", fmt.Errorf("неизвестная схема dsn %s, ожидается postgres, sqlite или memory", o.Dsn)
+}
+
 func (o *OptionsSrv) ServStr() string {
   return fmt.Sprintf("%s:%s", o.Host, o.Port)
 }
+
+func (o *OptionsSrv) GrpcStr() string {
+  return fmt.Sprintf("%s:%s", o.Host, o.GrpcPort)
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+  "bytes"
+  "log/slog"
+  "os"
+  "path/filepath"
+  "slices"
+  "strings"
+  "testing"
+  "time"
+)
+
+// writeFile кладет файл настроек name во временный каталог теста
+func writeFile(t *testing.T, name, content string) string {
+  t.Helper()
+  path := filepath.Join(t.TempDir(), name)
+  if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+    t.Fatal(err)
+  }
+  return path
+}
+
+func TestLoadLayers(t *testing.T) {
+  path := writeFile(t, "server.yaml", `
+port: 8080
+grpc-port: 8081
+logger-create: info
+read-timeout: 10s
+db-max-open: 20
+no-webhooks: true
+trusted-proxies: [10.0.0.1, 10.0.0.0/8]
+`)
+  t.Setenv("LOG", "warn")
+  t.Setenv("GRPC_PORT", "9091")
+
+  conf, err := Load([]string{"--config", path, "--grpc-port", "7071"})
+  if err != nil {
+    t.Fatal(err)
+  }
+  o := conf.Options
+  // файл перекрывает значения по умолчанию
+  if o.Port != "8080" || o.ReadTimeout != 10*time.Second || o.DbMaxOpen != 20 || !o.NoWebhooks {
+    t.Fatalf("значения файла не применены: %+v", o)
+  }
+  if o.TrustedProxies != "10.0.0.1,10.0.0.0/8" {
+    t.Fatalf("список из файла: %s", o.TrustedProxies)
+  }
+  // переменная окружения перекрывает файл, флаг перекрывает переменную
+  if o.Log != "warn" || conf.Level() != slog.LevelWarn {
+    t.Fatalf("уровень логирования %s", o.Log)
+  }
+  if o.GrpcPort != "7071" {
+    t.Fatalf("grpc-port %s", o.GrpcPort)
+  }
+  // не заданное нигде остается по умолчанию
+  if o.Host != "localhost" || o.ShutdownTimeout != 15*time.Second || conf.Policy == nil {
+    t.Fatalf("значения по умолчанию: %+v", o)
+  }
+}
+
+func TestLoadToml(t *testing.T) {
+  path := writeFile(t, "server.toml", "port = 8080\nshutdown-timeout = \"5s\"\n")
+  conf, err := Load([]string{"-c", path})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if conf.Options.Port != "8080" || conf.Options.ShutdownTimeout != 5*time.Second {
+    t.Fatalf("значения toml не применены: %+v", conf.Options)
+  }
+}
+
+func TestLoadFileErrors(t *testing.T) {
+  cases := map[string]struct {
+    name, content, want string
+  }{
+    "неизвестный ключ": {"server.yaml", "port: 8080\nprot: 1\n", "prot"},
+    "формат":           {"server.json", "{}", "формат"},
+    "тип значения":     {"server.yaml", "port: {a: 1}\n", "port"},
+    "конфиг в конфиге": {"server.yaml", "config: other.yaml\n", "config"},
+  }
+  for name, c := range cases {
+    t.Run(name, func(t *testing.T) {
+      _, err := Load([]string{"--config", writeFile(t, c.name, c.content)})
+      if err == nil || !strings.Contains(err.Error(), c.want) {
+        t.Fatalf("ожидалась ошибка про %s, получено %v", c.want, err)
+      }
+    })
+  }
+}
+
+func TestValidate(t *testing.T) {
+  path := writeFile(t, "server.yaml", `
+port: 70000
+grpc-port: 3000
+logger-create: loud
+db-max-open: 2
+db-max-idle: 5
+shutdown-timeout: 0s
+dsn: "mysql://db"
+`)
+  _, err := Load([]string{"--config", path})
+  if err == nil {
+    t.Fatal("ожидалась ошибка проверки")
+  }
+  for _, want := range []string{"port: порт 70000", "logger-create", "db-max-idle 5 больше db-max-open 2", "shutdown-timeout", "dsn"} {
+    if !strings.Contains(err.Error(), want) {
+      t.Fatalf("в ошибке нет %q: %v", want, err)
+    }
+  }
+
+  path = writeFile(t, "server.yaml", "port: 3001\n")
+  if _, err = Load([]string{"--config", path}); err == nil || !strings.Contains(err.Error(), "совпадают") {
+    t.Fatalf("ожидалась ошибка совпадения портов, получено %v", err)
+  }
+  if _, err = Load([]string{"--config", path, "--no-grpc"}); err != nil {
+    t.Fatalf("без gRPC порты не конфликтуют: %v", err)
+  }
+}
+
+func TestPrintRedacts(t *testing.T) {
+  conf, err := Load([]string{"--dsn", "postgres://user:secret@db:5432/tt", "--bootstrap-key", "key-secret", "--pgpass", "pg-secret"})
+  if err != nil {
+    t.Fatal(err)
+  }
+  var out bytes.Buffer
+  if err = conf.Print(&out); err != nil {
+    t.Fatal(err)
+  }
+  printed := out.String()
+  for _, secret := range []string{"secret@", "key-secret", "pg-secret"} {
+    if strings.Contains(printed, secret) {
+      t.Fatalf("секрет %s в выводе:\n%s", secret, printed)
+    }
+  }
+  for _, want := range []string{"dsn: postgres://user:xxxxx@db:5432/tt", "shutdown-timeout: 15s", "port: \"3000\""} {
+    if !strings.Contains(printed, want) {
+      t.Fatalf("в выводе нет %q:\n%s", want, printed)
+    }
+  }
+
+  // вывод пригоден как файл настроек
+  again, err := Load([]string{"--config", writeFile(t, "printed.yaml", printed)})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if again.Options.Port != conf.Options.Port || again.Options.ShutdownTimeout != conf.Options.ShutdownTimeout {
+    t.Fatalf("настройки из вывода отличаются: %+v", again.Options)
+  }
+}
+
+func TestReloadChanged(t *testing.T) {
+  dir := t.TempDir()
+  path := filepath.Join(dir, "server.yaml")
+  if err := os.WriteFile(path, []byte("logger-create: info\n"), 0o600); err != nil {
+    t.Fatal(err)
+  }
+  conf, err := Load([]string{"--config", path})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if err = os.WriteFile(path, []byte("logger-create: error\nport: 8080\n"), 0o600); err != nil {
+    t.Fatal(err)
+  }
+  next, err := conf.Reload()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if next.Level() != slog.LevelError {
+    t.Fatalf("уровень после перечитывания %s", next.Options.Log)
+  }
+  changed := conf.Changed(next)
+  slices.Sort(changed)
+  if !slices.Equal(changed, []string{"logger-create", "port"}) {
+    t.Fatalf("изменены %v", changed)
+  }
+
+  if err = os.WriteFile(path, []byte("logger-create: loud\n"), 0o600); err != nil {
+    t.Fatal(err)
+  }
+  if _, err = conf.Reload(); err == nil {
+    t.Fatal("некорректные настройки не должны перечитываться")
+  }
+}
@@ -1,19 +1,31 @@
 package logger
 
 import (
+  "fmt"
   "log/slog"
   "os"
 )
 
-func New(mode string) *slog.Logger {
-  var opts *slog.HandlerOptions
-  if mode == "debug" {
-    opts = &slog.HandlerOptions{
-      Level: slog.LevelDebug,
-    }
-  }
-
-  log := slog.New(slog.NewJSONHandler(os.Stdout, opts))
+// New логгер с уровнем level, который можно менять во время работы
+func New(level *slog.LevelVar) *slog.Logger {
+  log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+    Level: level,
+  }))
 
   return log
 }
+
+// ParseLevel уровень логирования по имени: debug, info, warn или error
+func ParseLevel(mode string) (slog.Level, error) {
+  switch mode {
+  case "debug":
+    return slog.LevelDebug, nil
+  case "info":
+    return slog.LevelInfo, nil
+  case "warn":
+    return slog.LevelWarn, nil
+  case "error":
+    return slog.LevelError, nil
+  }
+  return 0, fmt.Errorf("неизвестный уровень логирования %s, ожидается debug, info, warn или error", mode)
+}
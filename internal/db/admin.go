@@ -0,0 +1,263 @@
+package db
+
+import (
+  "context"
+  "fmt"
+  "github.com/brianvoe/gofakeit/v7"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "github.com/lib/pq"
+  "time"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils/const/status"
+)
+
+// SeedOptions объем тестовых данных: People человек, у каждого Tasks задач,
+// у каждой задачи Entries закрытых интервалов времени в прошлом
+type SeedOptions struct {
+  Organization string
+  People       int
+  Tasks        int
+  Entries      int
+  Seed         uint64
+}
+
+type SeedResult struct {
+  People  int
+  Tasks   int
+  Entries int
+}
+
+// Seed заполняет организацию случайными данными для нагрузочного тестирования.
+// Строки пишутся через COPY в одной транзакции, при ошибке не остается ничего
+func (d *DbRepo) Seed(ctx context.Context, opts SeedOptions) (SeedResult, error) {
+  var res SeedResult
+  faker := gofakeit.New(opts.Seed)
+
+  tx, err := d.db.BeginTxx(ctx, nil)
+  if err != nil {
+    return res, fmt.Errorf("ошибка начала транзакции: %v", err)
+  }
+  defer func() { _ = tx.Rollback() }()
+  if err = repo.SetTenant(ctx, tx, opts.Organization); err != nil {
+    return res, err
+  }
+
+  // паспорт уникален в организации, новые не должны совпасть с уже выданными
+  var existing []string
+  err = tx.SelectContext(ctx, &existing, `SELECT passport_number FROM person WHERE organization_id = $1 AND deleted_at IS NULL`, opts.Organization)
+  if err != nil {
+    return res, fmt.Errorf("ошибка чтения паспортов организации: %v", err)
+  }
+  passports := make(map[string]struct{}, len(existing)+opts.People)
+  for _, p := range existing {
+    passports[p] = struct{}{}
+  }
+
+  // время сервера базы, а не клиента: интервалы сравниваются с NOW() в запросах
+  var now time.Time
+  if err = tx.GetContext(ctx, &now, `SELECT LOCALTIMESTAMP`); err != nil {
+    return res, fmt.Errorf("ошибка чтения времени базы: %v", err)
+  }
+
+  people := make([]uuid.UUID, 0, opts.People)
+  err = copyIn(ctx, tx, "person", []string{"id", "surname", "name", "patronymic", "address", "passport_number", "organization_id"}, func(add func(...interface{}) error) error {
+    for i := 0; i < opts.People; i++ {
+      passport := faker.Numerify("#### ######")
+      for _, ok := passports[passport]; ok; _, ok = passports[passport] {
+        passport = faker.Numerify("#### ######")
+      }
+      passports[passport] = struct{}{}
+      id := uuid.New()
+      if err := add(id, faker.LastName(), faker.FirstName(), faker.MiddleName(), faker.Address().Address, passport, opts.Organization); err != nil {
+        return err
+      }
+      people = append(people, id)
+    }
+    return nil
+  })
+  if err != nil {
+    return res, fmt.Errorf("ошибка записи людей: %v", err)
+  }
+  res.People = len(people)
+
+  type entry struct {
+    task       uuid.UUID
+    start, end time.Time
+  }
+  var entries []entry
+  err = copyIn(ctx, tx, "tasks", []string{"idtask", "idperson", "task_name", "task_status", "organization_id", "created_at"}, func(add func(...interface{}) error) error {
+    for _, person := range people {
+      for i := 0; i < opts.Tasks; i++ {
+        id := uuid.New()
+        // интервалы идут назад от текущего момента с перерывами между ними
+        end := now.Add(-time.Duration(faker.IntRange(1, 72*60)) * time.Minute)
+        for j := 0; j < opts.Entries; j++ {
+          start := end.Add(-time.Duration(faker.IntRange(5, 4*60)) * time.Minute)
+          entries = append(entries, entry{task: id, start: start, end: end})
+          end = start.Add(-time.Duration(faker.IntRange(1, 24*60)) * time.Minute)
+        }
+        // таймеры в данных закрыты, поэтому в работе задач нет
+        taskStatus := status.New
+        if opts.Entries > 0 {
+          taskStatus = status.Pause
+          if faker.IntRange(0, 2) == 0 {
+            taskStatus = status.Complete
+          }
+        }
+        if err := add(id, person, faker.HipsterSentence(3), taskStatus, opts.Organization, end); err != nil {
+          return err
+        }
+        res.Tasks++
+      }
+    }
+    return nil
+  })
+  if err != nil {
+    return res, fmt.Errorf("ошибка записи задач: %v", err)
+  }
+
+  err = copyIn(ctx, tx, "timetask", []string{"idtask", "start_time", "end_time", "organization_id"}, func(add func(...interface{}) error) error {
+    for _, e := range entries {
+      if err := add(e.task, e.start, e.end, opts.Organization); err != nil {
+        return err
+      }
+    }
+    return nil
+  })
+  if err != nil {
+    return res, fmt.Errorf("ошибка записи интервалов: %v", err)
+  }
+  res.Entries = len(entries)
+
+  if err = tx.Commit(); err != nil {
+    return SeedResult{}, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+  }
+  return res, nil
+}
+
+// copyIn пишет строки, переданные fill через add, командой COPY в таблицу table
+func copyIn(ctx context.Context, tx *sqlx.Tx, table string, columns []string, fill func(add func(...interface{}) error) error) error {
+  stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+  if err != nil {
+    return err
+  }
+  err = fill(func(args ...interface{}) error {
+    _, err := stmt.ExecContext(ctx, args...)
+    return err
+  })
+  if err != nil {
+    _ = stmt.Close()
+    return err
+  }
+  if _, err = stmt.ExecContext(ctx); err != nil {
+    _ = stmt.Close()
+    return err
+  }
+  return stmt.Close()
+}
+
+// recalcStatuses статус задачи, который следует из ее интервалов: с открытым
+// интервалом задача в работе, без него - на паузе, новая с интервалами тоже на паузе
+const recalcStatuses = `
+WITH s AS (
+    SELECT t.idtask,
+           CASE
+               WHEN EXISTS (SELECT 1 FROM timetask tt WHERE tt.idtask = t.idtask AND tt.end_time IS NULL) THEN 'work'
+               WHEN t.task_status = 'work' THEN 'pause'
+               WHEN t.task_status = 'new' AND EXISTS (SELECT 1 FROM timetask tt WHERE tt.idtask = t.idtask) THEN 'pause'
+               ELSE t.task_status::text
+           END AS status
+    FROM tasks t
+    WHERE $1 = '' OR t.organization_id::text = $1
+)`
+
+// Recalc исправляет статусы задач, разошедшиеся с таймерами, и возвращает число
+// исправленных задач. organization пустая - все организации, dryRun - только подсчет
+func (d *DbRepo) Recalc(ctx context.Context, organization string, dryRun bool) (int64, error) {
+  tx, err := d.maintenanceTx(ctx, organization)
+  if err != nil {
+    return 0, err
+  }
+  defer func() { _ = tx.Rollback() }()
+
+  if dryRun {
+    var n int64
+    query := recalcStatuses + `
+SELECT COUNT(*) FROM s JOIN tasks t ON t.idtask = s.idtask WHERE s.status <> t.task_status::text`
+    if err = tx.GetContext(ctx, &n, query, organization); err != nil {
+      return 0, fmt.Errorf("ошибка подсчета задач: %v", err)
+    }
+    return n, nil
+  }
+  query := recalcStatuses + `
+UPDATE tasks t SET task_status = s.status::status, version = t.version + 1
+FROM s WHERE s.idtask = t.idtask AND s.status <> t.task_status::text`
+  result, err := tx.ExecContext(ctx, query, organization)
+  if err != nil {
+    return 0, fmt.Errorf("ошибка пересчета статусов: %v", err)
+  }
+  n, err := result.RowsAffected()
+  if err != nil {
+    return 0, fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if err = tx.Commit(); err != nil {
+    return 0, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+  }
+  return n, nil
+}
+
+// VacuumStaleTimers закрывает таймеры, открытые дольше olderThan: интервал
+// обрезается до olderThan от начала, задача ставится на паузу. Возвращает число
+// закрытых интервалов. organization пустая - все организации, dryRun - только подсчет
+func (d *DbRepo) VacuumStaleTimers(ctx context.Context, organization string, olderThan time.Duration, dryRun bool) (int64, error) {
+  const stale = `end_time IS NULL AND start_time < LOCALTIMESTAMP - make_interval(secs => $1)
+      AND ($2 = '' OR organization_id::text = $2)`
+  tx, err := d.maintenanceTx(ctx, organization)
+  if err != nil {
+    return 0, err
+  }
+  defer func() { _ = tx.Rollback() }()
+
+  if dryRun {
+    var n int64
+    if err = tx.GetContext(ctx, &n, `SELECT COUNT(*) FROM timetask WHERE `+stale, olderThan.Seconds(), organization); err != nil {
+      return 0, fmt.Errorf("ошибка подсчета таймеров: %v", err)
+    }
+    return n, nil
+  }
+
+  var tasks []string
+  query := `UPDATE timetask SET end_time = start_time + make_interval(secs => $1) WHERE ` + stale + ` RETURNING idtask`
+  if err = tx.SelectContext(ctx, &tasks, query, olderThan.Seconds(), organization); err != nil {
+    return 0, fmt.Errorf("ошибка закрытия таймеров: %v", err)
+  }
+  if len(tasks) > 0 {
+    _, err = tx.ExecContext(ctx, `UPDATE tasks SET task_status = 'pause', version = version + 1
+      WHERE idtask::text = ANY($1) AND task_status = 'work'`, pq.StringArray(tasks))
+    if err != nil {
+      return 0, fmt.Errorf("ошибка постановки задач на паузу: %v", err)
+    }
+  }
+  if err = tx.Commit(); err != nil {
+    return 0, fmt.Errorf("ошибка фиксации транзакции: %v", err)
+  }
+  return int64(len(tasks)), nil
+}
+
+// maintenanceTx транзакция обслуживания, которой RLS открывает строки organization,
+// а при пустой organization строки всех организаций
+func (d *DbRepo) maintenanceTx(ctx context.Context, organization string) (*sqlx.Tx, error) {
+  tx, err := d.db.BeginTxx(ctx, nil)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка начала транзакции: %v", err)
+  }
+  if organization == "" {
+    organization = repo.AllOrganizations
+  }
+  if err = repo.SetTenant(ctx, tx, organization); err != nil {
+    _ = tx.Rollback()
+    return nil, err
+  }
+  return tx, nil
+}
@@ -1,14 +1,14 @@
 package db
 
 import (
+  "errors"
   "fmt"
   "github.com/golang-migrate/migrate/v4"
   _ "github.com/golang-migrate/migrate/v4/database/postgres"
-  _ "github.com/golang-migrate/migrate/v4/source/file"
+  "github.com/golang-migrate/migrate/v4/source/iofs"
   "github.com/jmoiron/sqlx"
-  "path/filepath"
-
   "log"
+  "timetracker/internal/db/migrations"
 )
 
 func newDb(connStr string) *sqlx.DB {
@@ -25,20 +25,71 @@ func newDb(connStr string) *sqlx.DB {
 }
 
 func runMigrations(dbURL string) error {
-  dir, err := filepath.Abs("internal/db/migrations")
-  fmt.Println(dir)
+  m, err := NewMigrator(dbURL)
   if err != nil {
-    return fmt.Errorf("could not get absolute path: %w", err)
+    return err
   }
+  defer m.Close()
+  return m.Up()
+}
 
-  m, err := migrate.New(
-    "file://"+dir,
-    dbURL)
+// Migrator управляет версией схемы по встроенным миграциям
+type Migrator struct {
+  m *migrate.Migrate
+}
+
+func NewMigrator(dbURL string) (*Migrator, error) {
+  src, err := iofs.New(migrations.FS, ".")
   if err != nil {
-    return err
+    return nil, fmt.Errorf("ошибка чтения встроенных миграций: %v", err)
   }
-  if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-    return err
+  m, err := migrate.NewWithSourceInstance("iofs", src, dbURL)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка подключения к базе для миграций: %v", err)
+  }
+  return &Migrator{m: m}, nil
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up() error {
+  return noChange(m.m.Up())
+}
+
+// Down откатывает последнюю миграцию, с all - все
+func (m *Migrator) Down(all bool) error {
+  if all {
+    return noChange(m.m.Down())
+  }
+  return noChange(m.m.Steps(-1))
+}
+
+// To приводит схему к версии version вверх или вниз
+func (m *Migrator) To(version uint) error {
+  return noChange(m.m.Migrate(version))
+}
+
+// Version текущая версия схемы, 0 - миграции не применялись. dirty - последняя
+// миграция упала на середине и схему нужно поправить руками, затем Force
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+  version, dirty, err = m.m.Version()
+  if errors.Is(err, migrate.ErrNilVersion) {
+    return 0, false, nil
+  }
+  return version, dirty, err
+}
+
+// Force записывает версию без выполнения миграций и снимает признак dirty
+func (m *Migrator) Force(version int) error {
+  return m.m.Force(version)
+}
+
+func (m *Migrator) Close() {
+  _, _ = m.m.Close()
+}
+
+func noChange(err error) error {
+  if errors.Is(err, migrate.ErrNoChange) {
+    return nil
   }
-  return nil
+  return err
 }
@@ -0,0 +1,8 @@
+package db
+
+import "github.com/jmoiron/sqlx"
+
+// DB пул соединений хранилища для проверок, которым нужен прямой доступ к базе
+func (d *DbRepo) DB() *sqlx.DB {
+  return d.db
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "fmt"
+  "github.com/google/uuid"
+  "slices"
+  "timetracker/internal/bl/dto"
+)
+
+type apiKey struct {
+  dto.ApiKey
+  hash string
+}
+
+type apiKeyRepo struct {
+  s *storage
+}
+
+// CreateKey сохраняет хеш нового API-ключа
+func (r *apiKeyRepo) CreateKey(ctx context.Context, model *dto.ApiKey, hash string) (*dto.ApiKey, error) {
+  k := apiKey{ApiKey: *model, hash: hash}
+  k.ID = uuid.NewString()
+  k.CreatedAt = now()
+  k.LastUsedAt = nil
+  k.Key = ""
+  err := r.s.write(ctx, func(d *data) error {
+    for _, other := range d.keys {
+      if other.hash == hash {
+        return fmt.Errorf("ошибка вставки данных в базу: ключ уже существует")
+      }
+    }
+    d.keys[k.ID] = k
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &k.ApiKey, nil
+}
+
+// GetByHash ищет ключ по хешу во всех организациях, nil если ключа нет
+func (r *apiKeyRepo) GetByHash(ctx context.Context, hash string) (*dto.ApiKey, error) {
+  var res *dto.ApiKey
+  err := r.s.read(ctx, func(d *data) error {
+    for _, k := range d.keys {
+      if k.hash == hash {
+        res = &k.ApiKey
+        return nil
+      }
+    }
+    return nil
+  })
+  return res, err
+}
+
+func (r *apiKeyRepo) GetKeys(ctx context.Context) ([]dto.ApiKey, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  res := []dto.ApiKey{}
+  err = r.s.read(ctx, func(d *data) error {
+    for _, k := range d.keys {
+      if k.OrganizationID == org {
+        res = append(res, k.ApiKey)
+      }
+    }
+    return nil
+  })
+  slices.SortFunc(res, func(a, b dto.ApiKey) int { return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID)) })
+  return res, err
+}
+
+func (r *apiKeyRepo) DeleteKey(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    if k, ok := d.keys[id]; !ok || k.OrganizationID != org {
+      return fmt.Errorf("ключ с id %s не найден", id)
+    }
+    delete(d.keys, id)
+    return nil
+  })
+}
+
+// TouchKey отмечает время последнего использования ключа
+func (r *apiKeyRepo) TouchKey(ctx context.Context, id string) error {
+  return r.s.write(ctx, func(d *data) error {
+    if k, ok := d.keys[id]; ok {
+      used := now()
+      k.LastUsedAt = &used
+      d.keys[id] = k
+    }
+    return nil
+  })
+}
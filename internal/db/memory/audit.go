@@ -0,0 +1,29 @@
+package memory
+
+import (
+  "context"
+  "github.com/google/uuid"
+  "timetracker/internal/bl/dto"
+)
+
+type auditRepo struct {
+  s *storage
+}
+
+// Write сохраняет запись аудита, внутри транзакции она откатывается вместе с ней
+func (r *auditRepo) Write(ctx context.Context, record *dto.AuditRecord) (string, error) {
+  if _, err := tenant(ctx); err != nil {
+    return "", err
+  }
+  rec := *record
+  rec.ID = uuid.NewString()
+  rec.CreatedAt = now()
+  err := r.s.write(ctx, func(d *data) error {
+    d.audit = append(d.audit, rec)
+    return nil
+  })
+  if err != nil {
+    return "", err
+  }
+  return rec.ID, nil
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+  "context"
+  "fmt"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type idemKey struct {
+  org   string
+  actor string
+  key   string
+}
+
+// idemRecord ключ идемпотентности, resp пуст, пока запрос выполняется
+type idemRecord struct {
+  hash      string
+  resp      *dto.IdempotentResponse
+  createdAt time.Time
+}
+
+type idempotencyRepo struct {
+  s *storage
+}
+
+// Claim занимает ключ под новый запрос, false если ключ уже использован.
+// Ключи старше суток считаются свободными
+func (r *idempotencyRepo) Claim(ctx context.Context, actor, key, hash string) (bool, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return false, err
+  }
+  k := idemKey{org: org, actor: actor, key: key}
+  claimed := false
+  err = r.s.write(ctx, func(d *data) error {
+    if rec, ok := d.idem[k]; ok && rec.createdAt.After(now().Add(-24*time.Hour)) {
+      return nil
+    }
+    d.idem[k] = idemRecord{hash: hash, createdAt: now()}
+    claimed = true
+    return nil
+  })
+  return claimed, err
+}
+
+// Get возвращает хеш запроса ключа и сохраненный ответ, nil пока запрос выполняется
+func (r *idempotencyRepo) Get(ctx context.Context, actor, key string) (string, *dto.IdempotentResponse, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", nil, err
+  }
+  var rec idemRecord
+  err = r.s.read(ctx, func(d *data) error {
+    var ok bool
+    if rec, ok = d.idem[idemKey{org: org, actor: actor, key: key}]; !ok {
+      return fmt.Errorf("ошибка получения ключа идемпотентности: ключ %s не найден", key)
+    }
+    return nil
+  })
+  if err != nil {
+    return "", nil, err
+  }
+  return rec.hash, rec.resp, nil
+}
+
+func (r *idempotencyRepo) Complete(ctx context.Context, actor, key string, resp *dto.IdempotentResponse) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  k := idemKey{org: org, actor: actor, key: key}
+  return r.s.write(ctx, func(d *data) error {
+    if rec, ok := d.idem[k]; ok {
+      saved := *resp
+      saved.Body = append([]byte(nil), resp.Body...)
+      rec.resp = &saved
+      d.idem[k] = rec
+    }
+    return nil
+  })
+}
+
+// Release освобождает ключ, чтобы запрос можно было повторить
+func (r *idempotencyRepo) Release(ctx context.Context, actor, key string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    delete(d.idem, idemKey{org: org, actor: actor, key: key})
+    return nil
+  })
+}
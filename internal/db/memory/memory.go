@@ -0,0 +1,180 @@
+// Package memory хранилище в памяти процесса для тестов и демонстрации без Postgres.
+// Данные теряются при остановке, запросы повторяют поведение репозиториев базы
+package memory
+
+import (
+  "context"
+  "errors"
+  "maps"
+  "sync"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+  "timetracker/internal/db/pgcompat"
+  "timetracker/internal/utils"
+)
+
+// data все таблицы хранилища, clone дает снимок для отката транзакции
+type data struct {
+  people     map[string]person
+  tasks      map[string]task
+  entries    []entry
+  entrySeq   int
+  teams      map[string]team
+  members    map[string]map[string]bool
+  keys       map[string]apiKey
+  orgs       map[string]dto.Organization
+  audit      []dto.AuditRecord
+  idem       map[idemKey]idemRecord
+  webhooks   map[string]webhook
+  outbox     []outboxEvent
+  outboxSeq  int64
+  deliveries []delivery
+  deliverSeq int64
+}
+
+func (d *data) clone() data {
+  res := *d
+  res.people = maps.Clone(d.people)
+  res.tasks = maps.Clone(d.tasks)
+  res.entries = append([]entry(nil), d.entries...)
+  res.teams = maps.Clone(d.teams)
+  res.members = make(map[string]map[string]bool, len(d.members))
+  for id, members := range d.members {
+    res.members[id] = maps.Clone(members)
+  }
+  res.keys = maps.Clone(d.keys)
+  res.orgs = maps.Clone(d.orgs)
+  res.audit = append([]dto.AuditRecord(nil), d.audit...)
+  res.idem = maps.Clone(d.idem)
+  res.webhooks = maps.Clone(d.webhooks)
+  res.outbox = append([]outboxEvent(nil), d.outbox...)
+  res.deliveries = append([]delivery(nil), d.deliveries...)
+  return res
+}
+
+// storage общее состояние репозиториев. Транзакция держит блокировку записи
+// все время WithinTx, поэтому транзакции выполняются по очереди
+type storage struct {
+  mu   sync.RWMutex
+  data data
+}
+
+type txKey struct{}
+
+type tx struct {
+  s     *storage
+  hooks []func()
+  done  bool
+}
+
+// New возвращает пустое хранилище в памяти с организацией по умолчанию
+func New() *db.Store {
+  s := &storage{data: data{
+    people:   map[string]person{},
+    tasks:    map[string]task{},
+    teams:    map[string]team{},
+    members:  map[string]map[string]bool{},
+    keys:     map[string]apiKey{},
+    orgs:     map[string]dto.Organization{},
+    idem:     map[idemKey]idemRecord{},
+    webhooks: map[string]webhook{},
+  }}
+  s.data.orgs[dto.DefaultOrganization] = dto.Organization{ID: dto.DefaultOrganization, Name: "default", CreatedAt: now()}
+  return &db.Store{
+    ITxRunner: s,
+    People:    &peopleRepo{s},
+    Task:      &taskRepo{s},
+    TimeTask:  &timeTaskRepo{s},
+    ApiKey:    &apiKeyRepo{s},
+    Org:       &organizationRepo{s},
+    Team:      &teamRepo{s},
+    Audit:     &auditRepo{s},
+    Idem:      &idempotencyRepo{s},
+    Webhook:   &webhookRepo{s},
+  }
+}
+
+// current транзакция этого хранилища из ctx, которая еще не завершена
+func (s *storage) current(ctx context.Context) *tx {
+  t, ok := ctx.Value(txKey{}).(*tx)
+  if !ok || t.s != s || t.done {
+    return nil
+  }
+  return t
+}
+
+// WithinTx выполняет fn под блокировкой записи и при ошибке восстанавливает снимок данных,
+// снятый перед вызовом. Вложенный вызов снимает свой снимок и откатывает только свои изменения
+func (s *storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+  if t := s.current(ctx); t != nil {
+    snapshot, hooks := s.data.clone(), len(t.hooks)
+    if err := fn(ctx); err != nil {
+      s.data, t.hooks = snapshot, t.hooks[:hooks]
+      return err
+    }
+    return nil
+  }
+  s.mu.Lock()
+  t := &tx{s: s}
+  snapshot := s.data.clone()
+  defer func() {
+    // без фиксации снимок восстанавливается при любом выходе, в том числе при панике в fn
+    if !t.done {
+      t.done = true
+      s.data = snapshot
+      s.mu.Unlock()
+    }
+  }()
+  if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+    return err
+  }
+  t.done = true
+  s.mu.Unlock()
+  for _, hook := range t.hooks {
+    hook()
+  }
+  return nil
+}
+
+func (s *storage) AfterCommit(ctx context.Context, fn func()) {
+  t := s.current(ctx)
+  if t == nil {
+    fn()
+    return
+  }
+  t.hooks = append(t.hooks, fn)
+}
+
+// read выполняет fn под блокировкой чтения, внутри транзакции блокировка уже взята
+func (s *storage) read(ctx context.Context, fn func(d *data) error) error {
+  if s.current(ctx) == nil {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+  }
+  return fn(&s.data)
+}
+
+// write выполняет fn под блокировкой записи. fn проверяет все условия до первого
+// изменения, чтобы ошибка не оставляла запрос примененным наполовину
+func (s *storage) write(ctx context.Context, fn func(d *data) error) error {
+  if s.current(ctx) == nil {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+  }
+  return fn(&s.data)
+}
+
+// tenant возвращает организацию, которой ограничен запрос
+func tenant(ctx context.Context) (string, error) {
+  org := utils.TenantFromCtx(ctx)
+  if org == "" {
+    return "", errors.New("организация запроса не определена")
+  }
+  return org, nil
+}
+
+// now текущее время в том виде, в каком его хранит колонка TIMESTAMP базы
+func now() time.Time {
+  return pgcompat.Now()
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+  "testing"
+  "timetracker/internal/db"
+  "timetracker/internal/db/repotest"
+)
+
+// фиксация в памяти только снимает блокировку и не может завершиться ошибкой
+func TestContract(t *testing.T) {
+  repotest.Run(t, func(t *testing.T) *db.Store {
+    return New()
+  }, nil)
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "fmt"
+  "github.com/google/uuid"
+  "slices"
+  "timetracker/internal/bl/dto"
+)
+
+type organizationRepo struct {
+  s *storage
+}
+
+func (r *organizationRepo) CreateOrganization(ctx context.Context, name string) (*dto.Organization, error) {
+  org := dto.Organization{ID: uuid.NewString(), Name: name, CreatedAt: now()}
+  err := r.s.write(ctx, func(d *data) error {
+    for _, other := range d.orgs {
+      if other.Name == name {
+        return fmt.Errorf("ошибка вставки данных в базу: организация %s уже существует", name)
+      }
+    }
+    d.orgs[org.ID] = org
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &org, nil
+}
+
+func (r *organizationRepo) GetOrganizations(ctx context.Context) ([]dto.Organization, error) {
+  res := []dto.Organization{}
+  err := r.s.read(ctx, func(d *data) error {
+    for _, org := range d.orgs {
+      res = append(res, org)
+    }
+    return nil
+  })
+  slices.SortFunc(res, func(a, b dto.Organization) int { return cmp.Compare(a.Name, b.Name) })
+  return res, err
+}
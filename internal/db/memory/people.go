@@ -0,0 +1,570 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "fmt"
+  "github.com/google/uuid"
+  "slices"
+  "strings"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db/pgcompat"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+  "timetracker/internal/utils/cursor"
+)
+
+type person struct {
+  dto.Person
+  org       string
+  createdAt time.Time
+  deletedAt *time.Time
+}
+
+type peopleRepo struct {
+  s *storage
+}
+
+// live человек организации org, не помеченный удаленным
+func (d *data) live(org, id string) (person, bool) {
+  p, ok := d.people[id]
+  return p, ok && p.org == org && p.deletedAt == nil
+}
+
+// managedBy руководит ли managerID человеком p: напрямую или как руководитель его команды
+func (d *data) managedBy(p person, managerID string) bool {
+  if managerID == "" {
+    return false
+  }
+  if p.ManagerID == managerID {
+    return true
+  }
+  for teamID, members := range d.members {
+    if members[p.ID] && d.teams[teamID].ManagerID == managerID {
+      return true
+    }
+  }
+  return false
+}
+
+func (r *peopleRepo) GetByPassport(ctx context.Context, passport string) (*dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res *dto.Person
+  err = r.s.read(ctx, func(d *data) error {
+    for _, p := range d.people {
+      if p.org == org && p.deletedAt == nil && p.PassportNumber == passport {
+        res = &p.Person
+        return nil
+      }
+    }
+    return nil
+  })
+  return res, err
+}
+
+func (r *peopleRepo) GetByUUID(ctx context.Context, id string) (*dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res *dto.Person
+  err = r.s.read(ctx, func(d *data) error {
+    p, ok := d.live(org, id)
+    if !ok {
+      return fmt.Errorf("человек с uuid %s не найден", id)
+    }
+    res = &p.Person
+    return nil
+  })
+  return res, err
+}
+
+// passportTaken занят ли номер паспорта в организации кем-то, кроме except
+func (d *data) passportTaken(org, passport, except string) bool {
+  for _, p := range d.people {
+    if p.org == org && p.deletedAt == nil && p.PassportNumber == passport && p.ID != except {
+      return true
+    }
+  }
+  return false
+}
+
+func (r *peopleRepo) CreatePerson(ctx context.Context, model *dto.Person) (string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", err
+  }
+  p := person{Person: *model, org: org, createdAt: now()}
+  p.ID = uuid.NewString()
+  p.Version = 1
+  err = r.s.write(ctx, func(d *data) error {
+    if d.passportTaken(org, p.PassportNumber, "") {
+      return fmt.Errorf("ошибка вставки данных в базу: паспорт %s уже есть в организации", p.PassportNumber)
+    }
+    if p.ManagerID != "" {
+      if _, ok := d.people[p.ManagerID]; !ok {
+        return fmt.Errorf("ошибка вставки данных в базу: руководитель %s не найден", p.ManagerID)
+      }
+    }
+    d.people[p.ID] = p
+    return nil
+  })
+  if err != nil {
+    return "", err
+  }
+  model.Version = p.Version
+  return p.ID, nil
+}
+
+// DeleteByPerson удаляет человека, ссылки на него очищаются так же, как внешние ключи базы
+func (r *peopleRepo) DeleteByPerson(ctx context.Context, id string, version int) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    p, ok := d.live(org, id)
+    if !ok || (version != 0 && p.Version != version) {
+      return fmt.Errorf("человек с UUID %s не найден", id)
+    }
+    for _, t := range d.tasks {
+      if t.IdPerson == id {
+        return fmt.Errorf("ошибка удаления человека: у человека %s есть задачи", id)
+      }
+    }
+    delete(d.people, id)
+    for pid, other := range d.people {
+      if other.ManagerID == id {
+        other.ManagerID = ""
+        d.people[pid] = other
+      }
+    }
+    for tid, t := range d.teams {
+      if t.ManagerID == id {
+        t.ManagerID = ""
+        d.teams[tid] = t
+      }
+    }
+    for _, members := range d.members {
+      delete(members, id)
+    }
+    for kid, k := range d.keys {
+      if k.PersonID == id {
+        delete(d.keys, kid)
+      }
+    }
+    return nil
+  })
+}
+
+func (r *peopleRepo) UpdatePerson(ctx context.Context, model *dto.Person) (*dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res *dto.Person
+  err = r.s.write(ctx, func(d *data) error {
+    p, ok := d.live(org, model.ID)
+    if !ok || p.Version != model.Version {
+      return fmt.Errorf("%w: человек с UUID %s не найден или изменен", repo.ErrStale, model.ID)
+    }
+    if d.passportTaken(org, model.PassportNumber, p.ID) {
+      return fmt.Errorf("ошибка обновления данных в базе: паспорт %s уже есть в организации", model.PassportNumber)
+    }
+    p.Person = *model
+    p.Version++
+    d.people[p.ID] = p
+    res = &p.Person
+    return nil
+  })
+  return res, err
+}
+
+// peopleFilter проверяет человека по условиям фильтра, как peopleWhere репозитория базы
+func (d *data) peopleFilter(org string, filter *dto.PeopleFilter, p person) bool {
+  if p.org != org || p.deletedAt != nil {
+    return false
+  }
+  if filter.ID != "" && p.ID != filter.ID {
+    return false
+  }
+  if filter.ManagerID != "" && p.ID != filter.ManagerID && !d.managedBy(p, filter.ManagerID) {
+    return false
+  }
+  if filter.TeamID != "" && !d.members[filter.TeamID][p.ID] {
+    return false
+  }
+  if !matchAny(p.Surname, filter.Surname) || !matchAny(p.Name, filter.Name) ||
+      !matchAny(p.Patronymic, filter.Patronymic) || !matchAny(p.PassportNumber, filter.PassportNumber) {
+    return false
+  }
+  if filter.Address != "" && !match(p.Address, filter.Address) {
+    return false
+  }
+  if filter.HasActiveTask != nil {
+    active := false
+    for _, t := range d.tasks {
+      if t.IdPerson == p.ID && t.TaskStatus == status.Work {
+        active = true
+        break
+      }
+    }
+    if active != *filter.HasActiveTask {
+      return false
+    }
+  }
+  if filter.CreatedAfter != nil && !p.createdAt.After(*filter.CreatedAfter) {
+    return false
+  }
+  return true
+}
+
+// match сравнивает значение с образцом: со * по шаблону без учета регистра, без * на равенство
+func match(value, pattern string) bool {
+  if !strings.Contains(pattern, "*") {
+    return value == pattern
+  }
+  value = strings.ToLower(value)
+  parts := strings.Split(strings.ToLower(pattern), "*")
+  if !strings.HasPrefix(value, parts[0]) {
+    return false
+  }
+  value = value[len(parts[0]):]
+  last := parts[len(parts)-1]
+  for _, part := range parts[1 : len(parts)-1] {
+    i := strings.Index(value, part)
+    if i < 0 {
+      return false
+    }
+    value = value[i+len(part):]
+  }
+  return strings.HasSuffix(value, last)
+}
+
+// matchAny как match, но образцы через запятую объединяются через ИЛИ, пустой образец пропускает все
+func matchAny(value, patterns string) bool {
+  empty := true
+  for _, pattern := range strings.Split(patterns, ",") {
+    pattern = strings.TrimSpace(pattern)
+    if pattern == "" {
+      continue
+    }
+    if match(value, pattern) {
+      return true
+    }
+    empty = false
+  }
+  return empty
+}
+
+// sortValue значение поля сортировки человека для сравнения и курсора
+func (p person) sortValue(field string) string {
+  switch field {
+  case "surname":
+    return p.Surname
+  case "name":
+    return p.Name
+  case "patronymic":
+    return p.Patronymic
+  case "created_at":
+    return p.createdAt.Format(time.RFC3339Nano)
+  }
+  return p.ID
+}
+
+// compareBy сравнивает значения поля сортировки, время сравнивается как время
+func compareBy(field, a, b string) int {
+  if field == "created_at" {
+    ta, _ := time.Parse(time.RFC3339Nano, a)
+    tb, _ := time.Parse(time.RFC3339Nano, b)
+    return ta.Compare(tb)
+  }
+  return cmp.Compare(a, b)
+}
+
+// comparePeople сравнивает людей по полям sort, id замыкает порядок
+func comparePeople(sort []dto.SortField, a, b []string) int {
+  for i, field := range sort {
+    c := compareBy(field.Field, a[i], b[i])
+    if field.Desc {
+      c = -c
+    }
+    if c != 0 {
+      return c
+    }
+  }
+  return cmp.Compare(a[len(a)-1], b[len(b)-1])
+}
+
+func (r *peopleRepo) GetPeople(ctx context.Context, filter *dto.PeopleFilter, page dto.PageRequest) ([]dto.Person, dto.PageInfo, error) {
+  var info dto.PageInfo
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, info, err
+  }
+
+  sort := filter.Sort
+  if len(sort) == 0 {
+    sort = []dto.SortField{{Field: "surname"}, {Field: "name"}}
+  }
+  for _, field := range sort {
+    if !slices.Contains(dto.PeopleSortFields, field.Field) {
+      return nil, info, fmt.Errorf("сортировка по полю %s не поддерживается", field.Field)
+    }
+  }
+  sortKey := dto.SortKey(sort)
+  var after []string
+  if page.Cursor != "" {
+    after, err = cursor.Decode(page.Cursor, len(sort)+2)
+    if err != nil || after[0] != sortKey || !utils.IsValidUUID(after[len(after)-1]) {
+      return nil, info, cursor.ErrInvalid
+    }
+    after = after[1:]
+    for i, field := range sort {
+      if field.Field == "created_at" {
+        if _, err = time.Parse(time.RFC3339Nano, after[i]); err != nil {
+          return nil, info, cursor.ErrInvalid
+        }
+      }
+    }
+  }
+
+  values := func(p person) []string {
+    res := make([]string, 0, len(sort)+1)
+    for _, field := range sort {
+      res = append(res, p.sortValue(field.Field))
+    }
+    return append(res, p.ID)
+  }
+
+  var matched []person
+  err = r.s.read(ctx, func(d *data) error {
+    for _, p := range d.people {
+      if d.peopleFilter(org, filter, p) {
+        matched = append(matched, p)
+      }
+    }
+    return nil
+  })
+  if err != nil {
+    return nil, info, err
+  }
+  if page.Count {
+    total := len(matched)
+    info.Total = &total
+  }
+
+  slices.SortFunc(matched, func(a, b person) int {
+    return comparePeople(sort, values(a), values(b))
+  })
+  if after != nil {
+    matched = slices.DeleteFunc(matched, func(p person) bool {
+      return comparePeople(sort, values(p), after) <= 0
+    })
+  }
+  matched = matched[min(page.Offset, len(matched)):]
+
+  if len(matched) > page.Limit {
+    matched = matched[:page.Limit]
+    info.NextCursor = cursor.Encode(append([]string{sortKey}, values(matched[len(matched)-1])...)...)
+  }
+  res := make([]dto.Person, 0, len(matched))
+  for _, p := range matched {
+    res = append(res, p.Person)
+  }
+  return res, info, nil
+}
+
+// SearchPeople ищет людей по префиксам слов ФИО и адреса или по похожести строки с порогом 0.6,
+// как оператор <% базы. Ранг и выделение совпадений приблизительны
+func (r *peopleRepo) SearchPeople(ctx context.Context, filter *dto.PeopleFilter, q string, limit int) ([]dto.PersonMatch, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res []dto.PersonMatch
+  err = r.s.read(ctx, func(d *data) error {
+    for _, p := range d.people {
+      if !d.peopleFilter(org, filter, p) {
+        continue
+      }
+      text := p.Surname + " " + p.Name + " " + p.Patronymic + ", " + p.Address
+      found := pgcompat.PrefixMatch(q, text)
+      rank := pgcompat.WordSimilarity(strings.ToLower(q), text)
+      if !found && rank < 0.6 {
+        continue
+      }
+      if found {
+        rank = max(rank, 1)
+      }
+      res = append(res, dto.PersonMatch{Person: p.Person, Rank: rank, Highlight: pgcompat.Highlight(text, q)})
+    }
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  slices.SortFunc(res, func(a, b dto.PersonMatch) int {
+    return cmp.Or(cmp.Compare(b.Rank, a.Rank), cmp.Compare(a.Surname, b.Surname), cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+  })
+  return res[:min(limit, len(res))], nil
+}
+
+// FindDuplicates ищет пары людей с одинаковым ФИО без учета регистра и похожим адресом
+func (r *peopleRepo) FindDuplicates(ctx context.Context, threshold float64, limit int) ([]dto.Duplicate, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res []dto.Duplicate
+  err = r.s.read(ctx, func(d *data) error {
+    for _, a := range d.people {
+      for _, b := range d.people {
+        if a.org != org || b.org != org || a.deletedAt != nil || b.deletedAt != nil || a.ID >= b.ID {
+          continue
+        }
+        if !strings.EqualFold(a.Surname, b.Surname) || !strings.EqualFold(a.Name, b.Name) || !strings.EqualFold(a.Patronymic, b.Patronymic) {
+          continue
+        }
+        if sim := pgcompat.Similarity(a.Address, b.Address); sim >= threshold {
+          res = append(res, dto.Duplicate{First: a.Person, Second: b.Person, AddressSimilarity: sim})
+        }
+      }
+    }
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  slices.SortFunc(res, func(a, b dto.Duplicate) int {
+    return cmp.Or(cmp.Compare(b.AddressSimilarity, a.AddressSimilarity), cmp.Compare(a.First.Surname, b.First.Surname),
+      cmp.Compare(a.First.Name, b.First.Name), cmp.Compare(a.First.ID, b.First.ID), cmp.Compare(a.Second.ID, b.Second.ID))
+  })
+  return res[:min(limit, len(res))], nil
+}
+
+// MergePerson переносит задачи, подчиненных, команды и ключи человека sourceID к targetID
+// и помечает источник удаленным. Вызывается внутри транзакции
+func (r *peopleRepo) MergePerson(ctx context.Context, sourceID, targetID string) (int64, error) {
+  if r.s.current(ctx) == nil {
+    return 0, fmt.Errorf("слияние людей требует транзакции")
+  }
+  org, err := tenant(ctx)
+  if err != nil {
+    return 0, err
+  }
+  var moved int64
+  err = r.s.write(ctx, func(d *data) error {
+    source, ok := d.live(org, sourceID)
+    if !ok {
+      return fmt.Errorf("человек с UUID %s не найден", sourceID)
+    }
+    target, ok := d.live(org, targetID)
+    if !ok {
+      return fmt.Errorf("человек с UUID %s не найден", targetID)
+    }
+    for id, t := range d.tasks {
+      if t.IdPerson == sourceID && t.org == org {
+        t.IdPerson = targetID
+        d.tasks[id] = t
+        moved++
+      }
+    }
+    for id, p := range d.people {
+      if p.ManagerID == sourceID && id != targetID && p.org == org {
+        p.ManagerID = targetID
+        d.people[id] = p
+      }
+    }
+    // цель, подчиненная источнику, получает его руководителя, а не саму себя
+    if target.ManagerID == sourceID {
+      target.ManagerID = source.ManagerID
+      if target.ManagerID == targetID {
+        target.ManagerID = ""
+      }
+      d.people[targetID] = target
+    }
+    for id, t := range d.teams {
+      if t.ManagerID == sourceID && t.org == org {
+        t.ManagerID = targetID
+        d.teams[id] = t
+      }
+    }
+    for id, k := range d.keys {
+      if k.PersonID == sourceID && k.OrganizationID == org {
+        k.PersonID = targetID
+        d.keys[id] = k
+      }
+    }
+    for teamID, members := range d.members {
+      if members[sourceID] {
+        if d.teams[teamID].org == org {
+          members[targetID] = true
+        }
+        delete(members, sourceID)
+      }
+    }
+    deleted := now()
+    source.deletedAt = &deleted
+    source.ManagerID = ""
+    d.people[sourceID] = source
+    return nil
+  })
+  if err != nil {
+    return 0, err
+  }
+  return moved, nil
+}
+
+func (r *peopleRepo) IsManagedBy(ctx context.Context, id, managerID string) (bool, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return false, err
+  }
+  var managed bool
+  err = r.s.read(ctx, func(d *data) error {
+    p, ok := d.live(org, id)
+    managed = ok && d.managedBy(p, managerID)
+    return nil
+  })
+  return managed, err
+}
+
+func (r *peopleRepo) GetByUUIDs(ctx context.Context, ids []string) ([]dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  res := make([]dto.Person, 0, len(ids))
+  err = r.s.read(ctx, func(d *data) error {
+    for _, id := range ids {
+      if p, ok := d.live(org, id); ok {
+        res = append(res, p.Person)
+      }
+    }
+    return nil
+  })
+  return res, err
+}
+
+func (r *peopleRepo) ManagedAmong(ctx context.Context, ids []string, managerID string) ([]string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res []string
+  err = r.s.read(ctx, func(d *data) error {
+    for _, id := range ids {
+      if p, ok := d.live(org, id); ok && d.managedBy(p, managerID) {
+        res = append(res, id)
+      }
+    }
+    return nil
+  })
+  return res, err
+}
@@ -0,0 +1,319 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "fmt"
+  "github.com/google/uuid"
+  "slices"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db/pgcompat"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+  "timetracker/internal/utils/cursor"
+)
+
+type task struct {
+  dto.Task
+  org       string
+  createdAt time.Time
+}
+
+type taskRepo struct {
+  s *storage
+}
+
+func (d *data) task(org, id string) (task, error) {
+  t, ok := d.tasks[id]
+  if !ok || t.org != org {
+    return t, fmt.Errorf("задача с id %s не найдена", id)
+  }
+  return t, nil
+}
+
+func (r *taskRepo) CreateTask(ctx context.Context, model *dto.Task) (*dto.Task, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  t := task{Task: *model, org: org, createdAt: now()}
+  t.IdTask = uuid.NewString()
+  t.TaskStatus = status.New
+  t.Version = 1
+  err = r.s.write(ctx, func(d *data) error {
+    if _, ok := d.people[t.IdPerson]; !ok {
+      return fmt.Errorf("ошибка вставки данных в базу: человек %s не найден", t.IdPerson)
+    }
+    d.tasks[t.IdTask] = t
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &t.Task, nil
+}
+
+func (r *taskRepo) GetTask(ctx context.Context, id string) (*dto.Task, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res *dto.Task
+  err = r.s.read(ctx, func(d *data) error {
+    t, err := d.task(org, id)
+    res = &t.Task
+    return err
+  })
+  if err != nil {
+    return nil, err
+  }
+  return res, nil
+}
+
+// compareTasks порядок задач человека: по времени создания, затем по id
+func compareTasks(a, b task) int {
+  return cmp.Or(a.createdAt.Compare(b.createdAt), cmp.Compare(a.IdTask, b.IdTask))
+}
+
+// personTasks задачи человека в порядке compareTasks
+func (d *data) personTasks(org, personID string) []task {
+  var res []task
+  for _, t := range d.tasks {
+    if t.IdPerson == personID && t.org == org {
+      res = append(res, t)
+    }
+  }
+  slices.SortFunc(res, compareTasks)
+  return res
+}
+
+func (r *taskRepo) GetTasks(ctx context.Context, personID string, page dto.PageRequest) ([]dto.Task, dto.PageInfo, error) {
+  var info dto.PageInfo
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, info, err
+  }
+  var after *task
+  if page.Cursor != "" {
+    values, err := cursor.Decode(page.Cursor, 2)
+    if err != nil || !utils.IsValidUUID(values[1]) {
+      return nil, info, cursor.ErrInvalid
+    }
+    createdAt, err := time.Parse(time.RFC3339Nano, values[0])
+    if err != nil {
+      return nil, info, cursor.ErrInvalid
+    }
+    after = &task{Task: dto.Task{IdTask: values[1]}, createdAt: createdAt}
+  }
+
+  var tasks []task
+  err = r.s.read(ctx, func(d *data) error {
+    tasks = d.personTasks(org, personID)
+    return nil
+  })
+  if err != nil {
+    return nil, info, err
+  }
+  if page.Count {
+    total := len(tasks)
+    info.Total = &total
+  }
+  if after != nil {
+    tasks = slices.DeleteFunc(tasks, func(t task) bool { return compareTasks(t, *after) <= 0 })
+  }
+  tasks = tasks[min(page.Offset, len(tasks)):]
+  if len(tasks) > page.Limit {
+    tasks = tasks[:page.Limit]
+    last := tasks[len(tasks)-1]
+    info.NextCursor = cursor.Encode(last.createdAt.Format(time.RFC3339Nano), last.IdTask)
+  }
+  res := make([]dto.Task, 0, len(tasks))
+  for _, t := range tasks {
+    res = append(res, t.Task)
+  }
+  return res, info, nil
+}
+
+func (r *taskRepo) GetTaskStatus(ctx context.Context, id string) (string, error) {
+  t, err := r.GetTask(ctx, id)
+  if err != nil {
+    return "", err
+  }
+  return t.TaskStatus, nil
+}
+
+// UpdateStatus меняет статус задачи, при ненулевой version только в этой версии записи
+func (r *taskRepo) UpdateStatus(ctx context.Context, id, st string, version int) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    t, err := d.task(org, id)
+    if err != nil {
+      return err
+    }
+    if version != 0 && t.Version != version {
+      return fmt.Errorf("%w: задача с id %s не найдена или изменена", repo.ErrStale, id)
+    }
+    t.TaskStatus = st
+    t.Version++
+    d.tasks[id] = t
+    return nil
+  })
+}
+
+// taskTime время задачи за период
+type taskTime struct {
+  person   string
+  task     string
+  taskName string
+  total    pgcompat.Interval
+}
+
+// periodTimes время закрытых интервалов задач людей, для которых include вернул true, в пределах
+// периода [start, end]: интервалы обрезаются по границам, как в запросах репозитория базы
+func (d *data) periodTimes(org, start, end string, include func(t task) bool) ([]taskTime, error) {
+  from, err := pgcompat.ParseTimestamp(start)
+  if err != nil {
+    return nil, err
+  }
+  to, err := pgcompat.ParseTimestamp(end)
+  if err != nil {
+    return nil, err
+  }
+  byTask := map[string]*taskTime{}
+  var res []*taskTime
+  for _, e := range d.entries {
+    t, ok := d.tasks[e.task]
+    if !ok || t.org != org || e.end == nil || !include(t) {
+      continue
+    }
+    inside := !e.start.Before(from) && !e.start.After(to) ||
+        !e.end.Before(from) && !e.end.After(to) ||
+        e.start.Before(from) && e.end.After(to)
+    if !inside {
+      continue
+    }
+    s, f := e.start, *e.end
+    if s.Before(from) {
+      s = from
+    }
+    if f.After(to) {
+      f = to
+    }
+    tt, ok := byTask[t.IdTask]
+    if !ok {
+      tt = &taskTime{person: t.IdPerson, task: t.IdTask, taskName: t.TaskName}
+      byTask[t.IdTask] = tt
+      res = append(res, tt)
+    }
+    tt.total = tt.total.Add(pgcompat.Between(s, f))
+  }
+  times := make([]taskTime, 0, len(res))
+  for _, tt := range res {
+    times = append(times, *tt)
+  }
+  return times, nil
+}
+
+func (r *taskRepo) TaskTimes(ctx context.Context, id, start, end string) ([]dto.TaskTimeResult, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var times []taskTime
+  err = r.s.read(ctx, func(d *data) error {
+    times, err = d.periodTimes(org, start, end, func(t task) bool { return t.IdPerson == id })
+    return err
+  })
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  slices.SortFunc(times, func(a, b taskTime) int {
+    return cmp.Or(b.total.Compare(a.total), cmp.Compare(a.task, b.task))
+  })
+  var res []dto.TaskTimeResult
+  for _, tt := range times {
+    res = append(res, dto.TaskTimeResult{IDTask: tt.task, TaskName: tt.taskName, TotalTime: tt.total.String()})
+  }
+  return res, nil
+}
+
+// TeamTimes трудозатраты участников команды по задачам, участники упорядочены по общему времени
+func (r *taskRepo) TeamTimes(ctx context.Context, teamID, start, end string) ([]dto.TeamTimeResult, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var times []taskTime
+  people := map[string]dto.TeamTimeResult{}
+  err = r.s.read(ctx, func(d *data) error {
+    members := d.members[teamID]
+    times, err = d.periodTimes(org, start, end, func(t task) bool { return members[t.IdPerson] })
+    for _, tt := range times {
+      p := d.people[tt.person]
+      people[tt.person] = dto.TeamTimeResult{IDPerson: p.ID, Surname: p.Surname, Name: p.Name}
+    }
+    return err
+  })
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  personTime := map[string]pgcompat.Interval{}
+  for _, tt := range times {
+    personTime[tt.person] = personTime[tt.person].Add(tt.total)
+  }
+  slices.SortFunc(times, func(a, b taskTime) int {
+    return cmp.Or(personTime[b.person].Compare(personTime[a.person]), cmp.Compare(a.person, b.person),
+      b.total.Compare(a.total), cmp.Compare(a.task, b.task))
+  })
+  var res []dto.TeamTimeResult
+  for _, tt := range times {
+    if len(res) == 0 || res[len(res)-1].IDPerson != tt.person {
+      p := people[tt.person]
+      p.TotalTime = personTime[tt.person].String()
+      res = append(res, p)
+    }
+    last := &res[len(res)-1]
+    last.Tasks = append(last.Tasks, dto.TaskTimeResult{IDTask: tt.task, TaskName: tt.taskName, TotalTime: tt.total.String()})
+  }
+  return res, nil
+}
+
+// GetTasksOfPeople первые страницы задач нескольких людей, в том же порядке и с теми же курсорами, что и GetTasks
+func (r *taskRepo) GetTasksOfPeople(ctx context.Context, personIDs []string, limit int) (map[string][]dto.Task, map[string]dto.PageInfo, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, nil, err
+  }
+  tasks := make(map[string][]dto.Task, len(personIDs))
+  infos := make(map[string]dto.PageInfo, len(personIDs))
+  err = r.s.read(ctx, func(d *data) error {
+    for _, id := range personIDs {
+      personTasks := d.personTasks(org, id)
+      if len(personTasks) == 0 {
+        continue
+      }
+      total := len(personTasks)
+      info := dto.PageInfo{Total: &total}
+      if len(personTasks) > limit {
+        personTasks = personTasks[:limit]
+        last := personTasks[len(personTasks)-1]
+        info.NextCursor = cursor.Encode(last.createdAt.Format(time.RFC3339Nano), last.IdTask)
+      }
+      for _, t := range personTasks {
+        tasks[id] = append(tasks[id], t.Task)
+      }
+      infos[id] = info
+    }
+    return nil
+  })
+  if err != nil {
+    return nil, nil, err
+  }
+  return tasks, infos, nil
+}
@@ -0,0 +1,169 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "fmt"
+  "github.com/google/uuid"
+  "slices"
+  "timetracker/internal/bl/dto"
+)
+
+type team struct {
+  dto.Team
+  org string
+}
+
+type teamRepo struct {
+  s *storage
+}
+
+func (d *data) team(org, id string) (team, error) {
+  t, ok := d.teams[id]
+  if !ok || t.org != org {
+    return t, fmt.Errorf("команда с id %s не найдена", id)
+  }
+  return t, nil
+}
+
+func (r *teamRepo) CreateTeam(ctx context.Context, model *dto.Team) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  t := team{Team: dto.Team{ID: uuid.NewString(), Name: model.Name, ManagerID: model.ManagerID}, org: org}
+  err = r.s.write(ctx, func(d *data) error {
+    if _, ok := d.people[t.ManagerID]; t.ManagerID != "" && !ok {
+      return fmt.Errorf("ошибка вставки данных в базу: руководитель %s не найден", t.ManagerID)
+    }
+    d.teams[t.ID] = t
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &t.Team, nil
+}
+
+// GetTeam возвращает команду вместе со списком участников
+func (r *teamRepo) GetTeam(ctx context.Context, id string) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res dto.Team
+  err = r.s.read(ctx, func(d *data) error {
+    t, err := d.team(org, id)
+    if err != nil {
+      return err
+    }
+    res = t.Team
+    res.Members = nil
+    for personID := range d.members[id] {
+      res.Members = append(res.Members, personID)
+    }
+    slices.Sort(res.Members)
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &res, nil
+}
+
+// GetTeams возвращает команды организации, при непустом managerID только команды этого руководителя
+func (r *teamRepo) GetTeams(ctx context.Context, managerID string) ([]dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  res := []dto.Team{}
+  err = r.s.read(ctx, func(d *data) error {
+    for _, t := range d.teams {
+      if t.org == org && (managerID == "" || t.ManagerID == managerID) {
+        res = append(res, t.Team)
+      }
+    }
+    return nil
+  })
+  slices.SortFunc(res, func(a, b dto.Team) int { return cmp.Compare(a.Name, b.Name) })
+  return res, err
+}
+
+func (r *teamRepo) UpdateTeam(ctx context.Context, model *dto.Team) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var res dto.Team
+  err = r.s.write(ctx, func(d *data) error {
+    t, err := d.team(org, model.ID)
+    if err != nil {
+      return err
+    }
+    t.Name, t.ManagerID = model.Name, model.ManagerID
+    d.teams[t.ID] = t
+    res = t.Team
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &res, nil
+}
+
+func (r *teamRepo) DeleteTeam(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    if _, err := d.team(org, id); err != nil {
+      return err
+    }
+    delete(d.teams, id)
+    delete(d.members, id)
+    return nil
+  })
+}
+
+// AddMember добавляет человека в команду, повторное добавление не ошибка
+func (r *teamRepo) AddMember(ctx context.Context, teamID, personID string) error {
+  return r.s.write(ctx, func(d *data) error {
+    _, teamOk := d.teams[teamID]
+    _, personOk := d.people[personID]
+    if !teamOk || !personOk {
+      return fmt.Errorf("ошибка добавления участника команды: команда %s или человек %s не найдены", teamID, personID)
+    }
+    if d.members[teamID] == nil {
+      d.members[teamID] = map[string]bool{}
+    }
+    d.members[teamID][personID] = true
+    return nil
+  })
+}
+
+func (r *teamRepo) RemoveMember(ctx context.Context, teamID, personID string) error {
+  return r.s.write(ctx, func(d *data) error {
+    if !d.members[teamID][personID] {
+      return fmt.Errorf("человек %s не состоит в команде %s", personID, teamID)
+    }
+    delete(d.members[teamID], personID)
+    return nil
+  })
+}
+
+// PersonTeams id команд, в которых состоит человек
+func (r *teamRepo) PersonTeams(ctx context.Context, personID string) ([]string, error) {
+  var res []string
+  err := r.s.read(ctx, func(d *data) error {
+    for teamID, members := range d.members {
+      if members[personID] {
+        res = append(res, teamID)
+      }
+    }
+    return nil
+  })
+  slices.Sort(res)
+  return res, err
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "fmt"
+  "slices"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db/pgcompat"
+)
+
+type entry struct {
+  id    int
+  task  string
+  org   string
+  start time.Time
+  end   *time.Time
+}
+
+func (e entry) toDTO() dto.TimeTask {
+  return dto.TimeTask{ID: e.id, IDTask: e.task, StartTime: e.start, EndTime: e.end}
+}
+
+type timeTaskRepo struct {
+  s *storage
+}
+
+// insert добавляет интервал задачи с очередным id
+func (d *data) insert(e entry) entry {
+  d.entrySeq++
+  e.id = d.entrySeq
+  d.entries = append(d.entries, e)
+  return e
+}
+
+func (r *timeTaskRepo) StartTimer(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    if _, ok := d.tasks[id]; !ok {
+      return fmt.Errorf("ошибка вставки данных в таблицу timetask: задача %s не найдена", id)
+    }
+    d.insert(entry{task: id, org: org, start: now()})
+    return nil
+  })
+}
+
+func (r *timeTaskRepo) StopTimer(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    stopped := false
+    end := now()
+    for i, e := range d.entries {
+      if e.task == id && e.org == org && e.end == nil {
+        d.entries[i].end = &end
+        stopped = true
+      }
+    }
+    if !stopped {
+      return fmt.Errorf("нет активного таймера для задачи с id %s", id)
+    }
+    return nil
+  })
+}
+
+// AddEntry добавляет внесенный вручную завершенный интервал работы над задачей,
+// если он не пересекается с уже учтенными интервалами этой задачи
+func (r *timeTaskRepo) AddEntry(ctx context.Context, model *dto.TimeTask) (*dto.TimeTask, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  start, end := pgcompat.Wall(model.StartTime), pgcompat.Wall(*model.EndTime)
+  var res dto.TimeTask
+  err = r.s.write(ctx, func(d *data) error {
+    if _, ok := d.tasks[model.IDTask]; !ok {
+      return fmt.Errorf("ошибка вставки данных в таблицу timetask: задача %s не найдена", model.IDTask)
+    }
+    current := now()
+    for _, e := range d.entries {
+      eEnd := current
+      if e.end != nil {
+        eEnd = *e.end
+      }
+      if e.task == model.IDTask && e.org == org && e.start.Before(end) && eEnd.After(start) {
+        return fmt.Errorf("интервал пересекается с уже учтенным временем задачи %s", model.IDTask)
+      }
+    }
+    res = d.insert(entry{task: model.IDTask, org: org, start: start, end: &end}).toDTO()
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  return &res, nil
+}
+
+// GetEntries интервалы работы задач taskIDs, принадлежащих людям personIDs, по задачам в порядке начала
+func (r *timeTaskRepo) GetEntries(ctx context.Context, taskIDs, personIDs []string) (map[string][]dto.TimeTask, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  res := make(map[string][]dto.TimeTask, len(taskIDs))
+  err = r.s.read(ctx, func(d *data) error {
+    for _, e := range d.entries {
+      t, ok := d.tasks[e.task]
+      if ok && t.org == org && slices.Contains(taskIDs, e.task) && slices.Contains(personIDs, t.IdPerson) {
+        res[e.task] = append(res[e.task], e.toDTO())
+      }
+    }
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  for _, entries := range res {
+    slices.SortFunc(entries, func(a, b dto.TimeTask) int {
+      return cmp.Or(a.StartTime.Compare(b.StartTime), cmp.Compare(a.ID, b.ID))
+    })
+  }
+  return res, nil
+}
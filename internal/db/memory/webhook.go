@@ -0,0 +1,274 @@
+package memory
+
+import (
+  "cmp"
+  "context"
+  "encoding/json"
+  "fmt"
+  "github.com/google/uuid"
+  "slices"
+  "strconv"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type webhook struct {
+  dto.Webhook
+  org string
+}
+
+type outboxEvent struct {
+  id        int64
+  org       string
+  eventType string
+  payload   []byte
+  createdAt time.Time
+}
+
+type delivery struct {
+  id             int64
+  webhookID      string
+  outboxID       int64
+  status         string
+  attempts       int
+  nextAttemptAt  time.Time
+  responseStatus *int
+  lastError      string
+  deliveredAt    *time.Time
+  createdAt      time.Time
+}
+
+type webhookRepo struct {
+  s *storage
+}
+
+// public подписка без секрета, как ее возвращает репозиторий базы
+func (w webhook) public() dto.Webhook {
+  res := w.Webhook
+  res.Secret = ""
+  res.EventTypes = slices.Clone(w.EventTypes)
+  return res
+}
+
+func (r *webhookRepo) CreateWebhook(ctx context.Context, model *dto.Webhook) (*dto.Webhook, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  w := webhook{Webhook: *model, org: org}
+  w.ID = uuid.NewString()
+  w.EventTypes = slices.Clone(model.EventTypes)
+  w.CreatedAt = now()
+  err = r.s.write(ctx, func(d *data) error {
+    d.webhooks[w.ID] = w
+    return nil
+  })
+  if err != nil {
+    return nil, err
+  }
+  res := w.public()
+  return &res, nil
+}
+
+func (r *webhookRepo) GetWebhooks(ctx context.Context) ([]dto.Webhook, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  res := []dto.Webhook{}
+  err = r.s.read(ctx, func(d *data) error {
+    for _, w := range d.webhooks {
+      if w.org == org {
+        res = append(res, w.public())
+      }
+    }
+    return nil
+  })
+  slices.SortFunc(res, func(a, b dto.Webhook) int { return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID)) })
+  return res, err
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом ее доставок
+func (r *webhookRepo) DeleteWebhook(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    if w, ok := d.webhooks[id]; !ok || w.org != org {
+      return fmt.Errorf("вебхук с id %s не найден", id)
+    }
+    delete(d.webhooks, id)
+    d.deliveries = slices.DeleteFunc(d.deliveries, func(del delivery) bool { return del.webhookID == id })
+    return nil
+  })
+}
+
+// GetDeliveries последние доставки подписки, при непустом status только в этом статусе
+func (r *webhookRepo) GetDeliveries(ctx context.Context, webhookID, status string, limit int) ([]dto.WebhookDelivery, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  res := []dto.WebhookDelivery{}
+  err = r.s.read(ctx, func(d *data) error {
+    if w, ok := d.webhooks[webhookID]; !ok || w.org != org {
+      return nil
+    }
+    for i := len(d.deliveries) - 1; i >= 0 && len(res) < limit; i-- {
+      del := d.deliveries[i]
+      if del.webhookID != webhookID || (status != "" && del.status != status) {
+        continue
+      }
+      item := dto.WebhookDelivery{
+        ID:             del.id,
+        WebhookID:      del.webhookID,
+        EventID:        del.outboxID,
+        Status:         del.status,
+        Attempts:       del.attempts,
+        ResponseStatus: del.responseStatus,
+        LastError:      del.lastError,
+        DeliveredAt:    del.deliveredAt,
+        CreatedAt:      del.createdAt,
+      }
+      if i := slices.IndexFunc(d.outbox, func(e outboxEvent) bool { return e.id == del.outboxID }); i >= 0 {
+        item.EventType = d.outbox[i].eventType
+      }
+      if del.status == dto.DeliveryPending {
+        next := del.nextAttemptAt
+        item.NextAttemptAt = &next
+      }
+      res = append(res, item)
+    }
+    return nil
+  })
+  return res, err
+}
+
+// Enqueue пишет событие в outbox и ставит его доставку каждой подписке организации на этот тип.
+// Без подписок событие не сохраняется
+func (r *webhookRepo) Enqueue(ctx context.Context, eventType string, payload []byte) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  return r.s.write(ctx, func(d *data) error {
+    var hooks []string
+    for _, w := range d.webhooks {
+      if w.org == org && slices.Contains(w.EventTypes, eventType) {
+        hooks = append(hooks, w.ID)
+      }
+    }
+    if len(hooks) == 0 {
+      return nil
+    }
+    slices.Sort(hooks)
+    created := now()
+    d.outboxSeq++
+    d.outbox = append(d.outbox, outboxEvent{id: d.outboxSeq, org: org, eventType: eventType, payload: slices.Clone(payload), createdAt: created})
+    for _, hook := range hooks {
+      d.deliverSeq++
+      d.deliveries = append(d.deliveries, delivery{
+        id:            d.deliverSeq,
+        webhookID:     hook,
+        outboxID:      d.outboxSeq,
+        status:        dto.DeliveryPending,
+        nextAttemptAt: created,
+        createdAt:     created,
+      })
+    }
+    return nil
+  })
+}
+
+// Claim берет в работу до limit доставок, которым пора отправляться, по всем организациям.
+// На время lease доставка скрыта от следующих вызовов Claim
+func (r *webhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]dto.OutgoingWebhook, error) {
+  var res []dto.OutgoingWebhook
+  err := r.s.write(ctx, func(d *data) error {
+    current := now()
+    var due []int
+    for i, del := range d.deliveries {
+      if del.status == dto.DeliveryPending && !del.nextAttemptAt.After(current) {
+        due = append(due, i)
+      }
+    }
+    slices.SortStableFunc(due, func(a, b int) int {
+      return d.deliveries[a].nextAttemptAt.Compare(d.deliveries[b].nextAttemptAt)
+    })
+    for _, i := range due[:min(limit, len(due))] {
+      del := &d.deliveries[i]
+      del.attempts++
+      del.nextAttemptAt = current.Add(lease)
+      w := d.webhooks[del.webhookID]
+      event := d.outbox[slices.IndexFunc(d.outbox, func(e outboxEvent) bool { return e.id == del.outboxID })]
+      res = append(res, dto.OutgoingWebhook{
+        DeliveryID: del.id,
+        Attempts:   del.attempts,
+        URL:        w.URL,
+        Secret:     w.Secret,
+        Event: dto.WebhookEvent{
+          ID:             strconv.FormatInt(event.id, 10),
+          Type:           event.eventType,
+          OrganizationID: event.org,
+          CreatedAt:      event.createdAt,
+          Data:           json.RawMessage(event.payload),
+        },
+      })
+    }
+    return nil
+  })
+  return res, err
+}
+
+// update меняет доставку id, если она есть
+func (r *webhookRepo) update(ctx context.Context, id int64, fn func(del *delivery)) error {
+  return r.s.write(ctx, func(d *data) error {
+    if i := slices.IndexFunc(d.deliveries, func(del delivery) bool { return del.id == id }); i >= 0 {
+      fn(&d.deliveries[i])
+    }
+    return nil
+  })
+}
+
+func (r *webhookRepo) Delivered(ctx context.Context, id int64, status int) error {
+  return r.update(ctx, id, func(del *delivery) {
+    delivered := now()
+    del.status, del.responseStatus, del.lastError, del.deliveredAt = dto.DeliveryDelivered, &status, "", &delivered
+  })
+}
+
+// Retry сохраняет итог неудачной попытки и назначает следующую через delay
+func (r *webhookRepo) Retry(ctx context.Context, id int64, status *int, reason string, delay time.Duration) error {
+  return r.update(ctx, id, func(del *delivery) {
+    del.responseStatus, del.lastError, del.nextAttemptAt = status, reason, now().Add(delay)
+  })
+}
+
+// Fail завершает доставку без успеха, повторов больше не будет
+func (r *webhookRepo) Fail(ctx context.Context, id int64, status *int, reason string) error {
+  return r.update(ctx, id, func(del *delivery) {
+    del.status, del.responseStatus, del.lastError = dto.DeliveryFailed, status, reason
+  })
+}
+
+// Cleanup удаляет события старше retention вместе с журналом их доставок
+func (r *webhookRepo) Cleanup(ctx context.Context, retention time.Duration) (int64, error) {
+  var n int64
+  err := r.s.write(ctx, func(d *data) error {
+    border := now().Add(-retention)
+    removed := map[int64]bool{}
+    d.outbox = slices.DeleteFunc(d.outbox, func(e outboxEvent) bool {
+      removed[e.id] = e.createdAt.Before(border)
+      return removed[e.id]
+    })
+    d.deliveries = slices.DeleteFunc(d.deliveries, func(del delivery) bool { return removed[del.outboxID] })
+    for _, r := range removed {
+      if r {
+        n++
+      }
+    }
+    return nil
+  })
+  return n, err
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+                                        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                        name VARCHAR(255) NOT NULL,
+                                        person_id UUID,
+                                        key_hash CHAR(64) NOT NULL UNIQUE,
+                                        prefix VARCHAR(16) NOT NULL,
+                                        is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+                                        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                        last_used_at TIMESTAMP,
+                                        FOREIGN KEY (person_id) REFERENCES person (id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+DROP INDEX idx_person_manager_id;
+ALTER TABLE person DROP COLUMN manager_id;
+
+ALTER TABLE api_keys ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE api_keys SET is_admin = TRUE WHERE role = 'admin';
+ALTER TABLE api_keys DROP COLUMN role;
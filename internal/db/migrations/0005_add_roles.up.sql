@@ -0,0 +1,6 @@
+ALTER TABLE api_keys ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'self';
+UPDATE api_keys SET role = 'admin' WHERE is_admin;
+ALTER TABLE api_keys DROP COLUMN is_admin;
+
+ALTER TABLE person ADD COLUMN manager_id UUID REFERENCES person (id) ON DELETE SET NULL;
+CREATE INDEX idx_person_manager_id ON person(manager_id);
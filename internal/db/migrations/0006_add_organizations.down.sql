@@ -0,0 +1,19 @@
+DROP POLICY tenant_isolation ON timetask;
+ALTER TABLE timetask NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE timetask DISABLE ROW LEVEL SECURITY;
+DROP POLICY tenant_isolation ON tasks;
+ALTER TABLE tasks NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tasks DISABLE ROW LEVEL SECURITY;
+DROP POLICY tenant_isolation ON person;
+ALTER TABLE person NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE person DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX idx_person_organization_passport;
+CREATE UNIQUE INDEX idx_person_passport_number ON person(passport_number);
+ALTER TABLE person ADD CONSTRAINT person_passport_number_key UNIQUE (passport_number);
+
+ALTER TABLE api_keys DROP COLUMN organization_id;
+ALTER TABLE timetask DROP COLUMN organization_id;
+ALTER TABLE tasks DROP COLUMN organization_id;
+ALTER TABLE person DROP COLUMN organization_id;
+DROP TABLE organizations;
@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS organizations (
+                                             id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                             name VARCHAR(255) NOT NULL UNIQUE,
+                                             created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO organizations (id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'default');
+
+ALTER TABLE person ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE person ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE tasks ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE tasks ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE timetask ADD COLUMN organization_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES organizations (id);
+ALTER TABLE timetask ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE api_keys ADD COLUMN organization_id UUID REFERENCES organizations (id);
+
+CREATE INDEX idx_person_organization_id ON person(organization_id);
+CREATE INDEX idx_tasks_organization_id ON tasks(organization_id);
+CREATE INDEX idx_timetask_organization_id ON timetask(organization_id);
+
+-- паспорт уникален в пределах организации
+ALTER TABLE person DROP CONSTRAINT person_passport_number_key;
+DROP INDEX idx_person_passport_number;
+CREATE UNIQUE INDEX idx_person_organization_passport ON person(organization_id, passport_number);
+
+-- внутри транзакции видны только строки организации из app.organization_id,
+-- без установленной настройки фильтрация остается на запросах репозиториев
+ALTER TABLE person ENABLE ROW LEVEL SECURITY;
+ALTER TABLE person FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON person
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
+
+ALTER TABLE tasks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tasks FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tasks
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
+
+ALTER TABLE timetask ENABLE ROW LEVEL SECURITY;
+ALTER TABLE timetask FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON timetask
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS teams (
+                                     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                     organization_id UUID NOT NULL REFERENCES organizations (id),
+                                     name VARCHAR(255) NOT NULL,
+                                     manager_id UUID REFERENCES person (id) ON DELETE SET NULL,
+                                     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                     UNIQUE (organization_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+                                            team_id UUID NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+                                            person_id UUID NOT NULL REFERENCES person (id) ON DELETE CASCADE,
+                                            PRIMARY KEY (team_id, person_id)
+);
+
+CREATE INDEX idx_teams_manager_id ON teams(manager_id);
+CREATE INDEX idx_team_members_person_id ON team_members(person_id);
+
+ALTER TABLE teams ENABLE ROW LEVEL SECURITY;
+ALTER TABLE teams FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON teams
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
@@ -0,0 +1,4 @@
+DROP INDEX idx_tasks_person_created_id;
+DROP INDEX idx_person_org_surname_name_id;
+
+ALTER TABLE tasks DROP COLUMN created_at;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_person_org_surname_name_id ON person(organization_id, surname, name, id);
+CREATE INDEX idx_tasks_person_created_id ON tasks(idperson, created_at, idtask);
@@ -0,0 +1,6 @@
+DROP INDEX idx_tasks_person_active;
+DROP INDEX idx_person_org_created_id;
+DROP INDEX idx_person_org_lower_name;
+DROP INDEX idx_person_org_lower_surname;
+
+ALTER TABLE person DROP COLUMN created_at;
@@ -0,0 +1,6 @@
+ALTER TABLE person ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_person_org_lower_surname ON person(organization_id, lower(surname) text_pattern_ops);
+CREATE INDEX idx_person_org_lower_name ON person(organization_id, lower(name) text_pattern_ops);
+CREATE INDEX idx_person_org_created_id ON person(organization_id, created_at, id);
+CREATE INDEX idx_tasks_person_active ON tasks(idperson) WHERE task_status = 'work';
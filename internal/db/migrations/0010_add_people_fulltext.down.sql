@@ -0,0 +1,5 @@
+DROP INDEX idx_person_search_trgm;
+DROP INDEX idx_person_search_vector;
+
+ALTER TABLE person DROP COLUMN search_vector;
+ALTER TABLE person DROP COLUMN search_text;
@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE person ADD COLUMN search_text TEXT GENERATED ALWAYS AS (
+    lower(surname || ' ' || name || ' ' || coalesce(patronymic, '') || ' ' || address)
+) STORED;
+ALTER TABLE person ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', surname || ' ' || name || ' ' || coalesce(patronymic, '')), 'A') ||
+    setweight(to_tsvector('simple', address), 'B')
+) STORED;
+
+CREATE INDEX idx_person_search_vector ON person USING GIN (search_vector);
+CREATE INDEX idx_person_search_trgm ON person USING GIN (search_text gin_trgm_ops);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS audit_log;
+
+DROP INDEX idx_person_address_trgm;
+
+DELETE FROM person WHERE deleted_at IS NOT NULL;
+DROP INDEX idx_person_organization_passport;
+CREATE UNIQUE INDEX idx_person_organization_passport ON person(organization_id, passport_number);
+
+ALTER TABLE person DROP COLUMN deleted_at;
@@ -0,0 +1,25 @@
+ALTER TABLE person ADD COLUMN deleted_at TIMESTAMP;
+
+-- паспорт удаленной записи можно выдать заново
+DROP INDEX idx_person_organization_passport;
+CREATE UNIQUE INDEX idx_person_organization_passport ON person(organization_id, passport_number) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_person_address_trgm ON person USING GIN (address gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+                                         id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                         organization_id UUID NOT NULL REFERENCES organizations (id),
+                                         actor VARCHAR(255) NOT NULL,
+                                         action VARCHAR(255) NOT NULL,
+                                         entity VARCHAR(255) NOT NULL,
+                                         entity_id UUID NOT NULL,
+                                         details JSONB NOT NULL DEFAULT '{}',
+                                         created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(organization_id, entity, entity_id);
+
+ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_log FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_log
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN version;
+ALTER TABLE person DROP COLUMN version;
@@ -0,0 +1,2 @@
+ALTER TABLE person ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
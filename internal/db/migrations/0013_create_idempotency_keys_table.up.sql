@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                                organization_id UUID NOT NULL REFERENCES organizations (id),
+                                                actor VARCHAR(255) NOT NULL,
+                                                key VARCHAR(255) NOT NULL,
+                                                request_hash VARCHAR(64) NOT NULL,
+                                                status INTEGER,
+                                                headers JSONB,
+                                                response BYTEA,
+                                                created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                PRIMARY KEY (organization_id, actor, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
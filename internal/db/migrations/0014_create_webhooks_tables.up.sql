@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+                                        id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+                                        organization_id UUID NOT NULL REFERENCES organizations (id),
+                                        url TEXT NOT NULL,
+                                        secret VARCHAR(255) NOT NULL,
+                                        event_types TEXT[] NOT NULL,
+                                        created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhooks_organization_id ON webhooks(organization_id);
+
+-- события пишутся в одной транзакции с изменением, рассылку делает воркер
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+                                              id BIGSERIAL PRIMARY KEY,
+                                              organization_id UUID NOT NULL REFERENCES organizations (id),
+                                              event_type VARCHAR(64) NOT NULL,
+                                              payload JSONB NOT NULL,
+                                              created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                                                  id BIGSERIAL PRIMARY KEY,
+                                                  webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+                                                  outbox_id BIGINT NOT NULL REFERENCES webhook_outbox (id) ON DELETE CASCADE,
+                                                  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+                                                  attempts INTEGER NOT NULL DEFAULT 0,
+                                                  next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+                                                  response_status INTEGER,
+                                                  last_error TEXT,
+                                                  delivered_at TIMESTAMP,
+                                                  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
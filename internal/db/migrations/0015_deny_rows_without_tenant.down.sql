@@ -0,0 +1,19 @@
+DROP POLICY tenant_isolation ON person;
+CREATE POLICY tenant_isolation ON person
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON tasks;
+CREATE POLICY tenant_isolation ON tasks
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON timetask;
+CREATE POLICY tenant_isolation ON timetask
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON teams;
+CREATE POLICY tenant_isolation ON teams
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON audit_log;
+CREATE POLICY tenant_isolation ON audit_log
+    USING (COALESCE(current_setting('app.organization_id', true), '') = '' OR organization_id::text = current_setting('app.organization_id', true));
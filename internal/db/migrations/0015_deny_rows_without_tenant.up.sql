@@ -0,0 +1,22 @@
+-- без установленной app.organization_id строки не видны: запрос, забывший организацию,
+-- получает пустой результат, а не данные всех организаций. Обслуживание базы
+-- открывает все организации явным значением '*'
+DROP POLICY tenant_isolation ON person;
+CREATE POLICY tenant_isolation ON person
+    USING (current_setting('app.organization_id', true) = '*' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON tasks;
+CREATE POLICY tenant_isolation ON tasks
+    USING (current_setting('app.organization_id', true) = '*' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON timetask;
+CREATE POLICY tenant_isolation ON timetask
+    USING (current_setting('app.organization_id', true) = '*' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON teams;
+CREATE POLICY tenant_isolation ON teams
+    USING (current_setting('app.organization_id', true) = '*' OR organization_id::text = current_setting('app.organization_id', true));
+
+DROP POLICY tenant_isolation ON audit_log;
+CREATE POLICY tenant_isolation ON audit_log
+    USING (current_setting('app.organization_id', true) = '*' OR organization_id::text = current_setting('app.organization_id', true));
@@ -0,0 +1,8 @@
+// Package migrations содержит миграции схемы, встроенные в бинарник,
+// поэтому сервер и ttadmin не зависят от каталога запуска
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
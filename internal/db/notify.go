@@ -0,0 +1,50 @@
+package db
+
+import (
+  "context"
+  "fmt"
+  "github.com/lib/pq"
+  "log/slog"
+  "time"
+)
+
+// eventsChannel канал NOTIFY, через который экземпляры сервиса обмениваются событиями
+const eventsChannel = "timetracker_events"
+
+// Notify отправляет событие всем экземплярам, слушающим канал событий
+func (d *DbRepo) Notify(ctx context.Context, payload []byte) error {
+  _, err := d.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", eventsChannel, string(payload))
+  if err != nil {
+    return fmt.Errorf("ошибка отправки уведомления: %v", err)
+  }
+  return nil
+}
+
+// Listen передает в receive события канала до отмены ctx, при обрыве соединение восстанавливается
+func (d *DbRepo) Listen(ctx context.Context, log *slog.Logger, receive func(payload []byte)) error {
+  listener := pq.NewListener(d.connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+    if err != nil {
+      log.Error("ошибка соединения LISTEN", slog.String("error", err.Error()))
+    }
+  })
+  defer listener.Close()
+  if err := listener.Listen(eventsChannel); err != nil {
+    return fmt.Errorf("ошибка подписки на канал %s: %v", eventsChannel, err)
+  }
+
+  for {
+    select {
+    case <-ctx.Done():
+      return nil
+    case n := <-listener.Notify:
+      // nil приходит после переподключения, пропущенные за обрыв события не восстановить
+      if n != nil {
+        receive([]byte(n.Extra))
+      }
+    case <-time.After(90 * time.Second):
+      go func() {
+        _ = listener.Ping()
+      }()
+    }
+  }
+}
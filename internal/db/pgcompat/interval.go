@@ -0,0 +1,54 @@
+// Package pgcompat поведение типов и функций Postgres, на которое опираются ответы API,
+// для хранилищ без Postgres: интервалы, TIMESTAMP и похожесть строк pg_trgm
+package pgcompat
+
+import (
+  "cmp"
+  "fmt"
+  "strings"
+  "time"
+)
+
+const microsPerDay = int64(24 * time.Hour / time.Microsecond)
+
+// Interval длительность в представлении интервала Postgres: сутки и время хранятся
+// отдельно, SUM складывает их по отдельности, и часов может набраться больше 24
+type Interval struct {
+  days   int64
+  micros int64
+}
+
+// Between разность моментов как разность TIMESTAMP в базе: полные сутки уходят в дни
+func Between(start, end time.Time) Interval {
+  d := end.Sub(start).Microseconds()
+  return Interval{days: d / microsPerDay, micros: d % microsPerDay}
+}
+
+func (i Interval) Add(o Interval) Interval {
+  return Interval{days: i.days + o.days, micros: i.micros + o.micros}
+}
+
+func (i Interval) Compare(o Interval) int {
+  return cmp.Compare(i.days*microsPerDay+i.micros, o.days*microsPerDay+o.micros)
+}
+
+// String формат вывода интервала базы по умолчанию: "1 day 02:03:04.5"
+func (i Interval) String() string {
+  var parts []string
+  switch i.days {
+  case 0:
+  case 1:
+    parts = append(parts, "1 day")
+  default:
+    parts = append(parts, fmt.Sprintf("%d days", i.days))
+  }
+  if i.micros != 0 || i.days == 0 {
+    secs := i.micros / 1e6
+    clock := fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+    if frac := i.micros % 1e6; frac != 0 {
+      clock += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+    }
+    parts = append(parts, clock)
+  }
+  return strings.Join(parts, " ")
+}
@@ -0,0 +1,103 @@
+package pgcompat
+
+import (
+  "slices"
+  "strings"
+  "unicode"
+)
+
+// Words слова строки в нижнем регистре
+func Words(s string) []string {
+  return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+    return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+  })
+}
+
+// trigrams триграммы строки по правилам pg_trgm: каждое слово дополняется двумя пробелами слева и одним справа
+func trigrams(s string) map[string]bool {
+  res := map[string]bool{}
+  for _, word := range Words(s) {
+    r := []rune("  " + word + " ")
+    for i := 0; i+3 <= len(r); i++ {
+      res[string(r[i:i+3])] = true
+    }
+  }
+  return res
+}
+
+// Similarity похожесть строк как similarity из pg_trgm: доля общих триграмм
+func Similarity(a, b string) float64 {
+  ta, tb := trigrams(a), trigrams(b)
+  common := 0
+  for t := range ta {
+    if tb[t] {
+      common++
+    }
+  }
+  union := len(ta) + len(tb) - common
+  if union == 0 {
+    return 0
+  }
+  return float64(common) / float64(union)
+}
+
+// WordSimilarity доля триграмм q, найденных в лучшем слове text. Приближает word_similarity
+// из pg_trgm, которая сравнивает q с отрезком текста
+func WordSimilarity(q, text string) float64 {
+  tq := trigrams(q)
+  if len(tq) == 0 {
+    return 0
+  }
+  best := 0.0
+  for _, word := range Words(text) {
+    tw := trigrams(word)
+    common := 0
+    for t := range tq {
+      if tw[t] {
+        common++
+      }
+    }
+    best = max(best, float64(common)/float64(len(tq)))
+  }
+  return best
+}
+
+// PrefixMatch каждое слово q начинает какое-то слово text, как запрос to_tsquery со словами "слово:*"
+func PrefixMatch(q, text string) bool {
+  qWords, textWords := Words(q), Words(text)
+  if len(qWords) == 0 {
+    return false
+  }
+  for _, qw := range qWords {
+    if !slices.ContainsFunc(textWords, func(w string) bool { return strings.HasPrefix(w, qw) }) {
+      return false
+    }
+  }
+  return true
+}
+
+// Highlight выделяет тегом <b> слова text, начинающиеся с одного из слов q
+func Highlight(text, q string) string {
+  qWords := Words(q)
+  var b strings.Builder
+  var word []rune
+  flush := func() {
+    lower := strings.ToLower(string(word))
+    if len(word) > 0 && slices.ContainsFunc(qWords, func(qw string) bool { return strings.HasPrefix(lower, qw) }) {
+      b.WriteString("<b>" + string(word) + "</b>")
+    } else {
+      b.WriteString(string(word))
+    }
+    word = word[:0]
+  }
+  for _, r := range text {
+    if unicode.IsLetter(r) || unicode.IsDigit(r) {
+      word = append(word, r)
+      continue
+    }
+    flush()
+    b.WriteRune(r)
+  }
+  flush()
+  return b.String()
+}
@@ -0,0 +1,28 @@
+package pgcompat
+
+import (
+  "fmt"
+  "strings"
+  "time"
+)
+
+// Wall отбрасывает часовой пояс, оставляя показания часов с точностью до микросекунд,
+// как приведение к TIMESTAMP
+func Wall(t time.Time) time.Time {
+  return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000*1000, time.UTC)
+}
+
+// Now текущее время в том виде, в каком его хранит колонка TIMESTAMP
+func Now() time.Time {
+  return Wall(time.Now())
+}
+
+// ParseTimestamp разбирает границу периода так же, как приведение строки к TIMESTAMP в базе
+func ParseTimestamp(s string) (time.Time, error) {
+  for _, layout := range []string{"2006-01-02", "2006-01-02 15:04:05", "2006-01-02T15:04:05", time.RFC3339Nano} {
+    if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+      return Wall(t), nil
+    }
+  }
+  return time.Time{}, fmt.Errorf("неверный формат времени %q", s)
+}
@@ -0,0 +1,171 @@
+package query
+
+import (
+  "fmt"
+  "github.com/lib/pq"
+  "strings"
+)
+
+// Order колонка сортировки, Column может быть выражением
+type Order struct {
+  Column string
+  Desc   bool
+}
+
+// Dialect SQL базы, для которой собирается запрос
+type Dialect int
+
+const (
+  Postgres Dialect = iota
+  // SQLite параметры ?n, список значений вместо массива, LIKE с явным экранированием
+  SQLite
+)
+
+// Builder собирает условия WHERE с позиционными параметрами $n (?n для SQLite).
+// Условие добавляется только для заданного фильтра, поэтому планировщик видит
+// простые предикаты и может использовать индексы
+type Builder struct {
+  Dialect Dialect
+  conds   []string
+  args    []interface{}
+}
+
+// Arg добавляет параметр и возвращает его плейсхолдер
+func (b *Builder) Arg(value interface{}) string {
+  b.args = append(b.args, value)
+  if b.Dialect == SQLite {
+    return fmt.Sprintf("?%d", len(b.args))
+  }
+  return fmt.Sprintf("$%d", len(b.args))
+}
+
+// Where добавляет условие, каждый ? в cond заменяется очередным параметром
+func (b *Builder) Where(cond string, values ...interface{}) {
+  for _, value := range values {
+    cond = strings.Replace(cond, "?", b.Arg(value), 1)
+  }
+  b.conds = append(b.conds, cond)
+}
+
+// Match отбор по текстовой колонке: значение со * сравнивается по шаблону
+// без учета регистра (иван* - начинается с "иван"), без * - на равенство
+func (b *Builder) Match(column, value string) {
+  if value == "" {
+    return
+  }
+  b.conds = append(b.conds, b.match(column, value))
+}
+
+// MatchAny как Match, но значения через запятую объединяются через ИЛИ,
+// точные значения уходят в один = ANY
+func (b *Builder) MatchAny(column, value string) {
+  var exact, conds []string
+  for _, part := range strings.Split(value, ",") {
+    part = strings.TrimSpace(part)
+    if part == "" {
+      continue
+    }
+    if strings.Contains(part, "*") {
+      conds = append(conds, b.match(column, part))
+    } else {
+      exact = append(exact, part)
+    }
+  }
+  switch len(exact) {
+  case 0:
+  case 1:
+    conds = append(conds, column+" = "+b.Arg(exact[0]))
+  default:
+    if b.Dialect == SQLite {
+      params := make([]string, len(exact))
+      for i, value := range exact {
+        params[i] = b.Arg(value)
+      }
+      conds = append(conds, column+" IN ("+strings.Join(params, ", ")+")")
+      break
+    }
+    conds = append(conds, column+" = ANY("+b.Arg(pq.Array(exact))+")")
+  }
+  switch len(conds) {
+  case 0:
+  case 1:
+    b.conds = append(b.conds, conds[0])
+  default:
+    b.conds = append(b.conds, "("+strings.Join(conds, " OR ")+")")
+  }
+}
+
+func (b *Builder) match(column, value string) string {
+  if !strings.Contains(value, "*") {
+    return column + " = " + b.Arg(value)
+  }
+  escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "*", "%")
+  cond := "lower(" + column + ") LIKE " + b.Arg(escaper.Replace(strings.ToLower(value)))
+  if b.Dialect == SQLite {
+    // в SQLite у LIKE нет экранирующего символа по умолчанию
+    cond += ` ESCAPE '\'`
+  }
+  return cond
+}
+
+// After условие keyset-пагинации: строка идет после строки со значениями values
+// в порядке orders. При одном направлении сортировки сравниваются кортежи
+func (b *Builder) After(orders []Order, values []interface{}) {
+  sameDir := true
+  for _, order := range orders {
+    sameDir = sameDir && order.Desc == orders[0].Desc
+  }
+  if sameDir {
+    columns := make([]string, len(orders))
+    params := make([]string, len(orders))
+    for i, order := range orders {
+      columns[i] = order.Column
+      params[i] = b.Arg(values[i])
+    }
+    op := ">"
+    if orders[0].Desc {
+      op = "<"
+    }
+    b.conds = append(b.conds, "("+strings.Join(columns, ", ")+") "+op+" ("+strings.Join(params, ", ")+")")
+    return
+  }
+
+  var alts []string
+  for i, order := range orders {
+    var parts []string
+    for j := 0; j < i; j++ {
+      parts = append(parts, orders[j].Column+" = "+b.Arg(values[j]))
+    }
+    op := " > "
+    if order.Desc {
+      op = " < "
+    }
+    parts = append(parts, order.Column+op+b.Arg(values[i]))
+    alts = append(alts, "("+strings.Join(parts, " AND ")+")")
+  }
+  b.conds = append(b.conds, "("+strings.Join(alts, " OR ")+")")
+}
+
+// Sql возвращает WHERE со всеми условиями или пустую строку
+func (b *Builder) Sql() string {
+  if len(b.conds) == 0 {
+    return ""
+  }
+  return "WHERE " + strings.Join(b.conds, "\n                AND ")
+}
+
+func (b *Builder) Args() []interface{} {
+  return b.args
+}
+
+// OrderBy возвращает ORDER BY по orders
+func OrderBy(orders []Order) string {
+  parts := make([]string, len(orders))
+  for i, order := range orders {
+    parts[i] = order.Column
+    if order.Desc {
+      parts[i] += " DESC"
+    }
+  }
+  return "ORDER BY " + strings.Join(parts, ", ")
+}
@@ -2,49 +2,76 @@ package db
 
 import (
   "context"
+  "fmt"
   "github.com/jmoiron/sqlx"
+  "time"
   "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
 )
 
+// DbRepo хранилище в Postgres, транзакции Store выполняет он сам
 type DbRepo struct {
-  db       *sqlx.DB
-  People   repo.IPeopleRepo
-  Task     repo.ITaskRepo
-  TimeTask repo.ITimeTaskRepo
+  Store
+  db      *sqlx.DB
+  connStr string
 }
 
+// New подключается к базе и применяет миграции
 func New(connStr string) *DbRepo {
-  res := DbRepo{db: newDb(connStr)}
+  return build(newDb(connStr), connStr)
+}
+
+// Open подключается к базе без миграций, для обслуживания из ttadmin
+func Open(connStr string) (*DbRepo, error) {
+  conn, err := sqlx.Open("postgres", connStr)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка подключения к базе: %v", err)
+  }
+  if err = conn.Ping(); err != nil {
+    _ = conn.Close()
+    return nil, fmt.Errorf("ошибка подключения к базе: %v", err)
+  }
+  return build(conn, connStr), nil
+}
+
+func build(conn *sqlx.DB, connStr string) *DbRepo {
+  res := DbRepo{db: conn, connStr: connStr}
+  res.ITxRunner = &res
   res.People = repo.NewPeopleRepo(res.db)
   res.Task = repo.NewTaskRepo(res.db)
   res.TimeTask = repo.NewTimeTaskRepo(res.db)
+  res.ApiKey = repo.NewApiKeyRepo(res.db)
+  res.Org = repo.NewOrganizationRepo(res.db)
+  res.Team = repo.NewTeamRepo(res.db)
+  res.Audit = repo.NewAuditRepo(res.db)
+  res.Idem = repo.NewIdempotencyRepo(res.db)
+  res.Webhook = repo.NewWebhookRepo(res.db)
   return &res
 }
 
-func (d *DbRepo) Begin(ctx context.Context) (context.Context, error) {
-  tx, err := d.db.BeginTxx(ctx, nil)
-  if err != nil {
-    return ctx, err
-  }
-  ctx = context.WithValue(ctx, "tx", tx)
+// SetPool ограничивает пул соединений: открытые (0 - без предела), простаивающие
+// и срок жизни соединения (0 - без срока)
+func (d *DbRepo) SetPool(maxOpen, maxIdle int, lifetime time.Duration) {
+  d.db.SetMaxOpenConns(maxOpen)
+  d.db.SetMaxIdleConns(maxIdle)
+  d.db.SetConnMaxLifetime(lifetime)
+}
 
-  return ctx, nil
+func (d *DbRepo) Close() error {
+  return d.db.Close()
 }
 
-func (d *DbRepo) End(ctx context.Context, err error) {
-  tx, ok := ctx.Value("tx").(*sqlx.Tx)
-  if !ok {
-    return
-  }
-  if err != nil {
-    err := tx.Rollback()
-    if err != nil {
-      return
-    }
-  } else {
-    err := tx.Commit()
-    if err != nil {
-      return
-    }
-  }
+// WithinTx выполняет fn в транзакции, см. ITxRunner. Строки транзакции ограничены
+// организацией из ctx политиками RLS
+func (d *DbRepo) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+  return repo.WithinTx(ctx, d.db, func(ctx context.Context, tx *sqlx.Tx) error {
+    // ограничение строк политиками RLS действует до конца транзакции
+    return repo.SetTenant(ctx, tx, utils.TenantFromCtx(ctx))
+  }, fn)
+}
+
+// AfterCommit выполняет fn после фиксации транзакции из ctx, при откате fn не вызывается.
+// Вне транзакции fn выполняется сразу
+func (d *DbRepo) AfterCommit(ctx context.Context, fn func()) {
+  repo.AfterCommit(ctx, d.db, fn)
 }
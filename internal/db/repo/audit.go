@@ -0,0 +1,42 @@
+package repo
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "timetracker/internal/bl/dto"
+)
+
+type IAuditRepo interface {
+  Write(ctx context.Context, record *dto.AuditRecord) (string, error)
+}
+
+type auditRepo struct {
+  db *sqlx.DB
+}
+
+func NewAuditRepo(db *sqlx.DB) IAuditRepo {
+  return &auditRepo{db: db}
+}
+
+// Write сохраняет запись аудита, внутри транзакции пишет в нее
+func (a *auditRepo) Write(ctx context.Context, record *dto.AuditRecord) (string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", err
+  }
+  details, err := json.Marshal(record.Details)
+  if err != nil {
+    return "", fmt.Errorf("ошибка сериализации записи аудита: %v", err)
+  }
+  query := `INSERT INTO audit_log (organization_id, actor, action, entity, entity_id, details)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+  var id string
+  err = Conn(ctx, a.db).GetContext(ctx, &id, query, org, record.Actor, record.Action, record.Entity, record.EntityID, details)
+  if err != nil {
+    return "", fmt.Errorf("ошибка записи аудита: %v", err)
+  }
+  return id, nil
+}
@@ -0,0 +1,6 @@
+package repo
+
+import "errors"
+
+// ErrStale запись изменена или удалена после чтения, проверка версии не прошла
+var ErrStale = errors.New("версия записи устарела")
@@ -0,0 +1,113 @@
+package repo
+
+import (
+  "context"
+  "database/sql"
+  "encoding/json"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "timetracker/internal/bl/dto"
+)
+
+// IdempotencyKey запись ключа идемпотентности, Status пуст, пока запрос выполняется
+type IdempotencyKey struct {
+  RequestHash string        `db:"request_hash"`
+  Status      sql.NullInt64 `db:"status"`
+  Headers     []byte        `db:"headers"`
+  Response    []byte        `db:"response"`
+}
+
+type IIdempotencyRepo interface {
+  Claim(ctx context.Context, actor, key, hash string) (bool, error)
+  Get(ctx context.Context, actor, key string) (string, *dto.IdempotentResponse, error)
+  Complete(ctx context.Context, actor, key string, resp *dto.IdempotentResponse) error
+  Release(ctx context.Context, actor, key string) error
+}
+
+type idempotencyRepo struct {
+  db *sqlx.DB
+}
+
+func NewIdempotencyRepo(db *sqlx.DB) IIdempotencyRepo {
+  return &idempotencyRepo{db: db}
+}
+
+// Claim занимает ключ под новый запрос, false если ключ уже использован.
+// Ключи старше суток считаются свободными
+func (i *idempotencyRepo) Claim(ctx context.Context, actor, key, hash string) (bool, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return false, err
+  }
+  _, err = Conn(ctx, i.db).ExecContext(ctx, `DELETE FROM idempotency_keys
+                                   WHERE organization_id = $1 AND actor = $2 AND key = $3
+                                     AND created_at < NOW() - INTERVAL '24 hours'`, org, actor, key)
+  if err != nil {
+    return false, fmt.Errorf("ошибка удаления устаревшего ключа идемпотентности: %v", err)
+  }
+  result, err := Conn(ctx, i.db).ExecContext(ctx, `INSERT INTO idempotency_keys (organization_id, actor, key, request_hash)
+                                         VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`, org, actor, key, hash)
+  if err != nil {
+    return false, fmt.Errorf("ошибка сохранения ключа идемпотентности: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return false, fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  return rowsAffected == 1, nil
+}
+
+// Get возвращает хеш запроса ключа и сохраненный ответ, nil пока запрос выполняется
+func (i *idempotencyRepo) Get(ctx context.Context, actor, key string) (string, *dto.IdempotentResponse, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", nil, err
+  }
+  var record IdempotencyKey
+  err = Conn(ctx, i.db).GetContext(ctx, &record, `SELECT request_hash, status, headers, response FROM idempotency_keys
+                                        WHERE organization_id = $1 AND actor = $2 AND key = $3`, org, actor, key)
+  if err != nil {
+    return "", nil, fmt.Errorf("ошибка получения ключа идемпотентности: %v", err)
+  }
+  if !record.Status.Valid {
+    return record.RequestHash, nil, nil
+  }
+  resp := &dto.IdempotentResponse{Status: int(record.Status.Int64), Body: record.Response}
+  if len(record.Headers) > 0 {
+    if err = json.Unmarshal(record.Headers, &resp.Headers); err != nil {
+      return "", nil, fmt.Errorf("ошибка разбора заголовков ответа: %v", err)
+    }
+  }
+  return record.RequestHash, resp, nil
+}
+
+func (i *idempotencyRepo) Complete(ctx context.Context, actor, key string, resp *dto.IdempotentResponse) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  headers, err := json.Marshal(resp.Headers)
+  if err != nil {
+    return fmt.Errorf("ошибка сериализации заголовков ответа: %v", err)
+  }
+  _, err = Conn(ctx, i.db).ExecContext(ctx, `UPDATE idempotency_keys SET status = $4, headers = $5, response = $6
+                                   WHERE organization_id = $1 AND actor = $2 AND key = $3`,
+    org, actor, key, resp.Status, headers, resp.Body)
+  if err != nil {
+    return fmt.Errorf("ошибка сохранения ответа: %v", err)
+  }
+  return nil
+}
+
+// Release освобождает ключ, чтобы запрос можно было повторить
+func (i *idempotencyRepo) Release(ctx context.Context, actor, key string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  _, err = Conn(ctx, i.db).ExecContext(ctx, "DELETE FROM idempotency_keys WHERE organization_id = $1 AND actor = $2 AND key = $3", org, actor, key)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления ключа идемпотентности: %v", err)
+  }
+  return nil
+}
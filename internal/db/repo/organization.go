@@ -0,0 +1,62 @@
+package repo
+
+import (
+  "context"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type Organization struct {
+  Id        string    `db:"id"`
+  Name      string    `db:"name"`
+  CreatedAt time.Time `db:"created_at"`
+}
+
+func (o *Organization) toDTO() *dto.Organization {
+  if o == nil {
+    return nil
+  }
+  return &dto.Organization{
+    ID:        o.Id,
+    Name:      o.Name,
+    CreatedAt: o.CreatedAt,
+  }
+}
+
+type IOrganizationRepo interface {
+  CreateOrganization(ctx context.Context, name string) (*dto.Organization, error)
+  GetOrganizations(ctx context.Context) ([]dto.Organization, error)
+}
+
+type organizationRepo struct {
+  db *sqlx.DB
+}
+
+func NewOrganizationRepo(db *sqlx.DB) IOrganizationRepo {
+  return &organizationRepo{db: db}
+}
+
+func (o *organizationRepo) CreateOrganization(ctx context.Context, name string) (*dto.Organization, error) {
+  query := `INSERT INTO organizations (name) VALUES ($1) RETURNING id, name, created_at`
+  var org Organization
+  err := Conn(ctx, o.db).GetContext(ctx, &org, query, name)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  return org.toDTO(), nil
+}
+
+func (o *organizationRepo) GetOrganizations(ctx context.Context) ([]dto.Organization, error) {
+  var orgs []Organization
+  err := Conn(ctx, o.db).SelectContext(ctx, &orgs, "SELECT id, name, created_at FROM organizations ORDER BY name")
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Organization, 0, len(orgs))
+  for _, org := range orgs {
+    res = append(res, *org.toDTO())
+  }
+  return res, nil
+}
@@ -5,29 +5,47 @@ import (
   "database/sql"
   "fmt"
   "github.com/jmoiron/sqlx"
-  "log/slog"
+  "github.com/lib/pq"
+  "slices"
+  "strings"
+  "time"
   "timetracker/internal/bl/dto"
+  "timetracker/internal/db/query"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+  "timetracker/internal/utils/cursor"
+  "unicode"
 )
 
 type Person struct {
-  Id             string `json:"id" db:"id"`
-  Surname        string `json:"surname" db:"surname"`
-  Name           string `json:"name" db:"name"`
-  Patronymic     string `json:"patronymic" db:"patronymic"`
-  Address        string `json:"address" db:"address"`
-  PassportNumber string `json:"passportNumber" db:"passport_number"`
+  Id             string         `json:"id" db:"id"`
+  Surname        string         `json:"surname" db:"surname"`
+  Name           string         `json:"name" db:"name"`
+  Patronymic     sql.NullString `json:"patronymic" db:"patronymic"`
+  Address        string         `json:"address" db:"address"`
+  PassportNumber string         `json:"passportNumber" db:"passport_number"`
+  ManagerId      *string        `json:"manager_id" db:"manager_id"`
+  OrganizationId string         `json:"-" db:"organization_id"`
+  CreatedAt      time.Time      `json:"-" db:"created_at"`
+  Version        int            `json:"version" db:"version"`
 }
 
 func (p *Person) toDTO() *dto.Person {
   if p == nil {
     return nil
   }
+  var managerId string
+  if p.ManagerId != nil {
+    managerId = *p.ManagerId
+  }
   return &dto.Person{
-    ID: p.Id,
+    ID:        p.Id,
+    ManagerID: managerId,
+    Version:   p.Version,
     People: dto.People{
       Surname:    p.Surname,
       Name:       p.Name,
-      Patronymic: p.Patronymic,
+      Patronymic: p.Patronymic.String,
       Address:    p.Address,
     },
     Passport: dto.Passport{
@@ -45,8 +63,14 @@ func (p *Person) fromDTO(model *dto.Person) *Person {
   p.Surname = model.Surname
   p.Address = model.Address
   p.Name = model.Name
-  p.Patronymic = model.Patronymic
+  p.Patronymic = sql.NullString{String: model.Patronymic, Valid: model.Patronymic != ""}
   p.PassportNumber = model.PassportNumber
+  p.Version = model.Version
+  p.ManagerId = nil
+  if model.ManagerID != "" {
+    managerId := model.ManagerID
+    p.ManagerId = &managerId
+  }
   return p
 }
 
@@ -54,9 +78,15 @@ type IPeopleRepo interface {
   GetByPassport(ctx context.Context, passport string) (*dto.Person, error)
   GetByUUID(ctx context.Context, uuid string) (*dto.Person, error)
   CreatePerson(ctx context.Context, person *dto.Person) (string, error)
-  DeleteByPerson(ctx context.Context, uuid string) error
+  DeleteByPerson(ctx context.Context, uuid string, version int) error
   UpdatePerson(ctx context.Context, person *dto.Person) (*dto.Person, error)
-  GetPeople(ctx context.Context, filter *dto.Person, offset, limit int) ([]dto.Person, int, error)
+  GetPeople(ctx context.Context, filter *dto.PeopleFilter, page dto.PageRequest) ([]dto.Person, dto.PageInfo, error)
+  SearchPeople(ctx context.Context, filter *dto.PeopleFilter, q string, limit int) ([]dto.PersonMatch, error)
+  FindDuplicates(ctx context.Context, threshold float64, limit int) ([]dto.Duplicate, error)
+  MergePerson(ctx context.Context, sourceID, targetID string) (int64, error)
+  IsManagedBy(ctx context.Context, uuid, managerUUID string) (bool, error)
+  GetByUUIDs(ctx context.Context, uuids []string) ([]dto.Person, error)
+  ManagedAmong(ctx context.Context, uuids []string, managerUUID string) ([]string, error)
 }
 
 type peopleRepo struct {
@@ -69,49 +99,52 @@ func NewPeopleRepo(db *sqlx.DB) IPeopleRepo {
 
 // GetByPassport ищет человека по номеру паспорта
 func (p *peopleRepo) GetByPassport(ctx context.Context, passport string) (*dto.Person, error) {
-  query := "SELECT surname, name, patronymic, address, passport_number FROM person WHERE passport_number = $1"
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := "SELECT id, surname, name, patronymic, address, passport_number, manager_id, version FROM person WHERE passport_number = $1 AND organization_id = $2 AND deleted_at IS NULL"
   var person Person
-  fmt.Println(ctx)
-  err := p.db.GetContext(ctx, &person, query, passport)
+  err = Conn(ctx, p.db).GetContext(ctx, &person, query, passport, org)
   if err != nil {
     if err == sql.ErrNoRows {
       return nil, nil
     }
     return nil, fmt.Errorf("ошибка бд: %v", err)
   }
-  fmt.Println(person.Id)
   return person.toDTO(), nil
 }
 
 // CreatePerson создает новую запись о человеке в базе данных
 func (p *peopleRepo) CreatePerson(ctx context.Context, person *dto.Person) (string, error) {
-  query := `INSERT INTO person (surname, name, patronymic, address, passport_number) 
-	          VALUES (:surname, :name, :patronymic, :address, :passport_number) 
-	          RETURNING id`
+  query := `INSERT INTO person (surname, name, patronymic, address, passport_number, manager_id, organization_id) 
+	          VALUES (:surname, :name, :patronymic, :address, :passport_number, :manager_id, :organization_id) 
+	          RETURNING id, version`
 
-  var id string
-  var per Person
-
-  rows, err := p.db.NamedQueryContext(ctx, query, per.fromDTO(person))
+  org, err := tenant(ctx)
   if err != nil {
-    return "", fmt.Errorf("ошибка вставки данных в базу: %v", err)
+    return "", err
   }
-  defer rows.Close()
+  var per, created Person
+  per.OrganizationId = org
 
-  for rows.Next() {
-    if err := rows.Scan(&id); err != nil {
-      return "", fmt.Errorf("ошибка при получении ID: %v", err)
-    }
+  if err = namedGet(ctx, Conn(ctx, p.db), &created, query, per.fromDTO(person)); err != nil {
+    return "", fmt.Errorf("ошибка вставки данных в базу: %v", err)
   }
+  person.Version = created.Version
 
-  return id, nil
+  return created.Id, nil
 }
 
-// DeleteByPerson удаляет человека из таблицы
-func (p *peopleRepo) DeleteByPerson(ctx context.Context, uuid string) error {
-  query := "DELETE FROM person WHERE id = $1"
+// DeleteByPerson удаляет человека из таблицы, при ненулевой version только эту версию записи
+func (p *peopleRepo) DeleteByPerson(ctx context.Context, uuid string, version int) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := "DELETE FROM person WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)"
 
-  result, err := p.db.ExecContext(ctx, query, uuid)
+  result, err := Conn(ctx, p.db).ExecContext(ctx, query, uuid, org, version)
   if err != nil {
     return fmt.Errorf("ошибка удаления человека: %v", err)
   }
@@ -130,11 +163,15 @@ func (p *peopleRepo) DeleteByPerson(ctx context.Context, uuid string) error {
 
 // GetByUUID ищет человека по UUID
 func (p *peopleRepo) GetByUUID(ctx context.Context, uuid string) (*dto.Person, error) {
-  ctxLogger := ctx.Value("logger").(*slog.Logger)
+  ctxLogger := utils.LoggerFromCtx(ctx)
   ctxLogger.Debug("db getbyuuid")
-  query := "SELECT id, surname, name, patronymic, address, passport_number FROM person WHERE id = $1"
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := "SELECT id, surname, name, patronymic, address, passport_number, manager_id, version FROM person WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL"
   var person Person
-  err := p.db.GetContext(ctx, &person, query, uuid)
+  err = Conn(ctx, p.db).GetContext(ctx, &person, query, uuid, org)
   if err != nil {
     if err == sql.ErrNoRows {
       ctxLogger.Error(err.Error())
@@ -147,90 +184,409 @@ func (p *peopleRepo) GetByUUID(ctx context.Context, uuid string) (*dto.Person, e
   return person.toDTO(), nil
 }
 
-// UpdatePerson обновляет запись о человеке в базе данных
+// UpdatePerson обновляет запись о человеке в базе данных, если ее версия все еще person.Version
 func (p *peopleRepo) UpdatePerson(ctx context.Context, person *dto.Person) (*dto.Person, error) {
   query := `UPDATE person
 	          SET surname = :surname,
 	              name = :name,
 	              patronymic = :patronymic,
 	              address = :address,
-	              passport_number = :passport_number
-	          WHERE id = :id
-	          RETURNING id, surname, name, patronymic, address, passport_number`
+	              passport_number = :passport_number,
+	              manager_id = :manager_id,
+	              version = version + 1
+	          WHERE id = :id AND organization_id = :organization_id AND deleted_at IS NULL AND version = :version
+	          RETURNING id, surname, name, patronymic, address, passport_number, manager_id, version`
 
-  personDb := Person{}
-
-  rows, err := p.db.NamedQueryContext(ctx, query, personDb.fromDTO(person))
+  org, err := tenant(ctx)
   if err != nil {
+    return nil, err
+  }
+  personDb := Person{OrganizationId: org}
+
+  var updatedPerson Person
+  err = namedGet(ctx, Conn(ctx, p.db), &updatedPerson, query, personDb.fromDTO(person))
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("%w: человек с UUID %s не найден или изменен", ErrStale, person.ID)
+  } else if err != nil {
     return nil, fmt.Errorf("ошибка обновления данных в базе: %v", err)
   }
-  defer rows.Close()
+  return updatedPerson.toDTO(), nil
+}
 
-  if rows.Next() {
-    var updatedPerson Person
-    if err := rows.StructScan(&updatedPerson); err != nil {
-      return nil, fmt.Errorf("ошибка сканирования обновленных данных: %v", err)
-    }
-    return updatedPerson.toDTO(), nil
+// peopleSort колонки сортировки списка людей
+var peopleSort = map[string]string{
+  "surname":    "surname",
+  "name":       "name",
+  "patronymic": "COALESCE(patronymic, '')",
+  "created_at": "created_at",
+}
+
+var peopleDefaultSort = []dto.SortField{{Field: "surname"}, {Field: "name"}}
+
+// sortValue значение поля сортировки записи для курсора
+func (p *Person) sortValue(field string) string {
+  switch field {
+  case "surname":
+    return p.Surname
+  case "name":
+    return p.Name
+  case "patronymic":
+    return p.Patronymic.String
+  case "created_at":
+    return p.CreatedAt.Format(time.RFC3339Nano)
   }
+  return p.Id
+}
 
-  return nil, fmt.Errorf("человек с UUID %s не найден", person.ID)
+// peopleWhere собирает условия отбора людей организации org
+func peopleWhere(org string, filter *dto.PeopleFilter) *query.Builder {
+  b := &query.Builder{}
+  b.Where("organization_id = ?", org)
+  b.Where("deleted_at IS NULL")
+  if filter.ID != "" {
+    b.Where("id = ?", filter.ID)
+  }
+  if filter.ManagerID != "" {
+    b.Where(`(manager_id = ? OR id = ?
+                     OR id IN (SELECT m.person_id FROM team_members m JOIN teams t ON t.id = m.team_id
+                                WHERE t.manager_id = ?))`, filter.ManagerID, filter.ManagerID, filter.ManagerID)
+  }
+  if filter.TeamID != "" {
+    b.Where("id IN (SELECT person_id FROM team_members WHERE team_id = ?)", filter.TeamID)
+  }
+  b.MatchAny("surname", filter.Surname)
+  b.MatchAny("name", filter.Name)
+  b.MatchAny("patronymic", filter.Patronymic)
+  b.Match("address", filter.Address)
+  b.MatchAny("passport_number", filter.PassportNumber)
+  if filter.HasActiveTask != nil {
+    active := "EXISTS (SELECT 1 FROM tasks WHERE tasks.idperson = person.id AND tasks.task_status = ?)"
+    if !*filter.HasActiveTask {
+      active = "NOT " + active
+    }
+    b.Where(active, status.Work)
+  }
+  if filter.CreatedAfter != nil {
+    b.Where("created_at > ?", *filter.CreatedAfter)
+  }
+  return b
 }
 
-func (p *peopleRepo) GetPeople(ctx context.Context, filter *dto.Person, offset, limit int) ([]dto.Person, int, error) {
-  query := `SELECT id, surname, name, patronymic, address, passport_number
+// GetPeople возвращает страницу людей по фильтру в порядке filter.Sort (по умолчанию surname, name),
+// id замыкает сортировку и делает курсор однозначным.
+// Фильтр по manager_id отбирает руководителя вместе с его подчиненными и участниками его команд
+func (p *peopleRepo) GetPeople(ctx context.Context, filter *dto.PeopleFilter, page dto.PageRequest) ([]dto.Person, dto.PageInfo, error) {
+  var info dto.PageInfo
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, info, err
+  }
+
+  sort := filter.Sort
+  if len(sort) == 0 {
+    sort = peopleDefaultSort
+  }
+  orders := make([]query.Order, 0, len(sort)+1)
+  for _, field := range sort {
+    column, ok := peopleSort[field.Field]
+    if !ok {
+      return nil, info, fmt.Errorf("сортировка по полю %s не поддерживается", field.Field)
+    }
+    orders = append(orders, query.Order{Column: column, Desc: field.Desc})
+  }
+  orders = append(orders, query.Order{Column: "id"})
+  sortKey := dto.SortKey(sort)
+
+  b := peopleWhere(org, filter)
+  countWhere, countArgs := b.Sql(), b.Args()
+  if page.Cursor != "" {
+    after, err := cursor.Decode(page.Cursor, len(orders)+1)
+    if err != nil || after[0] != sortKey || !utils.IsValidUUID(after[len(after)-1]) {
+      return nil, info, cursor.ErrInvalid
+    }
+    values := make([]interface{}, len(orders))
+    for i, field := range sort {
+      values[i] = after[i+1]
+      if field.Field == "created_at" {
+        if values[i], err = time.Parse(time.RFC3339Nano, after[i+1]); err != nil {
+          return nil, info, cursor.ErrInvalid
+        }
+      }
+    }
+    values[len(values)-1] = after[len(after)-1]
+    b.After(orders, values)
+  }
+
+  peopleQuery := `SELECT id, surname, name, patronymic, address, passport_number, manager_id, version, created_at
               FROM person
-              WHERE (:surname = '' OR surname = :surname)
-                AND (:name = '' OR name = :name)
-                AND (:patronymic = '' OR patronymic = :patronymic)
-                AND (:address = '' OR address = :address)
-                AND (:passport_number = '' OR passport_number = :passport_number)
-              ORDER BY surname, name
-              LIMIT :limit OFFSET :offset`
-
-  filterValues := map[string]interface{}{
-    "surname":         filter.Surname,
-    "name":            filter.Name,
-    "patronymic":      filter.Patronymic,
-    "address":         filter.Address,
-    "passport_number": filter.PassportNumber,
-    "limit":           limit,
-    "offset":          offset,
-  }
-  rows, err := p.db.NamedQueryContext(ctx, query, filterValues)
-  if err != nil {
-    return nil, 0, fmt.Errorf("ошибка выполнения запроса: %v", err)
-  }
-  defer rows.Close()
-
-  var people []dto.Person
-  for rows.Next() {
-    var person Person
-    if err := rows.StructScan(&person); err != nil {
-      return nil, 0, fmt.Errorf("ошибка сканирования данных: %v", err)
+              ` + b.Sql() + `
+              ` + query.OrderBy(orders) + `
+              LIMIT ` + b.Arg(page.Limit+1) + ` OFFSET ` + b.Arg(page.Offset)
+
+  var persons []Person
+  err = Conn(ctx, p.db).SelectContext(ctx, &persons, peopleQuery, b.Args()...)
+  if err != nil {
+    return nil, info, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  // лишняя запись означает, что есть следующая страница
+  if len(persons) > page.Limit {
+    persons = persons[:page.Limit]
+    last := persons[len(persons)-1]
+    values := []string{sortKey}
+    for _, field := range sort {
+      values = append(values, last.sortValue(field.Field))
     }
+    info.NextCursor = cursor.Encode(append(values, last.Id)...)
+  }
+
+  people := make([]dto.Person, 0, len(persons))
+  for _, person := range persons {
     people = append(people, *person.toDTO())
   }
 
-  countQuery := `SELECT COUNT(*)
-                   FROM person
-                   WHERE (:surname = '' OR surname = :surname)
-                     AND (:name = '' OR name = :name)
-                     AND (:patronymic = '' OR patronymic = :patronymic)
-                     AND (:address = '' OR address = :address)
-                     AND (:passport_number = '' OR passport_number = :passport_number)`
+  if !page.Count {
+    return people, info, nil
+  }
 
-  nstmt, args, err := p.db.BindNamed(countQuery, filterValues)
+  var totalCount int
+  err = Conn(ctx, p.db).GetContext(ctx, &totalCount, "SELECT COUNT(*) FROM person "+countWhere, countArgs...)
   if err != nil {
-    return nil, 0, fmt.Errorf("ошибка биндинга именованных параметров: %v", err)
+    return nil, info, fmt.Errorf("ошибка получения общего количества записей: %v", err)
   }
+  info.Total = &totalCount
 
-  var totalCount int
-  err = p.db.GetContext(ctx, &totalCount, nstmt, args...)
+  return people, info, nil
+}
+
+type PersonMatch struct {
+  Person
+  Rank      float64 `db:"rank"`
+  Highlight string  `db:"highlight"`
+}
+
+// tsQuery превращает строку поиска в запрос to_tsquery: каждое слово ищется по префиксу
+func tsQuery(q string) string {
+  words := strings.FieldsFunc(q, func(r rune) bool {
+    return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+  })
+  for i, word := range words {
+    words[i] = word + ":*"
+  }
+  return strings.Join(words, " & ")
+}
+
+// SearchPeople ищет людей по словам фамилии, имени, отчества и адреса и по похожести с опечатками,
+// результаты упорядочены по релевантности. filter ограничивает область поиска
+func (p *peopleRepo) SearchPeople(ctx context.Context, filter *dto.PeopleFilter, q string, limit int) ([]dto.PersonMatch, error) {
+  org, err := tenant(ctx)
   if err != nil {
-    return nil, 0, fmt.Errorf("ошибка получения общего количества записей: %v", err)
+    return nil, err
   }
+  b := peopleWhere(org, filter)
+  tsq := "to_tsquery('simple', " + b.Arg(tsQuery(q)) + ")"
+  text := b.Arg(strings.ToLower(q))
+  b.Where("(search_vector @@ " + tsq + " OR " + text + " <% search_text)")
+
+  searchQuery := `SELECT id, surname, name, patronymic, address, passport_number, manager_id, version, created_at,
+                     GREATEST(ts_rank(search_vector, ` + tsq + `), word_similarity(` + text + `, search_text)) AS rank,
+                     ts_headline('simple', surname || ' ' || name || ' ' || coalesce(patronymic, '') || ', ' || address,
+                                 ` + tsq + `, 'StartSel=<b>, StopSel=</b>, HighlightAll=true') AS highlight
+              FROM person
+              ` + b.Sql() + `
+              ORDER BY rank DESC, surname, name, id
+              LIMIT ` + b.Arg(limit)
 
-  return people, totalCount, nil
+  var matches []PersonMatch
+  err = Conn(ctx, p.db).SelectContext(ctx, &matches, searchQuery, b.Args()...)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.PersonMatch, 0, len(matches))
+  for _, match := range matches {
+    res = append(res, dto.PersonMatch{
+      Person:    *match.toDTO(),
+      Rank:      match.Rank,
+      Highlight: match.Highlight,
+    })
+  }
+  return res, nil
+}
 
+type Duplicate struct {
+  First             Person  `db:"first"`
+  Second            Person  `db:"second"`
+  AddressSimilarity float64 `db:"address_similarity"`
+}
+
+// FindDuplicates ищет пары людей с одинаковым ФИО без учета регистра и похожим адресом
+func (p *peopleRepo) FindDuplicates(ctx context.Context, threshold float64, limit int) ([]dto.Duplicate, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT a.id AS "first.id", a.surname AS "first.surname", a.name AS "first.name",
+                   a.patronymic AS "first.patronymic", a.address AS "first.address",
+                   a.passport_number AS "first.passport_number", a.manager_id AS "first.manager_id",
+                   b.id AS "second.id", b.surname AS "second.surname", b.name AS "second.name",
+                   b.patronymic AS "second.patronymic", b.address AS "second.address",
+                   b.passport_number AS "second.passport_number", b.manager_id AS "second.manager_id",
+                   similarity(a.address, b.address) AS address_similarity
+              FROM person a
+              JOIN person b ON b.organization_id = a.organization_id
+                           AND a.id < b.id
+                           AND lower(b.surname) = lower(a.surname)
+                           AND lower(b.name) = lower(a.name)
+                           AND lower(coalesce(b.patronymic, '')) = lower(coalesce(a.patronymic, ''))
+              WHERE a.organization_id = $1
+                AND a.deleted_at IS NULL
+                AND b.deleted_at IS NULL
+                AND similarity(a.address, b.address) >= $2
+              ORDER BY address_similarity DESC, a.surname, a.name, a.id, b.id
+              LIMIT $3`
+
+  var rows []Duplicate
+  err = Conn(ctx, p.db).SelectContext(ctx, &rows, query, org, threshold, limit)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Duplicate, 0, len(rows))
+  for _, row := range rows {
+    res = append(res, dto.Duplicate{
+      First:             *row.First.toDTO(),
+      Second:            *row.Second.toDTO(),
+      AddressSimilarity: row.AddressSimilarity,
+    })
+  }
+  return res, nil
+}
+
+// MergePerson переносит задачи (вместе с их временем), подчиненных, команды и ключи
+// человека sourceID к targetID и помечает источник удаленным. Вызывается внутри транзакции.
+// Обе записи блокируются до ее конца, параллельное слияние или удаление ждет
+func (p *peopleRepo) MergePerson(ctx context.Context, sourceID, targetID string) (int64, error) {
+  tx := TxFromCtx(ctx, p.db)
+  if tx == nil {
+    return 0, fmt.Errorf("слияние людей требует транзакции")
+  }
+  org, err := tenant(ctx)
+  if err != nil {
+    return 0, err
+  }
+
+  // строки блокируются в порядке id, встречные слияния не заблокируют друг друга
+  var locked []string
+  err = tx.SelectContext(ctx, &locked, `SELECT id FROM person
+      WHERE id IN ($1, $2) AND organization_id = $3 AND deleted_at IS NULL
+      ORDER BY id FOR UPDATE`, sourceID, targetID, org)
+  if err != nil {
+    return 0, fmt.Errorf("ошибка блокировки людей: %v", err)
+  }
+  for _, id := range []string{sourceID, targetID} {
+    if !slices.Contains(locked, id) {
+      return 0, fmt.Errorf("человек с UUID %s не найден", id)
+    }
+  }
+
+  result, err := tx.ExecContext(ctx, "UPDATE tasks SET idperson = $2 WHERE idperson = $1 AND organization_id = $3", sourceID, targetID, org)
+  if err != nil {
+    return 0, fmt.Errorf("ошибка переноса задач: %v", err)
+  }
+  moved, err := result.RowsAffected()
+  if err != nil {
+    return 0, fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+
+  statements := []string{
+    "UPDATE person SET manager_id = $2 WHERE manager_id = $1 AND id <> $2 AND organization_id = $3",
+    // цель, подчиненная источнику, получает его руководителя, а не саму себя
+    `UPDATE person SET manager_id = NULLIF((SELECT manager_id FROM person WHERE id = $1), $2)
+      WHERE id = $2 AND manager_id = $1 AND organization_id = $3`,
+    "UPDATE teams SET manager_id = $2 WHERE manager_id = $1 AND organization_id = $3",
+    "UPDATE api_keys SET person_id = $2 WHERE person_id = $1 AND organization_id = $3",
+    `INSERT INTO team_members (team_id, person_id)
+       SELECT m.team_id, $2 FROM team_members m JOIN teams t ON t.id = m.team_id
+        WHERE m.person_id = $1 AND t.organization_id = $3
+     ON CONFLICT DO NOTHING`,
+    "DELETE FROM team_members WHERE person_id = $1",
+  }
+  for _, statement := range statements {
+    if _, err = tx.ExecContext(ctx, statement, sourceID, targetID, org); err != nil {
+      return 0, fmt.Errorf("ошибка переноса связей человека: %v", err)
+    }
+  }
+
+  result, err = tx.ExecContext(ctx, "UPDATE person SET deleted_at = NOW(), manager_id = NULL WHERE id = $1 AND organization_id = $2 AND deleted_at IS NULL", sourceID, org)
+  if err != nil {
+    return 0, fmt.Errorf("ошибка удаления человека: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return 0, fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return 0, fmt.Errorf("человек с UUID %s не найден", sourceID)
+  }
+  return moved, nil
+}
+
+// IsManagedBy проверяет, что managerUUID является руководителем человека
+// напрямую или как руководитель одной из его команд
+func (p *peopleRepo) IsManagedBy(ctx context.Context, uuid, managerUUID string) (bool, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return false, err
+  }
+  query := `SELECT EXISTS (
+              SELECT 1 FROM person
+              WHERE id = $1 AND organization_id = $3 AND deleted_at IS NULL
+                AND (manager_id = $2
+                     OR id IN (SELECT m.person_id FROM team_members m JOIN teams t ON t.id = m.team_id
+                                WHERE t.manager_id = $2)))`
+  var managed bool
+  err = Conn(ctx, p.db).GetContext(ctx, &managed, query, uuid, managerUUID, org)
+  if err != nil {
+    return false, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  return managed, nil
+}
+
+// GetByUUIDs ищет людей по списку UUID одним запросом, отсутствующие пропускаются
+func (p *peopleRepo) GetByUUIDs(ctx context.Context, uuids []string) ([]dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT id, surname, name, patronymic, address, passport_number, manager_id, version
+              FROM person
+              WHERE id = ANY($1) AND organization_id = $2 AND deleted_at IS NULL`
+  var people []Person
+  err = Conn(ctx, p.db).SelectContext(ctx, &people, query, pq.StringArray(uuids), org)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  res := make([]dto.Person, 0, len(people))
+  for _, person := range people {
+    res = append(res, *person.toDTO())
+  }
+  return res, nil
+}
+
+// ManagedAmong отбирает из uuids людей, которыми руководит managerUUID, по тем же правилам, что и IsManagedBy
+func (p *peopleRepo) ManagedAmong(ctx context.Context, uuids []string, managerUUID string) ([]string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT id FROM person
+              WHERE id = ANY($1) AND organization_id = $3 AND deleted_at IS NULL
+                AND (manager_id = $2
+                     OR id IN (SELECT m.person_id FROM team_members m JOIN teams t ON t.id = m.team_id
+                                WHERE t.manager_id = $2))`
+  var managed []string
+  err = Conn(ctx, p.db).SelectContext(ctx, &managed, query, pq.StringArray(uuids), managerUUID, org)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  return managed, nil
 }
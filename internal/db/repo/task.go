@@ -5,7 +5,11 @@ import (
   "database/sql"
   "fmt"
   "github.com/jmoiron/sqlx"
+  "github.com/lib/pq"
+  "time"
   "timetracker/internal/bl/dto"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/cursor"
   "timetracker/internal/utils/const/status"
 )
 
@@ -14,6 +18,10 @@ type Task struct {
   IdPerson   string `json:"id_person" db:"idperson"`
   TaskName   string `json:"task_name" db:"task_name"`
   TaskStatus string `json:"task_status" db:"task_status"`
+
+  OrganizationId string    `json:"-" db:"organization_id"`
+  CreatedAt      time.Time `json:"-" db:"created_at"`
+  Version        int       `json:"version" db:"version"`
 }
 
 func (t *Task) toDTO() *dto.Task {
@@ -26,6 +34,7 @@ func (t *Task) toDTO() *dto.Task {
     IdPerson:   t.IdPerson,
     TaskName:   t.TaskName,
     TaskStatus: t.TaskStatus,
+    Version:    t.Version,
   }
 }
 
@@ -48,9 +57,13 @@ type taskRepo struct {
 
 type ITaskRepo interface {
   CreateTask(ctx context.Context, task *dto.Task) (*dto.Task, error)
+  GetTask(ctx context.Context, id string) (*dto.Task, error)
+  GetTasks(ctx context.Context, personID string, page dto.PageRequest) ([]dto.Task, dto.PageInfo, error)
   GetTaskStatus(ctx context.Context, id string) (string, error)
-  UpdateStatus(ctx context.Context, id, st string) error
+  UpdateStatus(ctx context.Context, id, st string, version int) error
   TaskTimes(ctx context.Context, id, start, end string) ([]dto.TaskTimeResult, error)
+  TeamTimes(ctx context.Context, teamID, start, end string) ([]dto.TeamTimeResult, error)
+  GetTasksOfPeople(ctx context.Context, personIDs []string, limit int) (map[string][]dto.Task, map[string]dto.PageInfo, error)
 }
 
 func NewTaskRepo(db *sqlx.DB) ITaskRepo {
@@ -58,42 +71,110 @@ func NewTaskRepo(db *sqlx.DB) ITaskRepo {
 }
 
 func (t *taskRepo) CreateTask(ctx context.Context, task *dto.Task) (*dto.Task, error) {
-  query := `INSERT INTO tasks ( idperson, task_name, task_status) VALUES ( :idperson, :task_name, :task_status) RETURNING idtask`
+  query := `INSERT INTO tasks ( idperson, task_name, task_status, organization_id) VALUES ( :idperson, :task_name, :task_status, :organization_id) RETURNING idtask, version`
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
   taskModel := new(Task).fromDTO(task)
   taskModel.TaskStatus = status.New
-  fmt.Println(taskModel.IdPerson)
+  taskModel.OrganizationId = org
 
-  rows, err := t.db.NamedQueryContext(ctx, query, taskModel)
-  if err != nil {
+  var created Task
+  if err = namedGet(ctx, Conn(ctx, t.db), &created, query, taskModel); err != nil {
     return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
   }
-  defer rows.Close()
+  taskModel.IdTask, taskModel.Version = created.IdTask, created.Version
+
+  return taskModel.toDTO(), nil
+}
 
-  if rows.Next() {
-    err := rows.Scan(&taskModel.IdTask)
+func (t *taskRepo) GetTask(ctx context.Context, id string) (*dto.Task, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  var task Task
+  query := `SELECT idtask, idperson, task_name, task_status, version FROM tasks WHERE idtask = $1 AND organization_id = $2`
+  err = Conn(ctx, t.db).GetContext(ctx, &task, query, id, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("задача с id %s не найдена", id)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка получения задачи из базы данных: %v", err)
+  }
+
+  return task.toDTO(), nil
+}
+
+// GetTasks возвращает страницу задач человека в порядке создания, (created_at, idtask) служит ключом курсора
+func (t *taskRepo) GetTasks(ctx context.Context, personID string, page dto.PageRequest) ([]dto.Task, dto.PageInfo, error) {
+  var info dto.PageInfo
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, info, err
+  }
+  args := []interface{}{personID, org, page.Limit + 1, page.Offset}
+  query := `SELECT idtask, idperson, task_name, task_status, version, created_at
+              FROM tasks
+              WHERE idperson = $1 AND organization_id = $2`
+  if page.Cursor != "" {
+    after, err := cursor.Decode(page.Cursor, 2)
+    if err != nil || !utils.IsValidUUID(after[1]) {
+      return nil, info, cursor.ErrInvalid
+    }
+    createdAt, err := time.Parse(time.RFC3339Nano, after[0])
     if err != nil {
-      return nil, fmt.Errorf("ошибка сканирования результата: %v", err)
+      return nil, info, cursor.ErrInvalid
     }
-  } else {
-    return nil, fmt.Errorf("не удалось вставить задачу")
+    query += `
+                AND (created_at, idtask) > ($5, $6)`
+    args = append(args, createdAt, after[1])
   }
+  query += `
+              ORDER BY created_at, idtask
+              LIMIT $3 OFFSET $4`
 
-  return taskModel.toDTO(), nil
-}
+  var tasks []Task
+  err = Conn(ctx, t.db).SelectContext(ctx, &tasks, query, args...)
+  if err != nil {
+    return nil, info, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
 
-func (t *taskRepo) GetTaskStatus(ctx context.Context, id string) (string, error) {
-  tx, ok := ctx.Value("tx").(*sqlx.Tx)
+  if len(tasks) > page.Limit {
+    tasks = tasks[:page.Limit]
+    last := tasks[len(tasks)-1]
+    info.NextCursor = cursor.Encode(last.CreatedAt.Format(time.RFC3339Nano), last.IdTask)
+  }
 
-  var taskStatus string
-  query := `SELECT task_status FROM tasks WHERE idtask = $1`
-  var err error
+  res := make([]dto.Task, 0, len(tasks))
+  for _, task := range tasks {
+    res = append(res, *task.toDTO())
+  }
 
-  if ok {
-    err = tx.GetContext(ctx, &taskStatus, query, id)
-  } else {
-    err = t.db.GetContext(ctx, &taskStatus, query, id)
+  if page.Count {
+    var totalCount int
+    err = Conn(ctx, t.db).GetContext(ctx, &totalCount, `SELECT COUNT(*) FROM tasks WHERE idperson = $1 AND organization_id = $2`, personID, org)
+    if err != nil {
+      return nil, info, fmt.Errorf("ошибка получения общего количества записей: %v", err)
+    }
+    info.Total = &totalCount
   }
 
+  return res, info, nil
+}
+
+// GetTaskStatus статус задачи. Строка блокируется до конца транзакции: параллельная смена статуса
+// ждет ее завершения и читает уже новый статус, поэтому таймер не запустится дважды
+func (t *taskRepo) GetTaskStatus(ctx context.Context, id string) (string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", err
+  }
+
+  var taskStatus string
+  query := `SELECT task_status FROM tasks WHERE idtask = $1 AND organization_id = $2 FOR UPDATE`
+  err = Conn(ctx, t.db).GetContext(ctx, &taskStatus, query, id, org)
   if err == sql.ErrNoRows {
     return "", fmt.Errorf("задача с id %s не найдена", id)
   } else if err != nil {
@@ -102,18 +183,15 @@ func (t *taskRepo) GetTaskStatus(ctx context.Context, id string) (string, error)
 
   return taskStatus, nil
 }
-func (t *taskRepo) UpdateStatus(ctx context.Context, id, st string) error {
-  tx, ok := ctx.Value("tx").(*sqlx.Tx)
-  query := `UPDATE tasks SET task_status = $1 WHERE idtask = $2`
-  var result sql.Result
-  var err error
-
-  if ok {
-    result, err = tx.ExecContext(ctx, query, st, id)
-  } else {
-    result, err = t.db.ExecContext(ctx, query, st, id)
+// UpdateStatus меняет статус задачи, при ненулевой version только в этой версии записи
+func (t *taskRepo) UpdateStatus(ctx context.Context, id, st string, version int) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
   }
-
+  query := `UPDATE tasks SET task_status = $1, version = version + 1
+              WHERE idtask = $2 AND organization_id = $3 AND ($4 = 0 OR version = $4)`
+  result, err := Conn(ctx, t.db).ExecContext(ctx, query, st, id, org, version)
   if err != nil {
     return fmt.Errorf("ошибка обновления статуса задачи в базе данных: %v", err)
   }
@@ -123,6 +201,9 @@ func (t *taskRepo) UpdateStatus(ctx context.Context, id, st string) error {
     return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
   }
 
+  if rowsAffected == 0 && version != 0 {
+    return fmt.Errorf("%w: задача с id %s не найдена или изменена", ErrStale, id)
+  }
   if rowsAffected == 0 {
     return fmt.Errorf("задача с id %s не найдена", id)
   }
@@ -130,6 +211,47 @@ func (t *taskRepo) UpdateStatus(ctx context.Context, id, st string) error {
   return nil
 }
 
+// GetTasksOfPeople первые страницы задач нескольких людей одним запросом, в том же порядке и с теми же курсорами,
+// что и GetTasks. Общее число задач каждого человека заполняется всегда
+func (t *taskRepo) GetTasksOfPeople(ctx context.Context, personIDs []string, limit int) (map[string][]dto.Task, map[string]dto.PageInfo, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, nil, err
+  }
+  query := `SELECT idtask, idperson, task_name, task_status, version, created_at, total
+              FROM (SELECT idtask, idperson, task_name, task_status, version, created_at,
+                           ROW_NUMBER() OVER (PARTITION BY idperson ORDER BY created_at, idtask) AS rn,
+                           COUNT(*) OVER (PARTITION BY idperson) AS total
+                      FROM tasks
+                      WHERE idperson = ANY($1) AND organization_id = $2) ranked
+              WHERE rn <= $3
+              ORDER BY idperson, created_at, idtask`
+  var rows []struct {
+    Task
+    Total int `db:"total"`
+  }
+  err = Conn(ctx, t.db).SelectContext(ctx, &rows, query, pq.StringArray(personIDs), org, limit+1)
+  if err != nil {
+    return nil, nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  tasks := make(map[string][]dto.Task, len(personIDs))
+  infos := make(map[string]dto.PageInfo, len(personIDs))
+  for i, row := range rows {
+    total := row.Total
+    info := dto.PageInfo{Total: &total}
+    if len(tasks[row.IdPerson]) == limit {
+      // лишняя строка означает следующую страницу, курсор по последней отданной
+      last := rows[i-1]
+      info.NextCursor = cursor.Encode(last.CreatedAt.Format(time.RFC3339Nano), last.IdTask)
+    } else {
+      tasks[row.IdPerson] = append(tasks[row.IdPerson], *row.toDTO())
+    }
+    infos[row.IdPerson] = info
+  }
+  return tasks, infos, nil
+}
+
 type TaskTimeCollect []TaskTimeResult
 
 type TaskTimeResult struct {
@@ -170,6 +292,7 @@ func (t *taskRepo) TaskTimes(ctx context.Context, id, start, end string) ([]dto.
         tasks task ON t.idtask = task.idtask
     WHERE
         task.idperson = $1
+        AND task.organization_id = $4
         AND t.end_time IS NOT NULL
         AND (
             (t.start_time >= $2 AND t.start_time <= $3)
@@ -192,10 +315,111 @@ ORDER BY
     total_time DESC;
 `
 
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
   var results TaskTimeCollect
-  err := t.db.SelectContext(ctx, &results, query, id, start, end)
+  err = Conn(ctx, t.db).SelectContext(ctx, &results, query, id, start, end, org)
   if err != nil {
     return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
   }
   return results.toDTO(), nil
 }
+
+type TeamTimeRow struct {
+  IDPerson   string `db:"idperson"`
+  Surname    string `db:"surname"`
+  Name       string `db:"name"`
+  IDTask     string `db:"idtask"`
+  TaskName   string `db:"task_name"`
+  TotalTime  string `db:"total_time"`
+  PersonTime string `db:"person_time"`
+}
+
+// TeamTimes трудозатраты участников команды по задачам, участники упорядочены по общему времени
+func (t *taskRepo) TeamTimes(ctx context.Context, teamID, start, end string) ([]dto.TeamTimeResult, error) {
+  query := `WITH filtered_times AS (
+    SELECT
+        task.idperson,
+        t.idtask,
+        task.task_name,
+        CASE
+            WHEN t.start_time < $2 THEN $2
+            ELSE t.start_time
+        END AS adjusted_start_time,
+        CASE
+            WHEN t.end_time > $3 THEN $3
+            ELSE t.end_time
+        END AS adjusted_end_time
+    FROM
+        timetask t
+    JOIN
+        tasks task ON t.idtask = task.idtask
+    JOIN
+        team_members m ON m.person_id = task.idperson
+    WHERE
+        m.team_id = $1
+        AND task.organization_id = $4
+        AND t.end_time IS NOT NULL
+        AND (
+            (t.start_time >= $2 AND t.start_time <= $3)
+            OR
+            (t.end_time >= $2 AND t.end_time <= $3)
+            OR
+            (t.start_time < $2 AND t.end_time > $3)
+        )
+)
+SELECT
+    f.idperson,
+    p.surname,
+    p.name,
+    f.idtask,
+    f.task_name,
+    SUM(f.adjusted_end_time - f.adjusted_start_time) AS total_time,
+    SUM(SUM(f.adjusted_end_time - f.adjusted_start_time)) OVER (PARTITION BY f.idperson) AS person_time
+FROM
+    filtered_times f
+JOIN
+    person p ON p.id = f.idperson
+GROUP BY
+    f.idperson,
+    p.surname,
+    p.name,
+    f.idtask,
+    f.task_name
+ORDER BY
+    person_time DESC,
+    f.idperson,
+    total_time DESC;
+`
+
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var rows []TeamTimeRow
+  err = Conn(ctx, t.db).SelectContext(ctx, &rows, query, teamID, start, end, org)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  var res []dto.TeamTimeResult
+  for _, row := range rows {
+    if len(res) == 0 || res[len(res)-1].IDPerson != row.IDPerson {
+      res = append(res, dto.TeamTimeResult{
+        IDPerson:  row.IDPerson,
+        Surname:   row.Surname,
+        Name:      row.Name,
+        TotalTime: row.PersonTime,
+      })
+    }
+    last := &res[len(res)-1]
+    last.Tasks = append(last.Tasks, dto.TaskTimeResult{
+      IDTask:    row.IDTask,
+      TaskName:  row.TaskName,
+      TotalTime: row.TotalTime,
+    })
+  }
+  return res, nil
+}
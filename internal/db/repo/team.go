@@ -0,0 +1,206 @@
+package repo
+
+import (
+  "context"
+  "database/sql"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "timetracker/internal/bl/dto"
+)
+
+type Team struct {
+  Id             string  `db:"id"`
+  Name           string  `db:"name"`
+  ManagerId      *string `db:"manager_id"`
+  OrganizationId string  `db:"organization_id"`
+}
+
+func (t *Team) toDTO() *dto.Team {
+  if t == nil {
+    return nil
+  }
+  res := &dto.Team{
+    ID:   t.Id,
+    Name: t.Name,
+  }
+  if t.ManagerId != nil {
+    res.ManagerID = *t.ManagerId
+  }
+  return res
+}
+
+func (t *Team) fromDTO(model *dto.Team) *Team {
+  if model == nil {
+    return nil
+  }
+  t.Id = model.ID
+  t.Name = model.Name
+  t.ManagerId = nil
+  if model.ManagerID != "" {
+    managerId := model.ManagerID
+    t.ManagerId = &managerId
+  }
+  return t
+}
+
+type ITeamRepo interface {
+  CreateTeam(ctx context.Context, team *dto.Team) (*dto.Team, error)
+  GetTeam(ctx context.Context, id string) (*dto.Team, error)
+  GetTeams(ctx context.Context, managerID string) ([]dto.Team, error)
+  UpdateTeam(ctx context.Context, team *dto.Team) (*dto.Team, error)
+  DeleteTeam(ctx context.Context, id string) error
+  AddMember(ctx context.Context, teamID, personID string) error
+  RemoveMember(ctx context.Context, teamID, personID string) error
+  PersonTeams(ctx context.Context, personID string) ([]string, error)
+}
+
+type teamRepo struct {
+  db *sqlx.DB
+}
+
+func NewTeamRepo(db *sqlx.DB) ITeamRepo {
+  return &teamRepo{db: db}
+}
+
+func (t *teamRepo) CreateTeam(ctx context.Context, team *dto.Team) (*dto.Team, error) {
+  query := `INSERT INTO teams (organization_id, name, manager_id)
+	          VALUES (:organization_id, :name, :manager_id)
+	          RETURNING id, organization_id, name, manager_id`
+
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  teamModel := new(Team).fromDTO(team)
+  teamModel.OrganizationId = org
+
+  var created Team
+  if err = namedGet(ctx, Conn(ctx, t.db), &created, query, teamModel); err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+// GetTeam возвращает команду вместе со списком участников
+func (t *teamRepo) GetTeam(ctx context.Context, id string) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT id, organization_id, name, manager_id FROM teams WHERE id = $1 AND organization_id = $2`
+  var team Team
+  err = Conn(ctx, t.db).GetContext(ctx, &team, query, id, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("команда с id %s не найдена", id)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка получения команды из базы данных: %v", err)
+  }
+
+  res := team.toDTO()
+  err = Conn(ctx, t.db).SelectContext(ctx, &res.Members, `SELECT person_id FROM team_members WHERE team_id = $1 ORDER BY person_id`, id)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка получения участников команды: %v", err)
+  }
+  return res, nil
+}
+
+// GetTeams возвращает команды организации, при непустом managerID только команды этого руководителя
+func (t *teamRepo) GetTeams(ctx context.Context, managerID string) ([]dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT id, organization_id, name, manager_id
+              FROM teams
+              WHERE organization_id = $1
+                AND ($2 = '' OR manager_id::text = $2)
+              ORDER BY name`
+  var teams []Team
+  err = Conn(ctx, t.db).SelectContext(ctx, &teams, query, org, managerID)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Team, 0, len(teams))
+  for _, team := range teams {
+    res = append(res, *team.toDTO())
+  }
+  return res, nil
+}
+
+func (t *teamRepo) UpdateTeam(ctx context.Context, team *dto.Team) (*dto.Team, error) {
+  query := `UPDATE teams
+	          SET name = :name,
+	              manager_id = :manager_id
+	          WHERE id = :id AND organization_id = :organization_id
+	          RETURNING id, organization_id, name, manager_id`
+
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  teamModel := new(Team).fromDTO(team)
+  teamModel.OrganizationId = org
+
+  var updated Team
+  err = namedGet(ctx, Conn(ctx, t.db), &updated, query, teamModel)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("команда с id %s не найдена", team.ID)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка обновления данных в базе: %v", err)
+  }
+  return updated.toDTO(), nil
+}
+
+func (t *teamRepo) DeleteTeam(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  result, err := Conn(ctx, t.db).ExecContext(ctx, "DELETE FROM teams WHERE id = $1 AND organization_id = $2", id, org)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления команды: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("команда с id %s не найдена", id)
+  }
+  return nil
+}
+
+// AddMember добавляет человека в команду, повторное добавление не ошибка
+func (t *teamRepo) AddMember(ctx context.Context, teamID, personID string) error {
+  query := `INSERT INTO team_members (team_id, person_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+  _, err := Conn(ctx, t.db).ExecContext(ctx, query, teamID, personID)
+  if err != nil {
+    return fmt.Errorf("ошибка добавления участника команды: %v", err)
+  }
+  return nil
+}
+
+func (t *teamRepo) RemoveMember(ctx context.Context, teamID, personID string) error {
+  result, err := Conn(ctx, t.db).ExecContext(ctx, "DELETE FROM team_members WHERE team_id = $1 AND person_id = $2", teamID, personID)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления участника команды: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("человек %s не состоит в команде %s", personID, teamID)
+  }
+  return nil
+}
+
+// PersonTeams id команд, в которых состоит человек
+func (t *teamRepo) PersonTeams(ctx context.Context, personID string) ([]string, error) {
+  var teams []string
+  err := Conn(ctx, t.db).SelectContext(ctx, &teams, `SELECT team_id FROM team_members WHERE person_id = $1 ORDER BY team_id`, personID)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка получения команд человека: %v", err)
+  }
+  return teams, nil
+}
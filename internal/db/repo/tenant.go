@@ -0,0 +1,16 @@
+package repo
+
+import (
+  "context"
+  "errors"
+  "timetracker/internal/utils"
+)
+
+// tenant возвращает организацию, которой ограничен запрос
+func tenant(ctx context.Context) (string, error) {
+  org := utils.TenantFromCtx(ctx)
+  if org == "" {
+    return "", errors.New("организация запроса не определена")
+  }
+  return org, nil
+}
@@ -2,8 +2,10 @@ package repo
 
 import (
   "context"
+  "database/sql"
   "fmt"
   "github.com/jmoiron/sqlx"
+  "github.com/lib/pq"
   "time"
   "timetracker/internal/bl/dto"
 )
@@ -46,6 +48,8 @@ type timeTaskRepo struct {
 type ITimeTaskRepo interface {
   StartTimer(ctx context.Context, id string) error
   StopTimer(ctx context.Context, id string) error
+  AddEntry(ctx context.Context, entry *dto.TimeTask) (*dto.TimeTask, error)
+  GetEntries(ctx context.Context, taskIDs, personIDs []string) (map[string][]dto.TimeTask, error)
 }
 
 func NewTimeTaskRepo(db *sqlx.DB) ITimeTaskRepo {
@@ -53,17 +57,13 @@ func NewTimeTaskRepo(db *sqlx.DB) ITimeTaskRepo {
 }
 
 func (t *timeTaskRepo) StartTimer(ctx context.Context, id string) error {
-  tx, ok := ctx.Value("tx").(*sqlx.Tx)
-
-  query := `INSERT INTO timetask (idtask, start_time) VALUES ($1, NOW())`
-  var err error
-
-  if ok {
-    _, err = tx.ExecContext(ctx, query, id)
-  } else {
-    _, err = t.db.ExecContext(ctx, query, id)
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
   }
 
+  query := `INSERT INTO timetask (idtask, start_time, organization_id) VALUES ($1, NOW(), $2)`
+  _, err = Conn(ctx, t.db).ExecContext(ctx, query, id, org)
   if err != nil {
     return fmt.Errorf("ошибка вставки данных в таблицу timetask: %v", err)
   }
@@ -72,8 +72,12 @@ func (t *timeTaskRepo) StartTimer(ctx context.Context, id string) error {
 }
 
 func (t *timeTaskRepo) StopTimer(ctx context.Context, id string) error {
-  query := `UPDATE timetask SET end_time = NOW() WHERE idtask = $1 AND end_time IS NULL`
-  result, err := t.db.ExecContext(ctx, query, id)
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := `UPDATE timetask SET end_time = NOW() WHERE idtask = $1 AND organization_id = $2 AND end_time IS NULL`
+  result, err := Conn(ctx, t.db).ExecContext(ctx, query, id, org)
   if err != nil {
     return fmt.Errorf("ошибка обновления данных в таблице timetask: %v", err)
   }
@@ -86,3 +90,50 @@ func (t *timeTaskRepo) StopTimer(ctx context.Context, id string) error {
   }
   return nil
 }
+
+// AddEntry добавляет внесенный вручную завершенный интервал работы над задачей,
+// если он не пересекается с уже учтенными интервалами этой задачи
+func (t *timeTaskRepo) AddEntry(ctx context.Context, entry *dto.TimeTask) (*dto.TimeTask, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `INSERT INTO timetask (idtask, start_time, end_time, organization_id)
+              SELECT $1::uuid, $2::timestamp, $3::timestamp, $4::uuid
+              WHERE NOT EXISTS (
+                SELECT 1 FROM timetask
+                WHERE idtask = $1 AND organization_id = $4
+                  AND start_time < $3 AND COALESCE(end_time, NOW()) > $2)
+              RETURNING id, idtask, start_time, end_time`
+  var created TimeTask
+  err = Conn(ctx, t.db).GetContext(ctx, &created, query, entry.IDTask, entry.StartTime, entry.EndTime, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("интервал пересекается с уже учтенным временем задачи %s", entry.IDTask)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в таблицу timetask: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+// GetEntries интервалы работы задач taskIDs, принадлежащих людям personIDs, по задачам в порядке начала
+func (t *timeTaskRepo) GetEntries(ctx context.Context, taskIDs, personIDs []string) (map[string][]dto.TimeTask, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT t.id, t.idtask, t.start_time, t.end_time
+              FROM timetask t
+              JOIN tasks task ON task.idtask = t.idtask
+              WHERE t.idtask = ANY($1) AND task.idperson = ANY($2) AND task.organization_id = $3
+              ORDER BY t.idtask, t.start_time`
+  var entries []TimeTask
+  err = Conn(ctx, t.db).SelectContext(ctx, &entries, query, pq.StringArray(taskIDs), pq.StringArray(personIDs), org)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make(map[string][]dto.TimeTask, len(taskIDs))
+  for _, entry := range entries {
+    res[entry.IDTask] = append(res[entry.IDTask], *entry.toDTO())
+  }
+  return res, nil
+}
@@ -0,0 +1,174 @@
+package repo
+
+import (
+  "context"
+  "database/sql"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "timetracker/internal/utils"
+)
+
+// Querier запросы, общие для пула соединений и транзакции. Методы репозиториев
+// выполняют запросы только через Conn, поэтому внутри транзакции попадают в нее
+type Querier interface {
+  ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+  GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+  SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// AllOrganizations значение app.organization_id, которому политики RLS открывают строки
+// всех организаций. Только для обслуживания базы, запросы сервера всегда ограничены организацией
+const AllOrganizations = "*"
+
+// SetTenant ограничивает строки транзакции tx организацией org политиками RLS до конца
+// транзакции. С пустой org строки таблиц с RLS не видны
+func SetTenant(ctx context.Context, tx *sqlx.Tx, org string) error {
+  _, err := tx.ExecContext(ctx, "SELECT set_config('app.organization_id', $1, true)", org)
+  if err != nil {
+    return fmt.Errorf("ошибка установки организации транзакции: %v", err)
+  }
+  return nil
+}
+
+type txKey struct{}
+
+// Tx транзакция хранилища на sqlx, начатая WithinTx. hooks - функции, отложенные до фиксации,
+// savepoints - число точек сохранения вложенных вызовов, из него строятся их имена
+type Tx struct {
+  *sqlx.Tx
+  db         *sqlx.DB
+  hooks      []func()
+  savepoints int
+  done       bool
+}
+
+// TxFromCtx незавершенная транзакция базы db из ctx, nil вне транзакции или в транзакции другой базы
+func TxFromCtx(ctx context.Context, db *sqlx.DB) *Tx {
+  tx, ok := ctx.Value(txKey{}).(*Tx)
+  if !ok || tx.db != db || tx.done {
+    return nil
+  }
+  return tx
+}
+
+// Conn возвращает транзакцию из ctx, если она начата. Вне транзакции запросы запроса с организацией
+// выполняются каждый в своей короткой транзакции с этой организацией, иначе RLS не покажет строк
+func Conn(ctx context.Context, db *sqlx.DB) Querier {
+  if tx := TxFromCtx(ctx, db); tx != nil {
+    return tx
+  }
+  if org := utils.TenantFromCtx(ctx); org != "" {
+    return tenantConn{db: db, org: org}
+  }
+  return db
+}
+
+// tenantConn пул соединений, каждый запрос которого ограничен организацией org
+type tenantConn struct {
+  db  *sqlx.DB
+  org string
+}
+
+func (c tenantConn) run(ctx context.Context, fn func(tx *Tx) error) error {
+  begin := func(ctx context.Context, tx *sqlx.Tx) error { return SetTenant(ctx, tx, c.org) }
+  return WithinTx(ctx, c.db, begin, func(ctx context.Context) error {
+    return fn(TxFromCtx(ctx, c.db))
+  })
+}
+
+func (c tenantConn) ExecContext(ctx context.Context, query string, args ...interface{}) (res sql.Result, err error) {
+  err = c.run(ctx, func(tx *Tx) error {
+    res, err = tx.ExecContext(ctx, query, args...)
+    return err
+  })
+  return res, err
+}
+
+func (c tenantConn) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+  return c.run(ctx, func(tx *Tx) error { return tx.GetContext(ctx, dest, query, args...) })
+}
+
+func (c tenantConn) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+  return c.run(ctx, func(tx *Tx) error { return tx.SelectContext(ctx, dest, query, args...) })
+}
+
+// namedGet выполняет именованный запрос postgres, например INSERT ... RETURNING, и сканирует
+// первую строку в dest. Без строк возвращает sql.ErrNoRows
+func namedGet(ctx context.Context, q Querier, dest interface{}, query string, arg interface{}) error {
+  query, args, err := sqlx.Named(query, arg)
+  if err != nil {
+    return err
+  }
+  return q.GetContext(ctx, dest, sqlx.Rebind(sqlx.DOLLAR, query), args...)
+}
+
+// WithinTx выполняет fn в транзакции и фиксирует ее, если fn вернула nil, иначе откатывает.
+// begin настраивает только что начатую транзакцию. Вызов внутри начатой транзакции работает
+// в точке сохранения: ошибка fn откатывает только изменения этого вызова. Ошибка фиксации
+// возвращается, функции AfterCommit выполняются только после успешной фиксации
+func WithinTx(ctx context.Context, db *sqlx.DB, begin func(ctx context.Context, tx *sqlx.Tx) error, fn func(ctx context.Context) error) error {
+  if tx := TxFromCtx(ctx, db); tx != nil {
+    return tx.savepoint(ctx, fn)
+  }
+  sqlTx, err := db.BeginTxx(ctx, nil)
+  if err != nil {
+    return fmt.Errorf("ошибка начала транзакции: %v", err)
+  }
+  tx := &Tx{Tx: sqlTx, db: db}
+  defer func() {
+    // транзакция без фиксации откатывается при любом выходе, в том числе при панике в fn
+    if !tx.done {
+      tx.done = true
+      _ = sqlTx.Rollback()
+    }
+  }()
+  if begin != nil {
+    if err = begin(ctx, sqlTx); err != nil {
+      return err
+    }
+  }
+  if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+    return err
+  }
+  tx.done = true
+  if err = sqlTx.Commit(); err != nil {
+    return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+  }
+  for _, hook := range tx.hooks {
+    hook()
+  }
+  return nil
+}
+
+// savepoint выполняет fn вложенного WithinTx в точке сохранения
+func (t *Tx) savepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+  t.savepoints++
+  name := fmt.Sprintf("sp_%d", t.savepoints)
+  if _, err := t.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+    return fmt.Errorf("ошибка создания точки сохранения: %v", err)
+  }
+  hooks := len(t.hooks)
+  if err := fn(ctx); err != nil {
+    if _, rbErr := t.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+      return fmt.Errorf("%w (ошибка отката к точке сохранения: %v)", err, rbErr)
+    }
+    // функции, отложенные откаченным вызовом, не выполняются
+    t.hooks = t.hooks[:hooks]
+    return err
+  }
+  if _, err := t.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+    return fmt.Errorf("ошибка освобождения точки сохранения: %v", err)
+  }
+  return nil
+}
+
+// AfterCommit выполняет fn после фиксации транзакции базы db из ctx, при откате fn не вызывается.
+// Вне транзакции fn выполняется сразу
+func AfterCommit(ctx context.Context, db *sqlx.DB, fn func()) {
+  tx := TxFromCtx(ctx, db)
+  if tx == nil {
+    fn()
+    return
+  }
+  tx.hooks = append(tx.hooks, fn)
+}
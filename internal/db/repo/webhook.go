@@ -0,0 +1,287 @@
+package repo
+
+import (
+  "context"
+  "database/sql"
+  "encoding/json"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "github.com/lib/pq"
+  "strconv"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type Webhook struct {
+  Id             string         `db:"id"`
+  OrganizationId string         `db:"organization_id"`
+  Url            string         `db:"url"`
+  Secret         string         `db:"secret"`
+  EventTypes     pq.StringArray `db:"event_types"`
+  CreatedAt      time.Time      `db:"created_at"`
+}
+
+func (w *Webhook) toDTO() *dto.Webhook {
+  if w == nil {
+    return nil
+  }
+  return &dto.Webhook{
+    ID:         w.Id,
+    URL:        w.Url,
+    EventTypes: w.EventTypes,
+    CreatedAt:  w.CreatedAt,
+  }
+}
+
+type WebhookDelivery struct {
+  Id             int64          `db:"id"`
+  WebhookId      string         `db:"webhook_id"`
+  OutboxId       int64          `db:"outbox_id"`
+  EventType      string         `db:"event_type"`
+  Status         string         `db:"status"`
+  Attempts       int            `db:"attempts"`
+  NextAttemptAt  time.Time      `db:"next_attempt_at"`
+  ResponseStatus *int           `db:"response_status"`
+  LastError      sql.NullString `db:"last_error"`
+  DeliveredAt    *time.Time     `db:"delivered_at"`
+  CreatedAt      time.Time      `db:"created_at"`
+}
+
+func (d *WebhookDelivery) toDTO() *dto.WebhookDelivery {
+  if d == nil {
+    return nil
+  }
+  res := &dto.WebhookDelivery{
+    ID:             d.Id,
+    WebhookID:      d.WebhookId,
+    EventID:        d.OutboxId,
+    EventType:      d.EventType,
+    Status:         d.Status,
+    Attempts:       d.Attempts,
+    ResponseStatus: d.ResponseStatus,
+    LastError:      d.LastError.String,
+    DeliveredAt:    d.DeliveredAt,
+    CreatedAt:      d.CreatedAt,
+  }
+  if d.Status == dto.DeliveryPending {
+    next := d.NextAttemptAt
+    res.NextAttemptAt = &next
+  }
+  return res
+}
+
+type IWebhookRepo interface {
+  CreateWebhook(ctx context.Context, hook *dto.Webhook) (*dto.Webhook, error)
+  GetWebhooks(ctx context.Context) ([]dto.Webhook, error)
+  DeleteWebhook(ctx context.Context, id string) error
+  GetDeliveries(ctx context.Context, webhookID, status string, limit int) ([]dto.WebhookDelivery, error)
+  Enqueue(ctx context.Context, eventType string, data []byte) error
+  Claim(ctx context.Context, limit int, lease time.Duration) ([]dto.OutgoingWebhook, error)
+  Delivered(ctx context.Context, id int64, status int) error
+  Retry(ctx context.Context, id int64, status *int, reason string, delay time.Duration) error
+  Fail(ctx context.Context, id int64, status *int, reason string) error
+  Cleanup(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+type webhookRepo struct {
+  db *sqlx.DB
+}
+
+func NewWebhookRepo(db *sqlx.DB) IWebhookRepo {
+  return &webhookRepo{db: db}
+}
+
+func (w *webhookRepo) CreateWebhook(ctx context.Context, hook *dto.Webhook) (*dto.Webhook, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `INSERT INTO webhooks (organization_id, url, secret, event_types)
+              VALUES ($1, $2, $3, $4)
+              RETURNING id, organization_id, url, secret, event_types, created_at`
+  var created Webhook
+  err = Conn(ctx, w.db).GetContext(ctx, &created, query, org, hook.URL, hook.Secret, pq.StringArray(hook.EventTypes))
+  if err != nil {
+    return nil, fmt.Errorf("ошибка создания вебхука: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+func (w *webhookRepo) GetWebhooks(ctx context.Context) ([]dto.Webhook, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var hooks []Webhook
+  query := `SELECT id, organization_id, url, secret, event_types, created_at
+              FROM webhooks WHERE organization_id = $1 ORDER BY created_at, id`
+  if err = Conn(ctx, w.db).SelectContext(ctx, &hooks, query, org); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Webhook, 0, len(hooks))
+  for _, hook := range hooks {
+    res = append(res, *hook.toDTO())
+  }
+  return res, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом ее доставок
+func (w *webhookRepo) DeleteWebhook(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  result, err := Conn(ctx, w.db).ExecContext(ctx, "DELETE FROM webhooks WHERE id = $1 AND organization_id = $2", id, org)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления вебхука: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("вебхук с id %s не найден", id)
+  }
+  return nil
+}
+
+// GetDeliveries последние доставки подписки, при непустом status только в этом статусе
+func (w *webhookRepo) GetDeliveries(ctx context.Context, webhookID, status string, limit int) ([]dto.WebhookDelivery, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT d.id, d.webhook_id, d.outbox_id, o.event_type, d.status, d.attempts, d.next_attempt_at,
+                   d.response_status, d.last_error, d.delivered_at, d.created_at
+              FROM webhook_deliveries d
+              JOIN webhooks w ON w.id = d.webhook_id
+              JOIN webhook_outbox o ON o.id = d.outbox_id
+              WHERE d.webhook_id = $1 AND w.organization_id = $2 AND ($3 = '' OR d.status = $3)
+              ORDER BY d.id DESC
+              LIMIT $4`
+  var deliveries []WebhookDelivery
+  if err = Conn(ctx, w.db).SelectContext(ctx, &deliveries, query, webhookID, org, status, limit); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.WebhookDelivery, 0, len(deliveries))
+  for _, delivery := range deliveries {
+    res = append(res, *delivery.toDTO())
+  }
+  return res, nil
+}
+
+// Enqueue пишет событие в outbox и ставит его доставку каждой подписке организации на этот тип.
+// Внутри транзакции запись фиксируется вместе с изменением. Без подписок событие не сохраняется
+func (w *webhookRepo) Enqueue(ctx context.Context, eventType string, data []byte) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := `WITH event AS (
+                INSERT INTO webhook_outbox (organization_id, event_type, payload)
+                SELECT $1::uuid, $2::text, $3::jsonb
+                WHERE EXISTS (SELECT 1 FROM webhooks WHERE organization_id = $1::uuid AND $2::text = ANY(event_types))
+                RETURNING id, organization_id, event_type)
+            INSERT INTO webhook_deliveries (webhook_id, outbox_id)
+              SELECT w.id, event.id FROM webhooks w JOIN event ON w.organization_id = event.organization_id
+              WHERE event.event_type = ANY(w.event_types)`
+  _, err = Conn(ctx, w.db).ExecContext(ctx, query, org, eventType, string(data))
+  if err != nil {
+    return fmt.Errorf("ошибка записи события вебхука: %v", err)
+  }
+  return nil
+}
+
+// Claim берет в работу до limit доставок, которым пора отправляться, по всем организациям.
+// На время lease доставка скрыта от других воркеров, если воркер упадет, ее возьмут снова
+func (w *webhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]dto.OutgoingWebhook, error) {
+  query := `UPDATE webhook_deliveries d
+              SET attempts = d.attempts + 1,
+                  next_attempt_at = NOW() + make_interval(secs => $2)
+              FROM webhooks w, webhook_outbox o
+              WHERE d.id IN (SELECT id FROM webhook_deliveries
+                              WHERE status = 'pending' AND next_attempt_at <= NOW()
+                              ORDER BY next_attempt_at
+                              LIMIT $1
+                              FOR UPDATE SKIP LOCKED)
+                AND w.id = d.webhook_id AND o.id = d.outbox_id
+              RETURNING d.id, d.attempts, w.url, w.secret, o.id AS outbox_id, o.organization_id,
+                        o.event_type, o.payload, o.created_at`
+  var claimed []claimedDelivery
+  if err := Conn(ctx, w.db).SelectContext(ctx, &claimed, query, limit, lease.Seconds()); err != nil {
+    return nil, fmt.Errorf("ошибка выборки доставок: %v", err)
+  }
+
+  res := make([]dto.OutgoingWebhook, 0, len(claimed))
+  for _, c := range claimed {
+    res = append(res, c.toDTO())
+  }
+  return res, nil
+}
+
+// claimedDelivery строка доставки, взятой в работу, вместе с подпиской и событием
+type claimedDelivery struct {
+  Id             int64     `db:"id"`
+  Attempts       int       `db:"attempts"`
+  Url            string    `db:"url"`
+  Secret         string    `db:"secret"`
+  OutboxId       int64     `db:"outbox_id"`
+  OrganizationId string    `db:"organization_id"`
+  EventType      string    `db:"event_type"`
+  Payload        []byte    `db:"payload"`
+  CreatedAt      time.Time `db:"created_at"`
+}
+
+func (c *claimedDelivery) toDTO() dto.OutgoingWebhook {
+  var out dto.OutgoingWebhook
+  out.DeliveryID = c.Id
+  out.Attempts = c.Attempts
+  out.URL = c.Url
+  out.Secret = c.Secret
+  out.Event.ID = strconv.FormatInt(c.OutboxId, 10)
+  out.Event.OrganizationID = c.OrganizationId
+  out.Event.Type = c.EventType
+  out.Event.Data = json.RawMessage(c.Payload)
+  out.Event.CreatedAt = c.CreatedAt
+  return out
+}
+
+func (w *webhookRepo) Delivered(ctx context.Context, id int64, status int) error {
+  query := `UPDATE webhook_deliveries
+              SET status = 'delivered', response_status = $2, last_error = NULL, delivered_at = NOW()
+              WHERE id = $1`
+  if _, err := Conn(ctx, w.db).ExecContext(ctx, query, id, status); err != nil {
+    return fmt.Errorf("ошибка обновления доставки: %v", err)
+  }
+  return nil
+}
+
+// Retry сохраняет итог неудачной попытки и назначает следующую через delay
+func (w *webhookRepo) Retry(ctx context.Context, id int64, status *int, reason string, delay time.Duration) error {
+  query := `UPDATE webhook_deliveries
+              SET response_status = $2, last_error = $3, next_attempt_at = NOW() + make_interval(secs => $4)
+              WHERE id = $1`
+  if _, err := Conn(ctx, w.db).ExecContext(ctx, query, id, status, reason, delay.Seconds()); err != nil {
+    return fmt.Errorf("ошибка обновления доставки: %v", err)
+  }
+  return nil
+}
+
+// Fail завершает доставку без успеха, повторов больше не будет
+func (w *webhookRepo) Fail(ctx context.Context, id int64, status *int, reason string) error {
+  query := `UPDATE webhook_deliveries SET status = 'failed', response_status = $2, last_error = $3 WHERE id = $1`
+  if _, err := Conn(ctx, w.db).ExecContext(ctx, query, id, status, reason); err != nil {
+    return fmt.Errorf("ошибка обновления доставки: %v", err)
+  }
+  return nil
+}
+
+// Cleanup удаляет события старше retention вместе с журналом их доставок
+func (w *webhookRepo) Cleanup(ctx context.Context, retention time.Duration) (int64, error) {
+  result, err := Conn(ctx, w.db).ExecContext(ctx,
+    "DELETE FROM webhook_outbox WHERE created_at < NOW() - make_interval(secs => $1)", retention.Seconds())
+  if err != nil {
+    return 0, fmt.Errorf("ошибка очистки событий вебхуков: %v", err)
+  }
+  return result.RowsAffected()
+}
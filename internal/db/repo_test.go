@@ -0,0 +1,39 @@
+package db_test
+
+import (
+  "context"
+  "os"
+  "testing"
+  "timetracker/internal/db"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/db/repotest"
+)
+
+// TestContract проверяет репозитории на настоящей базе, строка подключения задается
+// TEST_DATABASE_URL. Миграции применяются к ней, каждая проверка пишет в свою организацию
+func TestContract(t *testing.T) {
+  dsn := os.Getenv("TEST_DATABASE_URL")
+  if dsn == "" {
+    t.Skip("TEST_DATABASE_URL не задана")
+  }
+  m, err := db.NewMigrator(dsn)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if err = m.Up(); err != nil {
+    t.Fatal(err)
+  }
+  m.Close()
+  d, err := db.Open(dsn)
+  if err != nil {
+    t.Fatal(err)
+  }
+  repotest.Run(t, func(t *testing.T) *db.Store {
+    return &d.Store
+  }, func(t *testing.T, s *db.Store, ctx context.Context) {
+    // после ошибки запроса транзакция прервана, и фиксация вернет ошибку
+    if _, err := repo.TxFromCtx(ctx, d.DB()).ExecContext(ctx, "SELECT 1 / 0"); err == nil {
+      t.Fatal("ожидалась ошибка деления на ноль")
+    }
+  })
+}
@@ -0,0 +1,206 @@
+package repotest
+
+import (
+  "context"
+  "errors"
+  "github.com/google/uuid"
+  "testing"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils/cursor"
+)
+
+func testPeopleCRUD(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  if p.Version != 1 {
+    t.Fatalf("версия нового человека %d", p.Version)
+  }
+
+  got, err := s.People.GetByUUID(ctx, p.ID)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got.ID != p.ID || got.People != p.People || got.PassportNumber != p.PassportNumber || got.Version != 1 {
+    t.Fatalf("получено %+v, ожидалось %+v", got, p)
+  }
+  byPassport, err := s.People.GetByPassport(ctx, p.PassportNumber)
+  if err != nil || byPassport == nil || byPassport.ID != p.ID {
+    t.Fatalf("поиск по паспорту: %+v, %v", byPassport, err)
+  }
+
+  changed := *got
+  changed.Address = "Казань, ул. Баумана, 2"
+  updated, err := s.People.UpdatePerson(ctx, &changed)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if updated.Address != changed.Address || updated.Version != 2 {
+    t.Fatalf("после обновления %+v", updated)
+  }
+  // обновление по устаревшей версии не применяется
+  if _, err = s.People.UpdatePerson(ctx, &changed); !errors.Is(err, repo.ErrStale) {
+    t.Fatalf("ожидалась ErrStale, получено %v", err)
+  }
+
+  if err = s.People.DeleteByPerson(ctx, p.ID, 1); err == nil {
+    t.Fatal("удаление по устаревшей версии должно завершиться ошибкой")
+  }
+  if err = s.People.DeleteByPerson(ctx, p.ID, 2); err != nil {
+    t.Fatal(err)
+  }
+  if _, err = s.People.GetByUUID(ctx, p.ID); err == nil {
+    t.Fatal("удаленный человек найден")
+  }
+  if err = s.People.DeleteByPerson(ctx, p.ID, 0); err == nil {
+    t.Fatal("повторное удаление должно завершиться ошибкой")
+  }
+}
+
+func testPeopleNotFound(t *testing.T, s *db.Store, ctx context.Context) {
+  id := uuid.NewString()
+  if _, err := s.People.GetByUUID(ctx, id); err == nil {
+    t.Fatal("GetByUUID: ожидалась ошибка")
+  }
+  if p, err := s.People.GetByPassport(ctx, "0000 000000"); p != nil || err != nil {
+    t.Fatalf("GetByPassport: ожидалось отсутствие без ошибки, получено %+v, %v", p, err)
+  }
+  missing := &dto.Person{ID: id, Version: 1, People: dto.People{Surname: "Иванов", Name: "Иван"}}
+  if _, err := s.People.UpdatePerson(ctx, missing); !errors.Is(err, repo.ErrStale) {
+    t.Fatalf("UpdatePerson: ожидалась ErrStale, получено %v", err)
+  }
+  if err := s.People.DeleteByPerson(ctx, id, 0); err == nil {
+    t.Fatal("DeleteByPerson: ожидалась ошибка")
+  }
+}
+
+func testPeoplePagination(t *testing.T, s *db.Store, ctx context.Context) {
+  surnames := []string{"Дмитриев", "Алексеев", "Григорьев", "Васильев", "Борисов"}
+  for i, surname := range surnames {
+    createPerson(t, s, ctx, surname, "1000 00000"+string(rune('0'+i)))
+  }
+  want := []string{"Алексеев", "Борисов", "Васильев", "Григорьев", "Дмитриев"}
+
+  var got []string
+  page := dto.PageRequest{Limit: 2, Count: true}
+  for pages := 0; ; pages++ {
+    if pages == len(want) {
+      t.Fatal("курсор не заканчивается")
+    }
+    people, info, err := s.People.GetPeople(ctx, &dto.PeopleFilter{}, page)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if info.Total == nil || *info.Total != len(want) {
+      t.Fatalf("общее количество %v, ожидалось %d", info.Total, len(want))
+    }
+    for _, p := range people {
+      got = append(got, p.Surname)
+    }
+    if info.NextCursor == "" {
+      break
+    }
+    page.Cursor = info.NextCursor
+  }
+  if len(got) != len(want) {
+    t.Fatalf("получено %v, ожидалось %v", got, want)
+  }
+  for i := range want {
+    if got[i] != want[i] {
+      t.Fatalf("получено %v, ожидалось %v", got, want)
+    }
+  }
+
+  people, _, err := s.People.GetPeople(ctx, &dto.PeopleFilter{}, dto.PageRequest{Limit: 2, Offset: 3})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(people) != 2 || people[0].Surname != want[3] || people[1].Surname != want[4] {
+    t.Fatalf("страница со смещением: %+v", people)
+  }
+
+  if _, _, err = s.People.GetPeople(ctx, &dto.PeopleFilter{}, dto.PageRequest{Limit: 2, Cursor: "мусор"}); !errors.Is(err, cursor.ErrInvalid) {
+    t.Fatalf("ожидалась cursor.ErrInvalid, получено %v", err)
+  }
+}
+
+func testPeopleTenant(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  other := newTenant(t, s)
+  if _, err := s.People.GetByUUID(other, p.ID); err == nil {
+    t.Fatal("человек виден из другой организации")
+  }
+  people, _, err := s.People.GetPeople(other, &dto.PeopleFilter{}, dto.PageRequest{Limit: 10})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(people) != 0 {
+    t.Fatalf("в новой организации найдены люди: %+v", people)
+  }
+  // номер паспорта уникален только внутри организации
+  createPerson(t, s, other, "Иванов", "1234 567890")
+}
+
+// setManager назначает человеку p руководителя managerID
+func setManager(t *testing.T, s *db.Store, ctx context.Context, p *dto.Person, managerID string) {
+  t.Helper()
+  got, err := s.People.GetByUUID(ctx, p.ID)
+  if err != nil {
+    t.Fatal(err)
+  }
+  got.ManagerID = managerID
+  if _, err = s.People.UpdatePerson(ctx, got); err != nil {
+    t.Fatal(err)
+  }
+}
+
+func testPeopleMerge(t *testing.T, s *db.Store, ctx context.Context) {
+  boss := createPerson(t, s, ctx, "Сидоров", "1234 567890")
+  source := createPerson(t, s, ctx, "Иванов", "1234 567891")
+  target := createPerson(t, s, ctx, "Иванов", "1234 567892")
+  report := createPerson(t, s, ctx, "Петров", "1234 567893")
+  setManager(t, s, ctx, source, boss.ID)
+  setManager(t, s, ctx, target, source.ID)
+  setManager(t, s, ctx, report, source.ID)
+  task := createTask(t, s, ctx, source.ID, "отчет")
+
+  merge := func() (moved int64, err error) {
+    err = s.WithinTx(ctx, func(ctx context.Context) (err error) {
+      moved, err = s.People.MergePerson(ctx, source.ID, target.ID)
+      return err
+    })
+    return moved, err
+  }
+  moved, err := merge()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if moved != 1 {
+    t.Fatalf("перенесено задач %d", moved)
+  }
+  if got, err := s.Task.GetTask(ctx, task.IdTask); err != nil || got.IdPerson != target.ID {
+    t.Fatalf("задача после слияния: %+v, %v", got, err)
+  }
+  // подчиненные источника переходят к цели, сама цель - к руководителю источника
+  for _, c := range []struct{ id, manager string }{{report.ID, target.ID}, {target.ID, boss.ID}} {
+    got, err := s.People.GetByUUID(ctx, c.id)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if got.ManagerID != c.manager {
+      t.Fatalf("руководитель %s: %s, ожидался %s", c.id, got.ManagerID, c.manager)
+    }
+  }
+  if _, err = s.People.GetByUUID(ctx, source.ID); err == nil {
+    t.Fatal("источник слияния найден")
+  }
+
+  // повторное слияние и слияние в удаленного не проходят
+  if _, err = merge(); err == nil {
+    t.Fatal("повторное слияние: ожидалась ошибка")
+  }
+  source, target = target, source
+  if _, err = merge(); err == nil {
+    t.Fatal("слияние в удаленного: ожидалась ошибка")
+  }
+}
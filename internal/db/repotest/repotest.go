@@ -0,0 +1,107 @@
+// Package repotest общий набор проверок репозиториев людей, задач и интервалов, который должно
+// проходить каждое хранилище db.Store. Хранилища вызывают Run из своих тестов
+package repotest
+
+import (
+  "context"
+  "github.com/google/uuid"
+  "testing"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+  "timetracker/internal/utils"
+)
+
+// Open возвращает хранилище для очередной проверки. Хранилище может быть общим для проверок:
+// каждая работает в своей новой организации
+type Open func(t *testing.T) *db.Store
+
+// BreakCommit портит транзакцию хранилища s из ctx средствами самой базы так, что ее
+// фиксация завершится ошибкой. Хранилище, фиксация которого не ошибается, передает nil
+type BreakCommit func(t *testing.T, s *db.Store, ctx context.Context)
+
+// Run выполняет все проверки над хранилищами open
+func Run(t *testing.T, open Open, breakCommit BreakCommit) {
+  tests := []struct {
+    name string
+    run  func(t *testing.T, s *db.Store, ctx context.Context)
+  }{
+    {"PeopleCRUD", testPeopleCRUD},
+    {"PeopleNotFound", testPeopleNotFound},
+    {"PeoplePagination", testPeoplePagination},
+    {"PeopleTenant", testPeopleTenant},
+    {"PeopleMerge", testPeopleMerge},
+    {"TaskCRUD", testTaskCRUD},
+    {"TaskNotFound", testTaskNotFound},
+    {"TaskStaleVersion", testTaskStaleVersion},
+    {"TaskPagination", testTaskPagination},
+    {"TxCommit", testTxCommit},
+    {"TxRollback", testTxRollback},
+    {"TxNested", testTxNested},
+    {"TxCommitError", func(t *testing.T, s *db.Store, ctx context.Context) { testTxCommitError(t, s, ctx, breakCommit) }},
+    {"Timer", testTimer},
+    {"AddEntry", testAddEntry},
+    {"TaskTimesClipping", testTaskTimesClipping},
+    {"TeamTimes", testTeamTimes},
+  }
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      s := open(t)
+      test.run(t, s, newTenant(t, s))
+    })
+  }
+}
+
+// newTenant контекст администратора новой организации, чтобы проверки не видели данные друг друга
+func newTenant(t *testing.T, s *db.Store) context.Context {
+  t.Helper()
+  org, err := s.Org.CreateOrganization(context.Background(), "repotest "+uuid.NewString())
+  if err != nil {
+    t.Fatal(err)
+  }
+  ctx := utils.WithTenant(context.Background(), org.ID)
+  return utils.WithPrincipal(ctx, &dto.Principal{ID: "repotest", Method: dto.AuthApiKey, Role: dto.RoleAdmin})
+}
+
+func createPerson(t *testing.T, s *db.Store, ctx context.Context, surname, passport string) *dto.Person {
+  t.Helper()
+  p := &dto.Person{
+    People:   dto.People{Surname: surname, Name: "Иван", Patronymic: "Петрович", Address: "Москва, ул. Ленина, 1"},
+    Passport: dto.Passport{PassportNumber: passport},
+  }
+  id, err := s.People.CreatePerson(ctx, p)
+  if err != nil {
+    t.Fatal(err)
+  }
+  p.ID = id
+  return p
+}
+
+func createTask(t *testing.T, s *db.Store, ctx context.Context, person, name string) *dto.Task {
+  t.Helper()
+  task, err := s.Task.CreateTask(ctx, &dto.Task{IdPerson: person, TaskName: name})
+  if err != nil {
+    t.Fatal(err)
+  }
+  return task
+}
+
+// at время без часового пояса в формате отчетов
+func at(t *testing.T, s string) time.Time {
+  t.Helper()
+  res, err := time.Parse("2006-01-02 15:04:05", s)
+  if err != nil {
+    t.Fatal(err)
+  }
+  return res
+}
+
+func addEntry(t *testing.T, s *db.Store, ctx context.Context, task, start, end string) *dto.TimeTask {
+  t.Helper()
+  finish := at(t, end)
+  entry, err := s.TimeTask.AddEntry(ctx, &dto.TimeTask{IDTask: task, StartTime: at(t, start), EndTime: &finish})
+  if err != nil {
+    t.Fatal(err)
+  }
+  return entry
+}
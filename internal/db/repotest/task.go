@@ -0,0 +1,286 @@
+package repotest
+
+import (
+  "context"
+  "errors"
+  "github.com/google/uuid"
+  "testing"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils/const/status"
+)
+
+func testTaskCRUD(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  task := createTask(t, s, ctx, p.ID, "отчет")
+  if task.IdTask == "" || task.IdPerson != p.ID || task.TaskStatus != status.New || task.Version != 1 {
+    t.Fatalf("новая задача %+v", task)
+  }
+
+  got, err := s.Task.GetTask(ctx, task.IdTask)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if *got != *task {
+    t.Fatalf("получено %+v, ожидалось %+v", got, task)
+  }
+
+  if err = s.Task.UpdateStatus(ctx, task.IdTask, status.Work, 0); err != nil {
+    t.Fatal(err)
+  }
+  st, err := s.Task.GetTaskStatus(ctx, task.IdTask)
+  if err != nil {
+    t.Fatal(err)
+  }
+  got, err = s.Task.GetTask(ctx, task.IdTask)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if st != status.Work || got.TaskStatus != status.Work || got.Version != 2 {
+    t.Fatalf("после смены статуса: %s, %+v", st, got)
+  }
+}
+
+func testTaskNotFound(t *testing.T, s *db.Store, ctx context.Context) {
+  id := uuid.NewString()
+  if _, err := s.Task.GetTask(ctx, id); err == nil {
+    t.Fatal("GetTask: ожидалась ошибка")
+  }
+  if _, err := s.Task.GetTaskStatus(ctx, id); err == nil {
+    t.Fatal("GetTaskStatus: ожидалась ошибка")
+  }
+  if err := s.Task.UpdateStatus(ctx, id, status.Work, 0); err == nil {
+    t.Fatal("UpdateStatus: ожидалась ошибка")
+  }
+  if _, err := s.Task.CreateTask(ctx, &dto.Task{IdPerson: id, TaskName: "отчет"}); err == nil {
+    t.Fatal("CreateTask: задача несуществующего человека создана")
+  }
+
+  // задача другой организации не видна
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  task := createTask(t, s, ctx, p.ID, "отчет")
+  other := newTenant(t, s)
+  if _, err := s.Task.GetTask(other, task.IdTask); err == nil {
+    t.Fatal("задача видна из другой организации")
+  }
+  if err := s.Task.UpdateStatus(other, task.IdTask, status.Work, 0); err == nil {
+    t.Fatal("статус задачи изменен из другой организации")
+  }
+}
+
+func testTaskStaleVersion(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  task := createTask(t, s, ctx, p.ID, "отчет")
+  if err := s.Task.UpdateStatus(ctx, task.IdTask, status.Work, task.Version); err != nil {
+    t.Fatal(err)
+  }
+  // версия, прочитанная до смены статуса, устарела
+  err := s.Task.UpdateStatus(ctx, task.IdTask, status.Pause, task.Version)
+  if !errors.Is(err, repo.ErrStale) {
+    t.Fatalf("ожидалась ErrStale, получено %v", err)
+  }
+  got, err := s.Task.GetTask(ctx, task.IdTask)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got.TaskStatus != status.Work || got.Version != task.Version+1 {
+    t.Fatalf("устаревшая версия изменила задачу: %+v", got)
+  }
+}
+
+func testTaskPagination(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  other := createPerson(t, s, ctx, "Петров", "1234 567891")
+  var want []string
+  for _, name := range []string{"первая", "вторая", "третья", "четвертая", "пятая"} {
+    want = append(want, createTask(t, s, ctx, p.ID, name).IdTask)
+  }
+  createTask(t, s, ctx, other.ID, "чужая")
+
+  var got []string
+  page := dto.PageRequest{Limit: 2, Count: true}
+  for pages := 0; ; pages++ {
+    if pages == len(want) {
+      t.Fatal("курсор не заканчивается")
+    }
+    tasks, info, err := s.Task.GetTasks(ctx, p.ID, page)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if info.Total == nil || *info.Total != len(want) {
+      t.Fatalf("общее количество %v, ожидалось %d", info.Total, len(want))
+    }
+    for _, task := range tasks {
+      got = append(got, task.IdTask)
+    }
+    if info.NextCursor == "" {
+      break
+    }
+    page.Cursor = info.NextCursor
+  }
+  if len(got) != len(want) {
+    t.Fatalf("получено %v, ожидалось %v", got, want)
+  }
+  for i := range want {
+    if got[i] != want[i] {
+      t.Fatalf("задачи не в порядке создания: получено %v, ожидалось %v", got, want)
+    }
+  }
+
+  // первые страницы нескольких людей совпадают с GetTasks
+  tasks, infos, err := s.Task.GetTasksOfPeople(ctx, []string{p.ID, other.ID}, 2)
+  if err != nil {
+    t.Fatal(err)
+  }
+  first, info, err := s.Task.GetTasks(ctx, p.ID, dto.PageRequest{Limit: 2})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(tasks[p.ID]) != 2 || tasks[p.ID][0] != first[0] || tasks[p.ID][1] != first[1] || infos[p.ID].NextCursor != info.NextCursor {
+    t.Fatalf("GetTasksOfPeople: %+v, %+v", tasks[p.ID], infos[p.ID])
+  }
+  if len(tasks[other.ID]) != 1 || infos[other.ID].NextCursor != "" || *infos[other.ID].Total != 1 {
+    t.Fatalf("GetTasksOfPeople: %+v, %+v", tasks[other.ID], infos[other.ID])
+  }
+}
+
+func testTxCommit(t *testing.T, s *db.Store, ctx context.Context) {
+  committed := false
+  var task *dto.Task
+  err := s.WithinTx(ctx, func(ctx context.Context) error {
+    p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+    task = createTask(t, s, ctx, p.ID, "отчет")
+    s.AfterCommit(ctx, func() { committed = true })
+    if committed {
+      t.Fatal("AfterCommit выполнен до фиксации")
+    }
+    return nil
+  })
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  if !committed {
+    t.Fatal("AfterCommit не выполнен после фиксации")
+  }
+  if _, err = s.Task.GetTask(ctx, task.IdTask); err != nil {
+    t.Fatal(err)
+  }
+}
+
+// testTxCommitError ошибка фиксации доходит до вызывающего, а функции AfterCommit не выполняются
+func testTxCommitError(t *testing.T, s *db.Store, ctx context.Context, breakCommit BreakCommit) {
+  if breakCommit == nil {
+    t.Skip("фиксация транзакции хранилища не завершается ошибкой")
+  }
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  committed := false
+  var task *dto.Task
+  err := s.WithinTx(ctx, func(ctx context.Context) error {
+    task = createTask(t, s, ctx, p.ID, "отчет")
+    s.AfterCommit(ctx, func() { committed = true })
+    breakCommit(t, s, ctx)
+    return nil
+  })
+  if err == nil {
+    t.Fatal("ожидалась ошибка фиксации")
+  }
+  if committed {
+    t.Fatal("AfterCommit выполнен при ошибке фиксации")
+  }
+  if _, err = s.Task.GetTask(ctx, task.IdTask); err == nil {
+    t.Fatal("задача из незафиксированной транзакции найдена")
+  }
+  // соединение после ошибки фиксации пригодно для работы
+  createTask(t, s, ctx, p.ID, "после ошибки")
+}
+
+func testTxRollback(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  task := createTask(t, s, ctx, p.ID, "отчет")
+
+  committed := false
+  rollback := errors.New("откат")
+  var created *dto.Person
+  err := s.WithinTx(ctx, func(ctx context.Context) error {
+    created = createPerson(t, s, ctx, "Петров", "1234 567891")
+    if err := s.Task.UpdateStatus(ctx, task.IdTask, status.Work, 0); err != nil {
+      t.Fatal(err)
+    }
+    if err := s.TimeTask.StartTimer(ctx, task.IdTask); err != nil {
+      t.Fatal(err)
+    }
+    // внутри транзакции изменения видны
+    if st, err := s.Task.GetTaskStatus(ctx, task.IdTask); err != nil || st != status.Work {
+      t.Fatalf("статус внутри транзакции %s, %v", st, err)
+    }
+    s.AfterCommit(ctx, func() { committed = true })
+    return rollback
+  })
+  if !errors.Is(err, rollback) {
+    t.Fatalf("ожидалась ошибка fn, получено %v", err)
+  }
+
+  if committed {
+    t.Fatal("AfterCommit выполнен после отката")
+  }
+  if _, err = s.People.GetByUUID(ctx, created.ID); err == nil {
+    t.Fatal("человек из отмененной транзакции сохранен")
+  }
+  got, err := s.Task.GetTask(ctx, task.IdTask)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got.TaskStatus != status.New || got.Version != 1 {
+    t.Fatalf("статус задачи не откатился: %+v", got)
+  }
+  if err = s.TimeTask.StopTimer(ctx, task.IdTask); err == nil {
+    t.Fatal("таймер из отмененной транзакции сохранен")
+  }
+}
+
+// testTxNested ошибка вложенного вызова откатывает только его изменения и его AfterCommit,
+// внешняя транзакция продолжается и фиксируется
+func testTxNested(t *testing.T, s *db.Store, ctx context.Context) {
+  var outer, inner, kept *dto.Person
+  var hooks []string
+  rollback := errors.New("откат")
+  err := s.WithinTx(ctx, func(ctx context.Context) error {
+    outer = createPerson(t, s, ctx, "Иванов", "1234 567890")
+    s.AfterCommit(ctx, func() { hooks = append(hooks, "outer") })
+
+    err := s.WithinTx(ctx, func(ctx context.Context) error {
+      inner = createPerson(t, s, ctx, "Петров", "1234 567891")
+      s.AfterCommit(ctx, func() { hooks = append(hooks, "inner") })
+      return rollback
+    })
+    if !errors.Is(err, rollback) {
+      t.Fatalf("вложенный вызов вернул %v", err)
+    }
+    if _, err = s.People.GetByUUID(ctx, inner.ID); err == nil {
+      t.Fatal("изменения откаченного вложенного вызова видны в транзакции")
+    }
+
+    return s.WithinTx(ctx, func(ctx context.Context) error {
+      kept = createPerson(t, s, ctx, "Сидоров", "1234 567892")
+      s.AfterCommit(ctx, func() { hooks = append(hooks, "kept") })
+      return nil
+    })
+  })
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  for _, p := range []*dto.Person{outer, kept} {
+    if _, err = s.People.GetByUUID(ctx, p.ID); err != nil {
+      t.Fatalf("%s: %v", p.Surname, err)
+    }
+  }
+  if _, err = s.People.GetByUUID(ctx, inner.ID); err == nil {
+    t.Fatal("человек из откаченного вложенного вызова сохранен")
+  }
+  if len(hooks) != 2 || hooks[0] != "outer" || hooks[1] != "kept" {
+    t.Fatalf("выполнены AfterCommit %v", hooks)
+  }
+}
@@ -0,0 +1,185 @@
+package repotest
+
+import (
+  "context"
+  "testing"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db"
+)
+
+func testTimer(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  task := createTask(t, s, ctx, p.ID, "отчет")
+
+  if err := s.TimeTask.StopTimer(ctx, task.IdTask); err == nil {
+    t.Fatal("остановка без запущенного таймера должна завершиться ошибкой")
+  }
+  if err := s.TimeTask.StartTimer(ctx, task.IdTask); err != nil {
+    t.Fatal(err)
+  }
+  entries, err := s.TimeTask.GetEntries(ctx, []string{task.IdTask}, []string{p.ID})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got := entries[task.IdTask]; len(got) != 1 || got[0].EndTime != nil {
+    t.Fatalf("ожидался один открытый интервал, получено %+v", got)
+  }
+
+  if err = s.TimeTask.StopTimer(ctx, task.IdTask); err != nil {
+    t.Fatal(err)
+  }
+  entries, err = s.TimeTask.GetEntries(ctx, []string{task.IdTask}, []string{p.ID})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if got := entries[task.IdTask]; len(got) != 1 || got[0].EndTime == nil || got[0].EndTime.Before(got[0].StartTime) {
+    t.Fatalf("ожидался один закрытый интервал, получено %+v", got)
+  }
+  if err = s.TimeTask.StopTimer(ctx, task.IdTask); err == nil {
+    t.Fatal("повторная остановка должна завершиться ошибкой")
+  }
+}
+
+func testAddEntry(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  other := createPerson(t, s, ctx, "Петров", "1234 567891")
+  task := createTask(t, s, ctx, p.ID, "отчет")
+  foreign := createTask(t, s, ctx, other.ID, "чужая")
+
+  second := addEntry(t, s, ctx, task.IdTask, "2026-01-10 12:00:00", "2026-01-10 13:00:00")
+  first := addEntry(t, s, ctx, task.IdTask, "2026-01-10 09:00:00", "2026-01-10 10:30:00")
+  // смежный интервал не пересекается
+  third := addEntry(t, s, ctx, task.IdTask, "2026-01-10 13:00:00", "2026-01-10 13:15:00")
+  addEntry(t, s, ctx, foreign.IdTask, "2026-01-10 09:00:00", "2026-01-10 18:00:00")
+
+  finish := at(t, "2026-01-10 12:30:00")
+  overlapping := &dto.TimeTask{IDTask: task.IdTask, StartTime: at(t, "2026-01-10 10:00:00"), EndTime: &finish}
+  if _, err := s.TimeTask.AddEntry(ctx, overlapping); err == nil {
+    t.Fatal("пересекающийся интервал должен быть отклонен")
+  }
+
+  entries, err := s.TimeTask.GetEntries(ctx, []string{task.IdTask, foreign.IdTask}, []string{p.ID})
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(entries[foreign.IdTask]) != 0 {
+    t.Fatalf("получены интервалы задачи другого человека: %+v", entries[foreign.IdTask])
+  }
+  got := entries[task.IdTask]
+  want := []*dto.TimeTask{first, second, third}
+  if len(got) != len(want) {
+    t.Fatalf("получено %+v, ожидалось %d интервала", got, len(want))
+  }
+  for i := range want {
+    if got[i].ID != want[i].ID || !got[i].StartTime.Equal(want[i].StartTime) || !got[i].EndTime.Equal(*want[i].EndTime) {
+      t.Fatalf("интервал %d: получено %+v, ожидалось %+v", i, got[i], want[i])
+    }
+  }
+}
+
+// checkTimes сравнивает отчет с ожидаемым построчно
+func checkTimes(t *testing.T, got, want []dto.TaskTimeResult) {
+  t.Helper()
+  if len(got) != len(want) {
+    t.Fatalf("получено %+v, ожидалось %+v", got, want)
+  }
+  for i := range want {
+    if got[i] != want[i] {
+      t.Fatalf("строка %d: получено %+v, ожидалось %+v", i, got[i], want[i])
+    }
+  }
+}
+
+func testTaskTimesClipping(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  other := createPerson(t, s, ctx, "Петров", "1234 567891")
+  short := createTask(t, s, ctx, p.ID, "короткая")
+  long := createTask(t, s, ctx, p.ID, "длинная")
+  outside := createTask(t, s, ctx, p.ID, "вне периода")
+  foreign := createTask(t, s, ctx, other.ID, "чужая")
+
+  // начинается до периода и учитывается с его начала
+  addEntry(t, s, ctx, short.IdTask, "2026-01-09 22:00:00", "2026-01-10 01:30:00")
+  addEntry(t, s, ctx, short.IdTask, "2026-01-10 12:00:00", "2026-01-10 12:30:30.5")
+  // заканчивается после периода и учитывается до его конца
+  addEntry(t, s, ctx, short.IdTask, "2026-01-11 23:00:00", "2026-01-12 02:00:00")
+  // накрывает весь период
+  addEntry(t, s, ctx, long.IdTask, "2026-01-09 00:00:00", "2026-01-13 00:00:00")
+  addEntry(t, s, ctx, outside.IdTask, "2026-01-01 09:00:00", "2026-01-01 10:00:00")
+  addEntry(t, s, ctx, outside.IdTask, "2026-01-13 09:00:00", "2026-01-13 10:00:00")
+  addEntry(t, s, ctx, foreign.IdTask, "2026-01-10 09:00:00", "2026-01-10 18:00:00")
+  // открытый интервал в отчеты не попадает
+  if err := s.TimeTask.StartTimer(ctx, short.IdTask); err != nil {
+    t.Fatal(err)
+  }
+
+  res, err := s.Task.TaskTimes(ctx, p.ID, "2026-01-10", "2026-01-12")
+  if err != nil {
+    t.Fatal(err)
+  }
+  checkTimes(t, res, []dto.TaskTimeResult{
+    {IDTask: long.IdTask, TaskName: "длинная", TotalTime: "2 days"},
+    {IDTask: short.IdTask, TaskName: "короткая", TotalTime: "03:00:30.5"},
+  })
+
+  res, err = s.Task.TaskTimes(ctx, p.ID, "2000-01-01", "2100-01-01")
+  if err != nil {
+    t.Fatal(err)
+  }
+  checkTimes(t, res, []dto.TaskTimeResult{
+    {IDTask: long.IdTask, TaskName: "длинная", TotalTime: "4 days"},
+    {IDTask: short.IdTask, TaskName: "короткая", TotalTime: "07:00:30.5"},
+    {IDTask: outside.IdTask, TaskName: "вне периода", TotalTime: "02:00:00"},
+  })
+
+  res, err = s.Task.TaskTimes(ctx, p.ID, "2025-01-01", "2025-12-31")
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(res) != 0 {
+    t.Fatalf("за пустой период получено %+v", res)
+  }
+}
+
+func testTeamTimes(t *testing.T, s *db.Store, ctx context.Context) {
+  p := createPerson(t, s, ctx, "Иванов", "1234 567890")
+  q := createPerson(t, s, ctx, "Петров", "1234 567891")
+  outsider := createPerson(t, s, ctx, "Сидоров", "1234 567892")
+  x := createTask(t, s, ctx, p.ID, "x")
+  y := createTask(t, s, ctx, p.ID, "y")
+  z := createTask(t, s, ctx, q.ID, "z")
+  w := createTask(t, s, ctx, outsider.ID, "w")
+  addEntry(t, s, ctx, x.IdTask, "2026-01-10 09:00:00", "2026-01-10 11:00:00")
+  addEntry(t, s, ctx, y.IdTask, "2026-01-10 12:00:00", "2026-01-10 12:30:00")
+  addEntry(t, s, ctx, z.IdTask, "2026-01-09 23:00:00", "2026-01-10 05:00:00")
+  addEntry(t, s, ctx, w.IdTask, "2026-01-10 09:00:00", "2026-01-10 18:00:00")
+
+  team, err := s.Team.CreateTeam(ctx, &dto.Team{Name: "команда"})
+  if err != nil {
+    t.Fatal(err)
+  }
+  for _, member := range []string{p.ID, q.ID} {
+    if err = s.Team.AddMember(ctx, team.ID, member); err != nil {
+      t.Fatal(err)
+    }
+  }
+
+  res, err := s.Task.TeamTimes(ctx, team.ID, "2026-01-10", "2026-01-11")
+  if err != nil {
+    t.Fatal(err)
+  }
+  if len(res) != 2 {
+    t.Fatalf("получено %+v, ожидалось два участника", res)
+  }
+  if res[0].IDPerson != q.ID || res[0].Surname != "Петров" || res[0].TotalTime != "05:00:00" {
+    t.Fatalf("первый участник %+v", res[0])
+  }
+  checkTimes(t, res[0].Tasks, []dto.TaskTimeResult{{IDTask: z.IdTask, TaskName: "z", TotalTime: "05:00:00"}})
+  if res[1].IDPerson != p.ID || res[1].TotalTime != "02:30:00" {
+    t.Fatalf("второй участник %+v", res[1])
+  }
+  checkTimes(t, res[1].Tasks, []dto.TaskTimeResult{
+    {IDTask: x.IdTask, TaskName: "x", TotalTime: "02:00:00"},
+    {IDTask: y.IdTask, TaskName: "y", TotalTime: "00:30:00"},
+  })
+}
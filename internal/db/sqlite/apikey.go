@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+  "context"
+  "database/sql"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type apiKey struct {
+  Id         string     `db:"id"`
+  Name       string     `db:"name"`
+  PersonId   *string    `db:"person_id"`
+  OrgId      *string    `db:"organization_id"`
+  KeyHash    string     `db:"key_hash"`
+  Prefix     string     `db:"prefix"`
+  Role       string     `db:"role"`
+  CreatedAt  time.Time  `db:"created_at"`
+  LastUsedAt *time.Time `db:"last_used_at"`
+}
+
+func (k *apiKey) toDTO() *dto.ApiKey {
+  res := &dto.ApiKey{
+    ID:         k.Id,
+    Name:       k.Name,
+    Role:       k.Role,
+    Prefix:     k.Prefix,
+    CreatedAt:  k.CreatedAt,
+    LastUsedAt: k.LastUsedAt,
+  }
+  if k.PersonId != nil {
+    res.PersonID = *k.PersonId
+  }
+  if k.OrgId != nil {
+    res.OrganizationID = *k.OrgId
+  }
+  return res
+}
+
+const apiKeyColumns = "id, name, person_id, organization_id, key_hash, prefix, role, created_at, last_used_at"
+
+type apiKeyRepo struct {
+  db *sqlx.DB
+}
+
+// CreateKey сохраняет хеш нового API-ключа
+func (a *apiKeyRepo) CreateKey(ctx context.Context, key *dto.ApiKey, hash string) (*dto.ApiKey, error) {
+  query := `INSERT INTO api_keys (id, name, person_id, organization_id, key_hash, prefix, role, created_at)
+              VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)
+              RETURNING ` + apiKeyColumns
+  var created apiKey
+  err := conn(ctx, a.db).GetContext(ctx, &created, query, uuid.NewString(), key.Name, nullable(key.PersonID),
+    nullable(key.OrganizationID), hash, key.Prefix, key.Role, now())
+  if err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+// GetByHash ищет ключ по хешу во всех организациях, nil если ключа нет
+func (a *apiKeyRepo) GetByHash(ctx context.Context, hash string) (*dto.ApiKey, error) {
+  var key apiKey
+  err := conn(ctx, a.db).GetContext(ctx, &key, "SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ?1", hash)
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("ошибка бд: %v", err)
+  }
+  return key.toDTO(), nil
+}
+
+func (a *apiKeyRepo) GetKeys(ctx context.Context) ([]dto.ApiKey, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE organization_id = ?1 ORDER BY created_at"
+  var keys []apiKey
+  if err = conn(ctx, a.db).SelectContext(ctx, &keys, query, org); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.ApiKey, 0, len(keys))
+  for _, key := range keys {
+    res = append(res, *key.toDTO())
+  }
+  return res, nil
+}
+
+func (a *apiKeyRepo) DeleteKey(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  result, err := conn(ctx, a.db).ExecContext(ctx, "DELETE FROM api_keys WHERE id = ?1 AND organization_id = ?2", id, org)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления ключа: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("ключ с id %s не найден", id)
+  }
+  return nil
+}
+
+// TouchKey отмечает время последнего использования ключа
+func (a *apiKeyRepo) TouchKey(ctx context.Context, id string) error {
+  _, err := conn(ctx, a.db).ExecContext(ctx, "UPDATE api_keys SET last_used_at = ?2 WHERE id = ?1", id, now())
+  if err != nil {
+    return fmt.Errorf("ошибка обновления ключа: %v", err)
+  }
+  return nil
+}
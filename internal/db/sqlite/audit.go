@@ -0,0 +1,34 @@
+package sqlite
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "timetracker/internal/bl/dto"
+)
+
+type auditRepo struct {
+  db *sqlx.DB
+}
+
+// Write сохраняет запись аудита, внутри транзакции пишет в нее
+func (a *auditRepo) Write(ctx context.Context, record *dto.AuditRecord) (string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", err
+  }
+  details, err := json.Marshal(record.Details)
+  if err != nil {
+    return "", fmt.Errorf("ошибка сериализации записи аудита: %v", err)
+  }
+  id := uuid.NewString()
+  query := `INSERT INTO audit_log (id, organization_id, actor, action, entity, entity_id, details, created_at)
+              VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)`
+  _, err = conn(ctx, a.db).ExecContext(ctx, query, id, org, record.Actor, record.Action, record.Entity, record.EntityID, string(details), now())
+  if err != nil {
+    return "", fmt.Errorf("ошибка записи аудита: %v", err)
+  }
+  return id, nil
+}
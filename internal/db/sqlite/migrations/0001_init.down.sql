@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_outbox;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS team_members;
+DROP TABLE IF EXISTS teams;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS timetask;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS person;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,146 @@
+-- id генерируются в приложении, время хранится текстом 'YYYY-MM-DD HH:MM:SS.ffffff'
+-- без часового пояса, как TIMESTAMP в Postgres: такие строки сравниваются как время
+
+CREATE TABLE organizations (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO organizations (id, name, created_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', strftime('%Y-%m-%d %H:%M:%f000', 'now', 'localtime'));
+
+CREATE TABLE person (
+    id TEXT PRIMARY KEY,
+    surname TEXT NOT NULL,
+    name TEXT NOT NULL,
+    patronymic TEXT,
+    address TEXT NOT NULL,
+    passport_number TEXT NOT NULL,
+    manager_id TEXT REFERENCES person (id) ON DELETE SET NULL,
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    created_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX idx_person_organization_passport ON person(organization_id, passport_number) WHERE deleted_at IS NULL;
+CREATE INDEX idx_person_manager_id ON person(manager_id);
+CREATE INDEX idx_person_org_surname_name_id ON person(organization_id, surname, name, id);
+CREATE INDEX idx_person_org_created_id ON person(organization_id, created_at, id);
+
+CREATE TABLE tasks (
+    idtask TEXT PRIMARY KEY,
+    idperson TEXT NOT NULL REFERENCES person (id),
+    task_name TEXT NOT NULL,
+    task_status TEXT NOT NULL CHECK (task_status IN ('new', 'work', 'pause', 'complete')),
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    created_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX idx_tasks_person_created_id ON tasks(idperson, created_at, idtask);
+CREATE INDEX idx_tasks_person_active ON tasks(idperson) WHERE task_status = 'work';
+
+CREATE TABLE timetask (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    idtask TEXT REFERENCES tasks (idtask),
+    start_time TIMESTAMP NOT NULL,
+    end_time TIMESTAMP,
+    organization_id TEXT NOT NULL REFERENCES organizations (id)
+);
+
+CREATE INDEX idx_timetask_idtask ON timetask(idtask, start_time);
+
+CREATE TABLE api_keys (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    person_id TEXT REFERENCES person (id) ON DELETE CASCADE,
+    organization_id TEXT REFERENCES organizations (id),
+    key_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'self',
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP
+);
+
+CREATE TABLE teams (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    name TEXT NOT NULL,
+    manager_id TEXT REFERENCES person (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (organization_id, name)
+);
+
+CREATE TABLE team_members (
+    team_id TEXT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    person_id TEXT NOT NULL REFERENCES person (id) ON DELETE CASCADE,
+    PRIMARY KEY (team_id, person_id)
+);
+
+CREATE INDEX idx_teams_manager_id ON teams(manager_id);
+CREATE INDEX idx_team_members_person_id ON team_members(person_id);
+
+CREATE TABLE audit_log (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    actor TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(organization_id, entity, entity_id);
+
+CREATE TABLE idempotency_keys (
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    actor TEXT NOT NULL,
+    key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status INTEGER,
+    headers BLOB,
+    response BLOB,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (organization_id, actor, key)
+);
+
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+
+-- event_types - json массив типов событий
+CREATE TABLE webhooks (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_webhooks_organization_id ON webhooks(organization_id);
+
+CREATE TABLE webhook_outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    organization_id TEXT NOT NULL REFERENCES organizations (id),
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id TEXT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    outbox_id INTEGER NOT NULL REFERENCES webhook_outbox (id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    response_status INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
@@ -0,0 +1,8 @@
+// Package migrations схема SQLite, встроенная в бинарник. Повторяет схему Postgres
+// из internal/db/migrations без расширений, политик RLS и поискового индекса
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
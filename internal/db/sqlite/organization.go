@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+  "context"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type organization struct {
+  Id        string    `db:"id"`
+  Name      string    `db:"name"`
+  CreatedAt time.Time `db:"created_at"`
+}
+
+func (o *organization) toDTO() *dto.Organization {
+  return &dto.Organization{ID: o.Id, Name: o.Name, CreatedAt: o.CreatedAt}
+}
+
+type organizationRepo struct {
+  db *sqlx.DB
+}
+
+func (o *organizationRepo) CreateOrganization(ctx context.Context, name string) (*dto.Organization, error) {
+  query := "INSERT INTO organizations (id, name, created_at) VALUES (?1, ?2, ?3) RETURNING id, name, created_at"
+  var org organization
+  if err := conn(ctx, o.db).GetContext(ctx, &org, query, uuid.NewString(), name, now()); err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  return org.toDTO(), nil
+}
+
+func (o *organizationRepo) GetOrganizations(ctx context.Context) ([]dto.Organization, error) {
+  var orgs []organization
+  err := conn(ctx, o.db).SelectContext(ctx, &orgs, "SELECT id, name, created_at FROM organizations ORDER BY name")
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Organization, 0, len(orgs))
+  for _, org := range orgs {
+    res = append(res, *org.toDTO())
+  }
+  return res, nil
+}
@@ -0,0 +1,508 @@
+package sqlite
+
+import (
+  "context"
+  "database/sql"
+  "encoding/json"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db/query"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+  "timetracker/internal/utils/cursor"
+)
+
+type person struct {
+  Id             string         `db:"id"`
+  Surname        string         `db:"surname"`
+  Name           string         `db:"name"`
+  Patronymic     sql.NullString `db:"patronymic"`
+  Address        string         `db:"address"`
+  PassportNumber string         `db:"passport_number"`
+  ManagerId      *string        `db:"manager_id"`
+  CreatedAt      time.Time      `db:"created_at"`
+  Version        int            `db:"version"`
+}
+
+func (p *person) toDTO() *dto.Person {
+  res := &dto.Person{
+    ID:       p.Id,
+    Version:  p.Version,
+    People:   dto.People{Surname: p.Surname, Name: p.Name, Patronymic: p.Patronymic.String, Address: p.Address},
+    Passport: dto.Passport{PassportNumber: p.PassportNumber},
+  }
+  if p.ManagerId != nil {
+    res.ManagerID = *p.ManagerId
+  }
+  return res
+}
+
+// nullable пустая строка как NULL
+func nullable(s string) interface{} {
+  if s == "" {
+    return nil
+  }
+  return s
+}
+
+// jsonArray список для условия IN (SELECT value FROM json_each(?)), замена = ANY($1) Postgres
+func jsonArray(values []string) string {
+  if values == nil {
+    values = []string{}
+  }
+  res, _ := json.Marshal(values)
+  return string(res)
+}
+
+const personColumns = "id, surname, name, patronymic, address, passport_number, manager_id, version, created_at"
+
+type peopleRepo struct {
+  db *sqlx.DB
+}
+
+// GetByPassport ищет человека по номеру паспорта
+func (p *peopleRepo) GetByPassport(ctx context.Context, passport string) (*dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := "SELECT " + personColumns + " FROM person WHERE passport_number = ?1 AND organization_id = ?2 AND deleted_at IS NULL"
+  var row person
+  err = conn(ctx, p.db).GetContext(ctx, &row, query, passport, org)
+  if err != nil {
+    if err == sql.ErrNoRows {
+      return nil, nil
+    }
+    return nil, fmt.Errorf("ошибка бд: %v", err)
+  }
+  return row.toDTO(), nil
+}
+
+// CreatePerson создает новую запись о человеке в базе данных
+func (p *peopleRepo) CreatePerson(ctx context.Context, model *dto.Person) (string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", err
+  }
+  id := uuid.NewString()
+  query := `INSERT INTO person (id, surname, name, patronymic, address, passport_number, manager_id, organization_id, created_at)
+              VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)`
+  _, err = conn(ctx, p.db).ExecContext(ctx, query, id, model.Surname, model.Name, nullable(model.Patronymic), model.Address,
+    model.PassportNumber, nullable(model.ManagerID), org, now())
+  if err != nil {
+    return "", fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  model.Version = 1
+  return id, nil
+}
+
+// DeleteByPerson удаляет человека из таблицы, при ненулевой version только эту версию записи
+func (p *peopleRepo) DeleteByPerson(ctx context.Context, uuid string, version int) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := "DELETE FROM person WHERE id = ?1 AND organization_id = ?2 AND deleted_at IS NULL AND (?3 = 0 OR version = ?3)"
+  result, err := conn(ctx, p.db).ExecContext(ctx, query, uuid, org, version)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления человека: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("человек с UUID %s не найден", uuid)
+  }
+  return nil
+}
+
+// GetByUUID ищет человека по UUID
+func (p *peopleRepo) GetByUUID(ctx context.Context, uuid string) (*dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := "SELECT " + personColumns + " FROM person WHERE id = ?1 AND organization_id = ?2 AND deleted_at IS NULL"
+  var row person
+  err = conn(ctx, p.db).GetContext(ctx, &row, query, uuid, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("человек с uuid %s не найден", uuid)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  return row.toDTO(), nil
+}
+
+// UpdatePerson обновляет запись о человеке, если ее версия все еще person.Version
+func (p *peopleRepo) UpdatePerson(ctx context.Context, model *dto.Person) (*dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `UPDATE person
+              SET surname = ?1, name = ?2, patronymic = ?3, address = ?4, passport_number = ?5, manager_id = ?6,
+                  version = version + 1
+              WHERE id = ?7 AND organization_id = ?8 AND deleted_at IS NULL AND version = ?9
+              RETURNING ` + personColumns
+  var updated person
+  err = conn(ctx, p.db).GetContext(ctx, &updated, query, model.Surname, model.Name, nullable(model.Patronymic),
+    model.Address, model.PassportNumber, nullable(model.ManagerID), model.ID, org, model.Version)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("%w: человек с UUID %s не найден или изменен", repo.ErrStale, model.ID)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка обновления данных в базе: %v", err)
+  }
+  return updated.toDTO(), nil
+}
+
+// peopleSort колонки сортировки списка людей
+var peopleSort = map[string]string{
+  "surname":    "surname",
+  "name":       "name",
+  "patronymic": "COALESCE(patronymic, '')",
+  "created_at": "created_at",
+}
+
+var peopleDefaultSort = []dto.SortField{{Field: "surname"}, {Field: "name"}}
+
+// sortValue значение поля сортировки записи для курсора
+func (p *person) sortValue(field string) string {
+  switch field {
+  case "surname":
+    return p.Surname
+  case "name":
+    return p.Name
+  case "patronymic":
+    return p.Patronymic.String
+  case "created_at":
+    return p.CreatedAt.Format(time.RFC3339Nano)
+  }
+  return p.Id
+}
+
+// peopleWhere собирает условия отбора людей организации org
+func peopleWhere(org string, filter *dto.PeopleFilter) *query.Builder {
+  b := &query.Builder{Dialect: query.SQLite}
+  b.Where("organization_id = ?", org)
+  b.Where("deleted_at IS NULL")
+  if filter.ID != "" {
+    b.Where("id = ?", filter.ID)
+  }
+  if filter.ManagerID != "" {
+    b.Where(`(manager_id = ? OR id = ?
+                     OR id IN (SELECT m.person_id FROM team_members m JOIN teams t ON t.id = m.team_id
+                                WHERE t.manager_id = ?))`, filter.ManagerID, filter.ManagerID, filter.ManagerID)
+  }
+  if filter.TeamID != "" {
+    b.Where("id IN (SELECT person_id FROM team_members WHERE team_id = ?)", filter.TeamID)
+  }
+  b.MatchAny("surname", filter.Surname)
+  b.MatchAny("name", filter.Name)
+  b.MatchAny("patronymic", filter.Patronymic)
+  b.Match("address", filter.Address)
+  b.MatchAny("passport_number", filter.PassportNumber)
+  if filter.HasActiveTask != nil {
+    active := "EXISTS (SELECT 1 FROM tasks WHERE tasks.idperson = person.id AND tasks.task_status = ?)"
+    if !*filter.HasActiveTask {
+      active = "NOT " + active
+    }
+    b.Where(active, status.Work)
+  }
+  if filter.CreatedAfter != nil {
+    b.Where("created_at > ?", stamp(*filter.CreatedAfter))
+  }
+  return b
+}
+
+// GetPeople возвращает страницу людей по фильтру в порядке filter.Sort (по умолчанию surname, name),
+// id замыкает сортировку и делает курсор однозначным
+func (p *peopleRepo) GetPeople(ctx context.Context, filter *dto.PeopleFilter, page dto.PageRequest) ([]dto.Person, dto.PageInfo, error) {
+  var info dto.PageInfo
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, info, err
+  }
+
+  sort := filter.Sort
+  if len(sort) == 0 {
+    sort = peopleDefaultSort
+  }
+  orders := make([]query.Order, 0, len(sort)+1)
+  for _, field := range sort {
+    column, ok := peopleSort[field.Field]
+    if !ok {
+      return nil, info, fmt.Errorf("сортировка по полю %s не поддерживается", field.Field)
+    }
+    orders = append(orders, query.Order{Column: column, Desc: field.Desc})
+  }
+  orders = append(orders, query.Order{Column: "id"})
+  sortKey := dto.SortKey(sort)
+
+  b := peopleWhere(org, filter)
+  countWhere, countArgs := b.Sql(), b.Args()
+  if page.Cursor != "" {
+    after, err := cursor.Decode(page.Cursor, len(orders)+1)
+    if err != nil || after[0] != sortKey || !utils.IsValidUUID(after[len(after)-1]) {
+      return nil, info, cursor.ErrInvalid
+    }
+    values := make([]interface{}, len(orders))
+    for i, field := range sort {
+      values[i] = after[i+1]
+      if field.Field == "created_at" {
+        createdAt, err := time.Parse(time.RFC3339Nano, after[i+1])
+        if err != nil {
+          return nil, info, cursor.ErrInvalid
+        }
+        values[i] = stamp(createdAt)
+      }
+    }
+    values[len(values)-1] = after[len(after)-1]
+    b.After(orders, values)
+  }
+
+  peopleQuery := `SELECT ` + personColumns + `
+              FROM person
+              ` + b.Sql() + `
+              ` + query.OrderBy(orders) + `
+              LIMIT ` + b.Arg(page.Limit+1) + ` OFFSET ` + b.Arg(page.Offset)
+
+  var rows []person
+  if err = conn(ctx, p.db).SelectContext(ctx, &rows, peopleQuery, b.Args()...); err != nil {
+    return nil, info, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  // лишняя запись означает, что есть следующая страница
+  if len(rows) > page.Limit {
+    rows = rows[:page.Limit]
+    last := rows[len(rows)-1]
+    values := []string{sortKey}
+    for _, field := range sort {
+      values = append(values, last.sortValue(field.Field))
+    }
+    info.NextCursor = cursor.Encode(append(values, last.Id)...)
+  }
+
+  people := make([]dto.Person, 0, len(rows))
+  for _, row := range rows {
+    people = append(people, *row.toDTO())
+  }
+  if !page.Count {
+    return people, info, nil
+  }
+
+  var total int
+  err = conn(ctx, p.db).GetContext(ctx, &total, "SELECT COUNT(*) FROM person "+countWhere, countArgs...)
+  if err != nil {
+    return nil, info, fmt.Errorf("ошибка получения общего количества записей: %v", err)
+  }
+  info.Total = &total
+  return people, info, nil
+}
+
+// searchText текст человека, по которому идет поиск и выделение совпадений
+const searchText = "surname || ' ' || name || ' ' || coalesce(patronymic, '') || ', ' || address"
+
+// SearchPeople ищет людей по префиксам слов ФИО и адреса или по похожести строки с порогом 0.6,
+// как оператор <% Postgres. Совпадение по префиксам получает ранг 1, иначе ранг - похожесть
+func (p *peopleRepo) SearchPeople(ctx context.Context, filter *dto.PeopleFilter, q string, limit int) ([]dto.PersonMatch, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  b := peopleWhere(org, filter)
+  text := b.Arg(q)
+  b.Where("(prefix_match(" + text + ", " + searchText + ") OR word_similarity(lower(" + text + "), " + searchText + ") >= 0.6)")
+
+  searchQuery := `SELECT ` + personColumns + `,
+                     CASE WHEN prefix_match(` + text + `, ` + searchText + `) THEN 1.0
+                          ELSE word_similarity(lower(` + text + `), ` + searchText + `) END AS rank,
+                     highlight(` + searchText + `, ` + text + `) AS highlight
+              FROM person
+              ` + b.Sql() + `
+              ORDER BY rank DESC, surname, name, id
+              LIMIT ` + b.Arg(limit)
+
+  var rows []struct {
+    person
+    Rank      float64 `db:"rank"`
+    Highlight string  `db:"highlight"`
+  }
+  if err = conn(ctx, p.db).SelectContext(ctx, &rows, searchQuery, b.Args()...); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.PersonMatch, 0, len(rows))
+  for _, row := range rows {
+    res = append(res, dto.PersonMatch{Person: *row.toDTO(), Rank: row.Rank, Highlight: row.Highlight})
+  }
+  return res, nil
+}
+
+// FindDuplicates ищет пары людей с одинаковым ФИО без учета регистра и похожим адресом
+func (p *peopleRepo) FindDuplicates(ctx context.Context, threshold float64, limit int) ([]dto.Duplicate, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT a.id AS "first.id", a.surname AS "first.surname", a.name AS "first.name",
+                   a.patronymic AS "first.patronymic", a.address AS "first.address",
+                   a.passport_number AS "first.passport_number", a.manager_id AS "first.manager_id",
+                   a.version AS "first.version", a.created_at AS "first.created_at",
+                   b.id AS "second.id", b.surname AS "second.surname", b.name AS "second.name",
+                   b.patronymic AS "second.patronymic", b.address AS "second.address",
+                   b.passport_number AS "second.passport_number", b.manager_id AS "second.manager_id",
+                   b.version AS "second.version", b.created_at AS "second.created_at",
+                   similarity(a.address, b.address) AS address_similarity
+              FROM person a
+              JOIN person b ON b.organization_id = a.organization_id
+                           AND a.id < b.id
+                           AND lower(b.surname) = lower(a.surname)
+                           AND lower(b.name) = lower(a.name)
+                           AND lower(coalesce(b.patronymic, '')) = lower(coalesce(a.patronymic, ''))
+              WHERE a.organization_id = ?1
+                AND a.deleted_at IS NULL
+                AND b.deleted_at IS NULL
+                AND similarity(a.address, b.address) >= ?2
+              ORDER BY address_similarity DESC, a.surname, a.name, a.id, b.id
+              LIMIT ?3`
+
+  var rows []struct {
+    First             person  `db:"first"`
+    Second            person  `db:"second"`
+    AddressSimilarity float64 `db:"address_similarity"`
+  }
+  if err = conn(ctx, p.db).SelectContext(ctx, &rows, query, org, threshold, limit); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Duplicate, 0, len(rows))
+  for _, row := range rows {
+    res = append(res, dto.Duplicate{First: *row.First.toDTO(), Second: *row.Second.toDTO(), AddressSimilarity: row.AddressSimilarity})
+  }
+  return res, nil
+}
+
+// MergePerson переносит задачи (вместе с их временем), подчиненных, команды и ключи
+// человека sourceID к targetID и помечает источник удаленным. Вызывается внутри транзакции
+func (p *peopleRepo) MergePerson(ctx context.Context, sourceID, targetID string) (int64, error) {
+  tx := repo.TxFromCtx(ctx, p.db)
+  if tx == nil {
+    return 0, fmt.Errorf("слияние людей требует транзакции")
+  }
+  org, err := tenant(ctx)
+  if err != nil {
+    return 0, err
+  }
+
+  // блокировка не нужна: транзакции с записью выполняются по очереди
+  for _, id := range []string{sourceID, targetID} {
+    var found int
+    err = tx.GetContext(ctx, &found, "SELECT COUNT(*) FROM person WHERE id = ?1 AND organization_id = ?2 AND deleted_at IS NULL", id, org)
+    if err != nil {
+      return 0, fmt.Errorf("ошибка получения человека из базы данных: %v", err)
+    }
+    if found == 0 {
+      return 0, fmt.Errorf("человек с UUID %s не найден", id)
+    }
+  }
+
+  result, err := tx.ExecContext(ctx, "UPDATE tasks SET idperson = ?2 WHERE idperson = ?1 AND organization_id = ?3", sourceID, targetID, org)
+  if err != nil {
+    return 0, fmt.Errorf("ошибка переноса задач: %v", err)
+  }
+  moved, err := result.RowsAffected()
+  if err != nil {
+    return 0, fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+
+  statements := []string{
+    "UPDATE person SET manager_id = ?2 WHERE manager_id = ?1 AND id <> ?2 AND organization_id = ?3",
+    // цель, подчиненная источнику, получает его руководителя, а не саму себя
+    `UPDATE person SET manager_id = NULLIF((SELECT manager_id FROM person WHERE id = ?1), ?2)
+      WHERE id = ?2 AND manager_id = ?1 AND organization_id = ?3`,
+    "UPDATE teams SET manager_id = ?2 WHERE manager_id = ?1 AND organization_id = ?3",
+    "UPDATE api_keys SET person_id = ?2 WHERE person_id = ?1 AND organization_id = ?3",
+    `INSERT INTO team_members (team_id, person_id)
+       SELECT m.team_id, ?2 FROM team_members m JOIN teams t ON t.id = m.team_id
+        WHERE m.person_id = ?1 AND t.organization_id = ?3
+     ON CONFLICT DO NOTHING`,
+    "DELETE FROM team_members WHERE person_id = ?1 AND ?2 <> '' AND ?3 <> ''",
+  }
+  for _, statement := range statements {
+    if _, err = tx.ExecContext(ctx, statement, sourceID, targetID, org); err != nil {
+      return 0, fmt.Errorf("ошибка переноса связей человека: %v", err)
+    }
+  }
+
+  result, err = tx.ExecContext(ctx, "UPDATE person SET deleted_at = ?3, manager_id = NULL WHERE id = ?1 AND organization_id = ?2 AND deleted_at IS NULL", sourceID, org, now())
+  if err != nil {
+    return 0, fmt.Errorf("ошибка удаления человека: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return 0, fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return 0, fmt.Errorf("человек с UUID %s не найден", sourceID)
+  }
+  return moved, nil
+}
+
+// managedBy условие: человеком руководит ?2 напрямую или как руководитель его команды
+const managedBy = `(manager_id = ?2
+                     OR id IN (SELECT m.person_id FROM team_members m JOIN teams t ON t.id = m.team_id
+                                WHERE t.manager_id = ?2))`
+
+func (p *peopleRepo) IsManagedBy(ctx context.Context, uuid, managerUUID string) (bool, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return false, err
+  }
+  query := `SELECT EXISTS (
+              SELECT 1 FROM person
+              WHERE id = ?1 AND organization_id = ?3 AND deleted_at IS NULL AND ` + managedBy + `)`
+  var managed bool
+  if err = conn(ctx, p.db).GetContext(ctx, &managed, query, uuid, managerUUID, org); err != nil {
+    return false, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  return managed, nil
+}
+
+// GetByUUIDs ищет людей по списку UUID одним запросом, отсутствующие пропускаются
+func (p *peopleRepo) GetByUUIDs(ctx context.Context, uuids []string) ([]dto.Person, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT ` + personColumns + `
+              FROM person
+              WHERE id IN (SELECT value FROM json_each(?1)) AND organization_id = ?2 AND deleted_at IS NULL`
+  var rows []person
+  if err = conn(ctx, p.db).SelectContext(ctx, &rows, query, jsonArray(uuids), org); err != nil {
+    return nil, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  res := make([]dto.Person, 0, len(rows))
+  for _, row := range rows {
+    res = append(res, *row.toDTO())
+  }
+  return res, nil
+}
+
+// ManagedAmong отбирает из uuids людей, которыми руководит managerUUID, по тем же правилам, что и IsManagedBy
+func (p *peopleRepo) ManagedAmong(ctx context.Context, uuids []string, managerUUID string) ([]string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT id FROM person
+              WHERE id IN (SELECT value FROM json_each(?1)) AND organization_id = ?3 AND deleted_at IS NULL
+                AND ` + managedBy
+  var managed []string
+  if err = conn(ctx, p.db).SelectContext(ctx, &managed, query, jsonArray(uuids), managerUUID, org); err != nil {
+    return nil, fmt.Errorf("ошибка получения данных из базы: %v", err)
+  }
+  return managed, nil
+}
@@ -0,0 +1,152 @@
+// Package sqlite хранилище в файле SQLite для запуска без Postgres и Docker.
+// Схема и запросы повторяют репозитории Postgres, функции pg_trgm и арифметика
+// интервалов выполняются в Go (pgcompat)
+package sqlite
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "github.com/golang-migrate/migrate/v4"
+  migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite3"
+  "github.com/golang-migrate/migrate/v4/source/iofs"
+  "github.com/jmoiron/sqlx"
+  "github.com/mattn/go-sqlite3"
+  "strings"
+  "time"
+  "timetracker/internal/db"
+  "timetracker/internal/db/pgcompat"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/db/sqlite/migrations"
+  "timetracker/internal/utils"
+)
+
+// driverName драйвер SQLite с функциями, которых нет в SQLite, но есть в запросах
+const driverName = "sqlite3_timetracker"
+
+func init() {
+  sql.Register(driverName, &sqlite3.SQLiteDriver{
+    ConnectHook: func(c *sqlite3.SQLiteConn) error {
+      functions := map[string]interface{}{
+        // встроенная lower меняет регистр только латиницы
+        "lower":           strings.ToLower,
+        "similarity":      pgcompat.Similarity,
+        "word_similarity": pgcompat.WordSimilarity,
+        "prefix_match":    pgcompat.PrefixMatch,
+        "highlight":       pgcompat.Highlight,
+      }
+      for name, fn := range functions {
+        if err := c.RegisterFunc(name, fn, true); err != nil {
+          return err
+        }
+      }
+      return nil
+    },
+  })
+}
+
+// Repo хранилище в SQLite, транзакции Store выполняет он сам
+type Repo struct {
+  db.Store
+  db *sqlx.DB
+}
+
+// Open открывает файл базы path (":memory:" - база в памяти) и применяет миграции.
+// Соединение одно: SQLite допускает одного писателя, а транзакция держит соединение до конца
+func Open(path string) (*Repo, error) {
+  dsn := "file:" + path + "?_foreign_keys=1&_busy_timeout=5000&_txlock=immediate"
+  if path != ":memory:" {
+    dsn += "&_journal_mode=WAL"
+  }
+  conn, err := sqlx.Open(driverName, dsn)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка открытия базы sqlite: %v", err)
+  }
+  conn.SetMaxOpenConns(1)
+  if err = migrateUp(conn); err != nil {
+    _ = conn.Close()
+    return nil, err
+  }
+
+  res := Repo{db: conn}
+  res.ITxRunner = &res
+  res.People = &peopleRepo{db: conn}
+  res.Task = &taskRepo{db: conn}
+  res.TimeTask = &timeTaskRepo{db: conn}
+  res.ApiKey = &apiKeyRepo{db: conn}
+  res.Org = &organizationRepo{db: conn}
+  res.Team = &teamRepo{db: conn}
+  res.Audit = &auditRepo{db: conn}
+  res.Idem = &idempotencyRepo{db: conn}
+  res.Webhook = &webhookRepo{db: conn}
+  return &res, nil
+}
+
+// migrateUp применяет встроенные миграции. Мигратор не закрывается: его Close закрыл бы
+// общее соединение, а с ним и базу в памяти
+func migrateUp(conn *sqlx.DB) error {
+  src, err := iofs.New(migrations.FS, ".")
+  if err != nil {
+    return fmt.Errorf("ошибка чтения миграций: %v", err)
+  }
+  driver, err := migratesqlite.WithInstance(conn.DB, &migratesqlite.Config{})
+  if err != nil {
+    return fmt.Errorf("ошибка подготовки миграций: %v", err)
+  }
+  m, err := migrate.NewWithInstance("iofs", src, "sqlite3", driver)
+  if err != nil {
+    return fmt.Errorf("ошибка подготовки миграций: %v", err)
+  }
+  if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+    return fmt.Errorf("ошибка применения миграций: %v", err)
+  }
+  return nil
+}
+
+func (r *Repo) Close() error {
+  return r.db.Close()
+}
+
+// WithinTx выполняет fn в транзакции, см. db.ITxRunner. Транзакция занимает единственное
+// соединение до конца, поэтому все запросы репозиториев идут через conn
+func (r *Repo) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+  return repo.WithinTx(ctx, r.db, nil, fn)
+}
+
+// AfterCommit выполняет fn после фиксации транзакции из ctx, при откате fn не вызывается.
+// Вне транзакции fn выполняется сразу
+func (r *Repo) AfterCommit(ctx context.Context, fn func()) {
+  repo.AfterCommit(ctx, r.db, fn)
+}
+
+// conn возвращает транзакцию из ctx, если она начата, иначе пул соединений.
+// Соединение одно, и запрос мимо начатой транзакции ждал бы ее вечно
+func conn(ctx context.Context, d *sqlx.DB) repo.Querier {
+  if tx := repo.TxFromCtx(ctx, d); tx != nil {
+    return tx
+  }
+  return d
+}
+
+// tenant возвращает организацию, которой ограничен запрос
+func tenant(ctx context.Context) (string, error) {
+  org := utils.TenantFromCtx(ctx)
+  if org == "" {
+    return "", errors.New("организация запроса не определена")
+  }
+  return org, nil
+}
+
+// stampLayout формат времени в базе: одинаковая длина строк делает их сравнение сравнением времени
+const stampLayout = "2006-01-02 15:04:05.000000"
+
+// stamp время в формате колонок TIMESTAMP
+func stamp(t time.Time) string {
+  return pgcompat.Wall(t).Format(stampLayout)
+}
+
+// now текущее время в формате колонок TIMESTAMP
+func now() string {
+  return stamp(time.Now())
+}
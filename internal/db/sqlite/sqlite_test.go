@@ -0,0 +1,34 @@
+package sqlite
+
+import (
+  "context"
+  "github.com/google/uuid"
+  "testing"
+  "timetracker/internal/db"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/db/repotest"
+)
+
+// TestContract тот же набор repotest, что у postgres и хранилища в памяти, на базе в памяти SQLite
+func TestContract(t *testing.T) {
+  opened := map[*db.Store]*Repo{}
+  repotest.Run(t, func(t *testing.T) *db.Store {
+    r, err := Open(":memory:")
+    if err != nil {
+      t.Fatal(err)
+    }
+    t.Cleanup(func() { r.Close() })
+    opened[&r.Store] = r
+    return &r.Store
+  }, func(t *testing.T, s *db.Store, ctx context.Context) {
+    // отложенная проверка внешних ключей срабатывает только при фиксации
+    tx := repo.TxFromCtx(ctx, opened[s].db)
+    _, err := tx.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON")
+    if err == nil {
+      _, err = tx.ExecContext(ctx, "INSERT INTO team_members (team_id, person_id) VALUES (?1, ?2)", uuid.NewString(), uuid.NewString())
+    }
+    if err != nil {
+      t.Fatal(err)
+    }
+  })
+}
@@ -0,0 +1,325 @@
+package sqlite
+
+import (
+  "cmp"
+  "context"
+  "database/sql"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "slices"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/db/pgcompat"
+  "timetracker/internal/db/repo"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+  "timetracker/internal/utils/cursor"
+)
+
+type task struct {
+  IdTask     string    `db:"idtask"`
+  IdPerson   string    `db:"idperson"`
+  TaskName   string    `db:"task_name"`
+  TaskStatus string    `db:"task_status"`
+  CreatedAt  time.Time `db:"created_at"`
+  Version    int       `db:"version"`
+}
+
+func (t *task) toDTO() *dto.Task {
+  return &dto.Task{
+    IdTask:     t.IdTask,
+    IdPerson:   t.IdPerson,
+    TaskName:   t.TaskName,
+    TaskStatus: t.TaskStatus,
+    Version:    t.Version,
+  }
+}
+
+const taskColumns = "idtask, idperson, task_name, task_status, version, created_at"
+
+type taskRepo struct {
+  db *sqlx.DB
+}
+
+func (t *taskRepo) CreateTask(ctx context.Context, model *dto.Task) (*dto.Task, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  created := task{IdTask: uuid.NewString(), IdPerson: model.IdPerson, TaskName: model.TaskName, TaskStatus: status.New, Version: 1}
+  query := `INSERT INTO tasks (idtask, idperson, task_name, task_status, organization_id, created_at)
+              VALUES (?1, ?2, ?3, ?4, ?5, ?6)`
+  _, err = conn(ctx, t.db).ExecContext(ctx, query, created.IdTask, created.IdPerson, created.TaskName, created.TaskStatus, org, now())
+  if err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+func (t *taskRepo) GetTask(ctx context.Context, id string) (*dto.Task, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var row task
+  query := "SELECT " + taskColumns + " FROM tasks WHERE idtask = ?1 AND organization_id = ?2"
+  err = conn(ctx, t.db).GetContext(ctx, &row, query, id, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("задача с id %s не найдена", id)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка получения задачи из базы данных: %v", err)
+  }
+  return row.toDTO(), nil
+}
+
+// GetTasks возвращает страницу задач человека в порядке создания, (created_at, idtask) служит ключом курсора
+func (t *taskRepo) GetTasks(ctx context.Context, personID string, page dto.PageRequest) ([]dto.Task, dto.PageInfo, error) {
+  var info dto.PageInfo
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, info, err
+  }
+  args := []interface{}{personID, org, page.Limit + 1, page.Offset}
+  query := `SELECT ` + taskColumns + `
+              FROM tasks
+              WHERE idperson = ?1 AND organization_id = ?2`
+  if page.Cursor != "" {
+    after, err := cursor.Decode(page.Cursor, 2)
+    if err != nil || !utils.IsValidUUID(after[1]) {
+      return nil, info, cursor.ErrInvalid
+    }
+    createdAt, err := time.Parse(time.RFC3339Nano, after[0])
+    if err != nil {
+      return nil, info, cursor.ErrInvalid
+    }
+    query += `
+                AND (created_at, idtask) > (?5, ?6)`
+    args = append(args, stamp(createdAt), after[1])
+  }
+  query += `
+              ORDER BY created_at, idtask
+              LIMIT ?3 OFFSET ?4`
+
+  var rows []task
+  if err = conn(ctx, t.db).SelectContext(ctx, &rows, query, args...); err != nil {
+    return nil, info, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  if len(rows) > page.Limit {
+    rows = rows[:page.Limit]
+    last := rows[len(rows)-1]
+    info.NextCursor = cursor.Encode(last.CreatedAt.Format(time.RFC3339Nano), last.IdTask)
+  }
+  res := make([]dto.Task, 0, len(rows))
+  for _, row := range rows {
+    res = append(res, *row.toDTO())
+  }
+
+  if page.Count {
+    var total int
+    err = conn(ctx, t.db).GetContext(ctx, &total, "SELECT COUNT(*) FROM tasks WHERE idperson = ?1 AND organization_id = ?2", personID, org)
+    if err != nil {
+      return nil, info, fmt.Errorf("ошибка получения общего количества записей: %v", err)
+    }
+    info.Total = &total
+  }
+  return res, info, nil
+}
+
+// GetTaskStatus статус задачи. Блокировка строки не нужна: соединение одно,
+// и транзакции с записью выполняются по очереди
+func (t *taskRepo) GetTaskStatus(ctx context.Context, id string) (string, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return "", err
+  }
+  var taskStatus string
+  query := "SELECT task_status FROM tasks WHERE idtask = ?1 AND organization_id = ?2"
+  err = conn(ctx, t.db).GetContext(ctx, &taskStatus, query, id, org)
+  if err == sql.ErrNoRows {
+    return "", fmt.Errorf("задача с id %s не найдена", id)
+  } else if err != nil {
+    return "", fmt.Errorf("ошибка получения статуса задачи из базы данных: %v", err)
+  }
+  return taskStatus, nil
+}
+
+// UpdateStatus меняет статус задачи, при ненулевой version только в этой версии записи
+func (t *taskRepo) UpdateStatus(ctx context.Context, id, st string, version int) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := "UPDATE tasks SET task_status = ?1, version = version + 1 WHERE idtask = ?2 AND organization_id = ?3 AND (?4 = 0 OR version = ?4)"
+  result, err := conn(ctx, t.db).ExecContext(ctx, query, st, id, org, version)
+  if err != nil {
+    return fmt.Errorf("ошибка обновления статуса задачи в базе данных: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 && version != 0 {
+    return fmt.Errorf("%w: задача с id %s не найдена или изменена", repo.ErrStale, id)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("задача с id %s не найдена", id)
+  }
+  return nil
+}
+
+// GetTasksOfPeople первые страницы задач нескольких людей одним запросом, в том же порядке и с теми же курсорами,
+// что и GetTasks. Общее число задач каждого человека заполняется всегда
+func (t *taskRepo) GetTasksOfPeople(ctx context.Context, personIDs []string, limit int) (map[string][]dto.Task, map[string]dto.PageInfo, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, nil, err
+  }
+  query := `SELECT ` + taskColumns + `, total
+              FROM (SELECT ` + taskColumns + `,
+                           ROW_NUMBER() OVER (PARTITION BY idperson ORDER BY created_at, idtask) AS rn,
+                           COUNT(*) OVER (PARTITION BY idperson) AS total
+                      FROM tasks
+                      WHERE idperson IN (SELECT value FROM json_each(?1)) AND organization_id = ?2) ranked
+              WHERE rn <= ?3
+              ORDER BY idperson, created_at, idtask`
+  var rows []struct {
+    task
+    Total int `db:"total"`
+  }
+  err = conn(ctx, t.db).SelectContext(ctx, &rows, query, jsonArray(personIDs), org, limit+1)
+  if err != nil {
+    return nil, nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  tasks := make(map[string][]dto.Task, len(personIDs))
+  infos := make(map[string]dto.PageInfo, len(personIDs))
+  for i, row := range rows {
+    total := row.Total
+    info := dto.PageInfo{Total: &total}
+    if len(tasks[row.IdPerson]) == limit {
+      // лишняя строка означает следующую страницу, курсор по последней отданной
+      last := rows[i-1]
+      info.NextCursor = cursor.Encode(last.CreatedAt.Format(time.RFC3339Nano), last.IdTask)
+    } else {
+      tasks[row.IdPerson] = append(tasks[row.IdPerson], *row.toDTO())
+    }
+    infos[row.IdPerson] = info
+  }
+  return tasks, infos, nil
+}
+
+// periodEntry закрытый интервал задачи, пересекающийся с периодом отчета
+type periodEntry struct {
+  IDPerson  string    `db:"idperson"`
+  Surname   string    `db:"surname"`
+  Name      string    `db:"name"`
+  IDTask    string    `db:"idtask"`
+  TaskName  string    `db:"task_name"`
+  StartTime time.Time `db:"start_time"`
+  EndTime   time.Time `db:"end_time"`
+}
+
+// taskTime время задачи за период
+type taskTime struct {
+  periodEntry
+  total pgcompat.Interval
+}
+
+// periodTimes выбирает закрытые интервалы, пересекающиеся с периодом [start, end], обрезает их по границам
+// периода и суммирует по задачам. Интервалы в SQLite не вычитаются, поэтому сумма считается в Go,
+// как SUM(end - start) в Postgres. where ограничивает задачи, ее параметры начинаются с ?4
+func (t *taskRepo) periodTimes(ctx context.Context, where, start, end string, args ...interface{}) ([]taskTime, error) {
+  from, err := pgcompat.ParseTimestamp(start)
+  if err != nil {
+    return nil, err
+  }
+  to, err := pgcompat.ParseTimestamp(end)
+  if err != nil {
+    return nil, err
+  }
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT task.idperson, p.surname, p.name, t.idtask, task.task_name, t.start_time, t.end_time
+              FROM timetask t
+              JOIN tasks task ON t.idtask = task.idtask
+              JOIN person p ON p.id = task.idperson
+              WHERE task.organization_id = ?1
+                AND t.end_time IS NOT NULL
+                AND t.start_time <= ?3 AND t.end_time >= ?2
+                AND ` + where + `
+              ORDER BY t.id`
+  var entries []periodEntry
+  err = conn(ctx, t.db).SelectContext(ctx, &entries, query, append([]interface{}{org, stamp(from), stamp(to)}, args...)...)
+  if err != nil {
+    return nil, err
+  }
+
+  byTask := map[string]int{}
+  var res []taskTime
+  for _, e := range entries {
+    s, f := e.StartTime, e.EndTime
+    if s.Before(from) {
+      s = from
+    }
+    if f.After(to) {
+      f = to
+    }
+    i, ok := byTask[e.IDTask]
+    if !ok {
+      i = len(res)
+      byTask[e.IDTask] = i
+      res = append(res, taskTime{periodEntry: e})
+    }
+    res[i].total = res[i].total.Add(pgcompat.Between(s, f))
+  }
+  return res, nil
+}
+
+func (t *taskRepo) TaskTimes(ctx context.Context, id, start, end string) ([]dto.TaskTimeResult, error) {
+  times, err := t.periodTimes(ctx, "task.idperson = ?4", start, end, id)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  slices.SortFunc(times, func(a, b taskTime) int {
+    return cmp.Or(b.total.Compare(a.total), cmp.Compare(a.IDTask, b.IDTask))
+  })
+  var res []dto.TaskTimeResult
+  for _, tt := range times {
+    res = append(res, dto.TaskTimeResult{IDTask: tt.IDTask, TaskName: tt.TaskName, TotalTime: tt.total.String()})
+  }
+  return res, nil
+}
+
+// TeamTimes трудозатраты участников команды по задачам, участники упорядочены по общему времени
+func (t *taskRepo) TeamTimes(ctx context.Context, teamID, start, end string) ([]dto.TeamTimeResult, error) {
+  times, err := t.periodTimes(ctx, "task.idperson IN (SELECT person_id FROM team_members WHERE team_id = ?4)", start, end, teamID)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+
+  personTime := map[string]pgcompat.Interval{}
+  for _, tt := range times {
+    personTime[tt.IDPerson] = personTime[tt.IDPerson].Add(tt.total)
+  }
+  slices.SortFunc(times, func(a, b taskTime) int {
+    return cmp.Or(personTime[b.IDPerson].Compare(personTime[a.IDPerson]), cmp.Compare(a.IDPerson, b.IDPerson),
+      b.total.Compare(a.total), cmp.Compare(a.IDTask, b.IDTask))
+  })
+  var res []dto.TeamTimeResult
+  for _, tt := range times {
+    if len(res) == 0 || res[len(res)-1].IDPerson != tt.IDPerson {
+      res = append(res, dto.TeamTimeResult{
+        IDPerson:  tt.IDPerson,
+        Surname:   tt.Surname,
+        Name:      tt.Name,
+        TotalTime: personTime[tt.IDPerson].String(),
+      })
+    }
+    last := &res[len(res)-1]
+    last.Tasks = append(last.Tasks, dto.TaskTimeResult{IDTask: tt.IDTask, TaskName: tt.TaskName, TotalTime: tt.total.String()})
+  }
+  return res, nil
+}
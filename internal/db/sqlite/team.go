@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+  "context"
+  "database/sql"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "timetracker/internal/bl/dto"
+)
+
+type team struct {
+  Id        string  `db:"id"`
+  Name      string  `db:"name"`
+  ManagerId *string `db:"manager_id"`
+}
+
+func (t *team) toDTO() *dto.Team {
+  res := &dto.Team{ID: t.Id, Name: t.Name}
+  if t.ManagerId != nil {
+    res.ManagerID = *t.ManagerId
+  }
+  return res
+}
+
+type teamRepo struct {
+  db *sqlx.DB
+}
+
+func (t *teamRepo) CreateTeam(ctx context.Context, model *dto.Team) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `INSERT INTO teams (id, organization_id, name, manager_id, created_at)
+              VALUES (?1, ?2, ?3, ?4, ?5)
+              RETURNING id, name, manager_id`
+  var created team
+  err = conn(ctx, t.db).GetContext(ctx, &created, query, uuid.NewString(), org, model.Name, nullable(model.ManagerID), now())
+  if err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в базу: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+// GetTeam возвращает команду вместе со списком участников
+func (t *teamRepo) GetTeam(ctx context.Context, id string) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var row team
+  err = conn(ctx, t.db).GetContext(ctx, &row, "SELECT id, name, manager_id FROM teams WHERE id = ?1 AND organization_id = ?2", id, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("команда с id %s не найдена", id)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка получения команды из базы данных: %v", err)
+  }
+
+  res := row.toDTO()
+  err = conn(ctx, t.db).SelectContext(ctx, &res.Members, "SELECT person_id FROM team_members WHERE team_id = ?1 ORDER BY person_id", id)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка получения участников команды: %v", err)
+  }
+  return res, nil
+}
+
+// GetTeams возвращает команды организации, при непустом managerID только команды этого руководителя
+func (t *teamRepo) GetTeams(ctx context.Context, managerID string) ([]dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT id, name, manager_id
+              FROM teams
+              WHERE organization_id = ?1
+                AND (?2 = '' OR manager_id = ?2)
+              ORDER BY name`
+  var teams []team
+  if err = conn(ctx, t.db).SelectContext(ctx, &teams, query, org, managerID); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Team, 0, len(teams))
+  for _, row := range teams {
+    res = append(res, *row.toDTO())
+  }
+  return res, nil
+}
+
+func (t *teamRepo) UpdateTeam(ctx context.Context, model *dto.Team) (*dto.Team, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `UPDATE teams
+              SET name = ?1, manager_id = ?2
+              WHERE id = ?3 AND organization_id = ?4
+              RETURNING id, name, manager_id`
+  var updated team
+  err = conn(ctx, t.db).GetContext(ctx, &updated, query, model.Name, nullable(model.ManagerID), model.ID, org)
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("команда с id %s не найдена", model.ID)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка обновления данных в базе: %v", err)
+  }
+  return updated.toDTO(), nil
+}
+
+func (t *teamRepo) DeleteTeam(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  result, err := conn(ctx, t.db).ExecContext(ctx, "DELETE FROM teams WHERE id = ?1 AND organization_id = ?2", id, org)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления команды: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("команда с id %s не найдена", id)
+  }
+  return nil
+}
+
+// AddMember добавляет человека в команду, повторное добавление не ошибка
+func (t *teamRepo) AddMember(ctx context.Context, teamID, personID string) error {
+  query := "INSERT INTO team_members (team_id, person_id) VALUES (?1, ?2) ON CONFLICT DO NOTHING"
+  if _, err := conn(ctx, t.db).ExecContext(ctx, query, teamID, personID); err != nil {
+    return fmt.Errorf("ошибка добавления участника команды: %v", err)
+  }
+  return nil
+}
+
+func (t *teamRepo) RemoveMember(ctx context.Context, teamID, personID string) error {
+  result, err := conn(ctx, t.db).ExecContext(ctx, "DELETE FROM team_members WHERE team_id = ?1 AND person_id = ?2", teamID, personID)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления участника команды: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("человек %s не состоит в команде %s", personID, teamID)
+  }
+  return nil
+}
+
+// PersonTeams id команд, в которых состоит человек
+func (t *teamRepo) PersonTeams(ctx context.Context, personID string) ([]string, error) {
+  var teams []string
+  err := conn(ctx, t.db).SelectContext(ctx, &teams, "SELECT team_id FROM team_members WHERE person_id = ?1 ORDER BY team_id", personID)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка получения команд человека: %v", err)
+  }
+  return teams, nil
+}
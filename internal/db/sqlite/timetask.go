@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+  "context"
+  "database/sql"
+  "fmt"
+  "github.com/jmoiron/sqlx"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+type timeTask struct {
+  ID        int        `db:"id"`
+  IDTask    string     `db:"idtask"`
+  StartTime time.Time  `db:"start_time"`
+  EndTime   *time.Time `db:"end_time"`
+}
+
+func (t *timeTask) toDTO() *dto.TimeTask {
+  return &dto.TimeTask{
+    ID:        t.ID,
+    IDTask:    t.IDTask,
+    StartTime: t.StartTime,
+    EndTime:   t.EndTime,
+  }
+}
+
+type timeTaskRepo struct {
+  db *sqlx.DB
+}
+
+func (t *timeTaskRepo) StartTimer(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := "INSERT INTO timetask (idtask, start_time, organization_id) VALUES (?1, ?2, ?3)"
+  if _, err = conn(ctx, t.db).ExecContext(ctx, query, id, now(), org); err != nil {
+    return fmt.Errorf("ошибка вставки данных в таблицу timetask: %v", err)
+  }
+  return nil
+}
+
+func (t *timeTaskRepo) StopTimer(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  query := "UPDATE timetask SET end_time = ?3 WHERE idtask = ?1 AND organization_id = ?2 AND end_time IS NULL"
+  result, err := conn(ctx, t.db).ExecContext(ctx, query, id, org, now())
+  if err != nil {
+    return fmt.Errorf("ошибка обновления данных в таблице timetask: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("нет активного таймера для задачи с id %s", id)
+  }
+  return nil
+}
+
+// AddEntry добавляет внесенный вручную завершенный интервал работы над задачей,
+// если он не пересекается с уже учтенными интервалами этой задачи
+func (t *timeTaskRepo) AddEntry(ctx context.Context, entry *dto.TimeTask) (*dto.TimeTask, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `INSERT INTO timetask (idtask, start_time, end_time, organization_id)
+              SELECT ?1, ?2, ?3, ?4
+              WHERE NOT EXISTS (
+                SELECT 1 FROM timetask
+                WHERE idtask = ?1 AND organization_id = ?4
+                  AND start_time < ?3 AND COALESCE(end_time, ?5) > ?2)
+              RETURNING id, idtask, start_time, end_time`
+  var created timeTask
+  err = conn(ctx, t.db).GetContext(ctx, &created, query, entry.IDTask, stamp(entry.StartTime), stamp(*entry.EndTime), org, now())
+  if err == sql.ErrNoRows {
+    return nil, fmt.Errorf("интервал пересекается с уже учтенным временем задачи %s", entry.IDTask)
+  } else if err != nil {
+    return nil, fmt.Errorf("ошибка вставки данных в таблицу timetask: %v", err)
+  }
+  return created.toDTO(), nil
+}
+
+// GetEntries интервалы работы задач taskIDs, принадлежащих людям personIDs, по задачам в порядке начала
+func (t *timeTaskRepo) GetEntries(ctx context.Context, taskIDs, personIDs []string) (map[string][]dto.TimeTask, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT t.id, t.idtask, t.start_time, t.end_time
+              FROM timetask t
+              JOIN tasks task ON task.idtask = t.idtask
+              WHERE t.idtask IN (SELECT value FROM json_each(?1))
+                AND task.idperson IN (SELECT value FROM json_each(?2))
+                AND task.organization_id = ?3
+              ORDER BY t.idtask, t.start_time, t.id`
+  var entries []timeTask
+  err = conn(ctx, t.db).SelectContext(ctx, &entries, query, jsonArray(taskIDs), jsonArray(personIDs), org)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make(map[string][]dto.TimeTask, len(taskIDs))
+  for _, entry := range entries {
+    res[entry.IDTask] = append(res[entry.IDTask], *entry.toDTO())
+  }
+  return res, nil
+}
@@ -0,0 +1,283 @@
+package sqlite
+
+import (
+  "context"
+  "database/sql"
+  "encoding/json"
+  "fmt"
+  "github.com/google/uuid"
+  "github.com/jmoiron/sqlx"
+  "strconv"
+  "time"
+  "timetracker/internal/bl/dto"
+)
+
+// webhook подписка, event_types хранится json массивом
+type webhook struct {
+  Id         string    `db:"id"`
+  Url        string    `db:"url"`
+  EventTypes string    `db:"event_types"`
+  CreatedAt  time.Time `db:"created_at"`
+}
+
+func (w *webhook) toDTO() (*dto.Webhook, error) {
+  res := &dto.Webhook{ID: w.Id, URL: w.Url, CreatedAt: w.CreatedAt}
+  if err := json.Unmarshal([]byte(w.EventTypes), &res.EventTypes); err != nil {
+    return nil, fmt.Errorf("ошибка разбора типов событий вебхука: %v", err)
+  }
+  return res, nil
+}
+
+type webhookDelivery struct {
+  Id             int64          `db:"id"`
+  WebhookId      string         `db:"webhook_id"`
+  OutboxId       int64          `db:"outbox_id"`
+  EventType      string         `db:"event_type"`
+  Status         string         `db:"status"`
+  Attempts       int            `db:"attempts"`
+  NextAttemptAt  time.Time      `db:"next_attempt_at"`
+  ResponseStatus *int           `db:"response_status"`
+  LastError      sql.NullString `db:"last_error"`
+  DeliveredAt    *time.Time     `db:"delivered_at"`
+  CreatedAt      time.Time      `db:"created_at"`
+}
+
+func (d *webhookDelivery) toDTO() *dto.WebhookDelivery {
+  res := &dto.WebhookDelivery{
+    ID:             d.Id,
+    WebhookID:      d.WebhookId,
+    EventID:        d.OutboxId,
+    EventType:      d.EventType,
+    Status:         d.Status,
+    Attempts:       d.Attempts,
+    ResponseStatus: d.ResponseStatus,
+    LastError:      d.LastError.String,
+    DeliveredAt:    d.DeliveredAt,
+    CreatedAt:      d.CreatedAt,
+  }
+  if d.Status == dto.DeliveryPending {
+    next := d.NextAttemptAt
+    res.NextAttemptAt = &next
+  }
+  return res
+}
+
+type webhookRepo struct {
+  db *sqlx.DB
+}
+
+func (w *webhookRepo) CreateWebhook(ctx context.Context, hook *dto.Webhook) (*dto.Webhook, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `INSERT INTO webhooks (id, organization_id, url, secret, event_types, created_at)
+              VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+              RETURNING id, url, event_types, created_at`
+  var created webhook
+  err = conn(ctx, w.db).GetContext(ctx, &created, query, uuid.NewString(), org, hook.URL, hook.Secret, jsonArray(hook.EventTypes), now())
+  if err != nil {
+    return nil, fmt.Errorf("ошибка создания вебхука: %v", err)
+  }
+  return created.toDTO()
+}
+
+func (w *webhookRepo) GetWebhooks(ctx context.Context) ([]dto.Webhook, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  var hooks []webhook
+  query := `SELECT id, url, event_types, created_at
+              FROM webhooks WHERE organization_id = ?1 ORDER BY created_at, id`
+  if err = conn(ctx, w.db).SelectContext(ctx, &hooks, query, org); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.Webhook, 0, len(hooks))
+  for _, hook := range hooks {
+    model, err := hook.toDTO()
+    if err != nil {
+      return nil, err
+    }
+    res = append(res, *model)
+  }
+  return res, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с журналом ее доставок
+func (w *webhookRepo) DeleteWebhook(ctx context.Context, id string) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  result, err := conn(ctx, w.db).ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?1 AND organization_id = ?2", id, org)
+  if err != nil {
+    return fmt.Errorf("ошибка удаления вебхука: %v", err)
+  }
+  rowsAffected, err := result.RowsAffected()
+  if err != nil {
+    return fmt.Errorf("ошибка получения количества затронутых строк: %v", err)
+  }
+  if rowsAffected == 0 {
+    return fmt.Errorf("вебхук с id %s не найден", id)
+  }
+  return nil
+}
+
+// GetDeliveries последние доставки подписки, при непустом status только в этом статусе
+func (w *webhookRepo) GetDeliveries(ctx context.Context, webhookID, status string, limit int) ([]dto.WebhookDelivery, error) {
+  org, err := tenant(ctx)
+  if err != nil {
+    return nil, err
+  }
+  query := `SELECT d.id, d.webhook_id, d.outbox_id, o.event_type, d.status, d.attempts, d.next_attempt_at,
+                   d.response_status, d.last_error, d.delivered_at, d.created_at
+              FROM webhook_deliveries d
+              JOIN webhooks w ON w.id = d.webhook_id
+              JOIN webhook_outbox o ON o.id = d.outbox_id
+              WHERE d.webhook_id = ?1 AND w.organization_id = ?2 AND (?3 = '' OR d.status = ?3)
+              ORDER BY d.id DESC
+              LIMIT ?4`
+  var deliveries []webhookDelivery
+  if err = conn(ctx, w.db).SelectContext(ctx, &deliveries, query, webhookID, org, status, limit); err != nil {
+    return nil, fmt.Errorf("ошибка выполнения запроса: %v", err)
+  }
+  res := make([]dto.WebhookDelivery, 0, len(deliveries))
+  for _, delivery := range deliveries {
+    res = append(res, *delivery.toDTO())
+  }
+  return res, nil
+}
+
+// Enqueue пишет событие в outbox и ставит его доставку каждой подписке организации на этот тип.
+// Внутри транзакции запись фиксируется вместе с изменением. Без подписок событие не сохраняется.
+// SQLite не поддерживает INSERT в WITH, поэтому событие и доставки пишутся двумя запросами
+func (w *webhookRepo) Enqueue(ctx context.Context, eventType string, data []byte) error {
+  org, err := tenant(ctx)
+  if err != nil {
+    return err
+  }
+  created := now()
+  query := `INSERT INTO webhook_outbox (organization_id, event_type, payload, created_at)
+              SELECT ?1, ?2, ?3, ?4
+              WHERE EXISTS (SELECT 1 FROM webhooks
+                             WHERE organization_id = ?1 AND ?2 IN (SELECT value FROM json_each(event_types)))
+              RETURNING id`
+  var outboxID int64
+  err = conn(ctx, w.db).GetContext(ctx, &outboxID, query, org, eventType, string(data), created)
+  if err == sql.ErrNoRows {
+    return nil
+  } else if err != nil {
+    return fmt.Errorf("ошибка записи события вебхука: %v", err)
+  }
+  query = `INSERT INTO webhook_deliveries (webhook_id, outbox_id, next_attempt_at, created_at)
+             SELECT id, ?3, ?4, ?4 FROM webhooks
+             WHERE organization_id = ?1 AND ?2 IN (SELECT value FROM json_each(event_types))`
+  if _, err = conn(ctx, w.db).ExecContext(ctx, query, org, eventType, outboxID, created); err != nil {
+    return fmt.Errorf("ошибка записи события вебхука: %v", err)
+  }
+  return nil
+}
+
+// Claim берет в работу до limit доставок, которым пора отправляться, по всем организациям.
+// На время lease доставка скрыта от других воркеров, если воркер упадет, ее возьмут снова.
+// Запись в SQLite одна на базу, поэтому SKIP LOCKED не нужен. RETURNING в SQLite не видит
+// присоединенные таблицы, данные подписки и события читаются отдельным запросом
+func (w *webhookRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]dto.OutgoingWebhook, error) {
+  current := time.Now()
+  query := `UPDATE webhook_deliveries
+              SET attempts = attempts + 1, next_attempt_at = ?3
+              WHERE id IN (SELECT id FROM webhook_deliveries
+                            WHERE status = 'pending' AND next_attempt_at <= ?2
+                            ORDER BY next_attempt_at
+                            LIMIT ?1)
+              RETURNING id`
+  var ids []int64
+  err := conn(ctx, w.db).SelectContext(ctx, &ids, query, limit, stamp(current), stamp(current.Add(lease)))
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выборки доставок: %v", err)
+  }
+  if len(ids) == 0 {
+    return nil, nil
+  }
+  claimed, err := json.Marshal(ids)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка выборки доставок: %v", err)
+  }
+
+  query = `SELECT d.id, d.attempts, w.url, w.secret, o.id AS outbox_id, o.organization_id,
+                  o.event_type, o.payload, o.created_at
+             FROM webhook_deliveries d
+             JOIN webhooks w ON w.id = d.webhook_id
+             JOIN webhook_outbox o ON o.id = d.outbox_id
+             WHERE d.id IN (SELECT value FROM json_each(?1))
+             ORDER BY d.next_attempt_at, d.id`
+  var rows []claimedDelivery
+  if err = conn(ctx, w.db).SelectContext(ctx, &rows, query, string(claimed)); err != nil {
+    return nil, fmt.Errorf("ошибка выборки доставок: %v", err)
+  }
+
+  res := make([]dto.OutgoingWebhook, 0, len(rows))
+  for _, row := range rows {
+    out := dto.OutgoingWebhook{DeliveryID: row.Id, Attempts: row.Attempts, URL: row.Url, Secret: row.Secret}
+    out.Event.ID = strconv.FormatInt(row.OutboxId, 10)
+    out.Event.OrganizationID = row.OrganizationId
+    out.Event.Type = row.EventType
+    out.Event.Data = json.RawMessage(row.Payload)
+    out.Event.CreatedAt = row.CreatedAt
+    res = append(res, out)
+  }
+  return res, nil
+}
+
+// claimedDelivery строка доставки, взятой в работу, вместе с подпиской и событием
+type claimedDelivery struct {
+  Id             int64     `db:"id"`
+  Attempts       int       `db:"attempts"`
+  Url            string    `db:"url"`
+  Secret         string    `db:"secret"`
+  OutboxId       int64     `db:"outbox_id"`
+  OrganizationId string    `db:"organization_id"`
+  EventType      string    `db:"event_type"`
+  Payload        string    `db:"payload"`
+  CreatedAt      time.Time `db:"created_at"`
+}
+
+func (w *webhookRepo) Delivered(ctx context.Context, id int64, status int) error {
+  query := `UPDATE webhook_deliveries
+              SET status = 'delivered', response_status = ?2, last_error = NULL, delivered_at = ?3
+              WHERE id = ?1`
+  if _, err := conn(ctx, w.db).ExecContext(ctx, query, id, status, now()); err != nil {
+    return fmt.Errorf("ошибка обновления доставки: %v", err)
+  }
+  return nil
+}
+
+// Retry сохраняет итог неудачной попытки и назначает следующую через delay
+func (w *webhookRepo) Retry(ctx context.Context, id int64, status *int, reason string, delay time.Duration) error {
+  query := `UPDATE webhook_deliveries
+              SET response_status = ?2, last_error = ?3, next_attempt_at = ?4
+              WHERE id = ?1`
+  if _, err := conn(ctx, w.db).ExecContext(ctx, query, id, status, reason, stamp(time.Now().Add(delay))); err != nil {
+    return fmt.Errorf("ошибка обновления доставки: %v", err)
+  }
+  return nil
+}
+
+// Fail завершает доставку без успеха, повторов больше не будет
+func (w *webhookRepo) Fail(ctx context.Context, id int64, status *int, reason string) error {
+  query := "UPDATE webhook_deliveries SET status = 'failed', response_status = ?2, last_error = ?3 WHERE id = ?1"
+  if _, err := conn(ctx, w.db).ExecContext(ctx, query, id, status, reason); err != nil {
+    return fmt.Errorf("ошибка обновления доставки: %v", err)
+  }
+  return nil
+}
+
+// Cleanup удаляет события старше retention вместе с журналом их доставок
+func (w *webhookRepo) Cleanup(ctx context.Context, retention time.Duration) (int64, error) {
+  result, err := conn(ctx, w.db).ExecContext(ctx, "DELETE FROM webhook_outbox WHERE created_at < ?1", stamp(time.Now().Add(-retention)))
+  if err != nil {
+    return 0, fmt.Errorf("ошибка очистки событий вебхуков: %v", err)
+  }
+  return result.RowsAffected()
+}
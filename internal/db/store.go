@@ -0,0 +1,29 @@
+package db
+
+import (
+  "context"
+  "timetracker/internal/db/repo"
+)
+
+// ITxRunner транзакции хранилища. WithinTx выполняет fn в транзакции, которую fn получает
+// в ctx, и фиксирует ее, если fn вернула nil. Вложенный вызов работает в точке сохранения
+// и при ошибке откатывает только свои изменения. AfterCommit откладывает fn до фиксации
+type ITxRunner interface {
+  WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+  AfterCommit(ctx context.Context, fn func())
+}
+
+// Store репозитории и транзакции, от которых зависит бизнес-логика. Реализуется
+// базой (New), файлом SQLite (sqlite.Open) или памятью (memory.New)
+type Store struct {
+  ITxRunner
+  People   repo.IPeopleRepo
+  Task     repo.ITaskRepo
+  TimeTask repo.ITimeTaskRepo
+  ApiKey   repo.IApiKeyRepo
+  Org      repo.IOrganizationRepo
+  Team     repo.ITeamRepo
+  Audit    repo.IAuditRepo
+  Idem     repo.IIdempotencyRepo
+  Webhook  repo.IWebhookRepo
+}
@@ -0,0 +1,80 @@
+package graphql
+
+import (
+  "github.com/vektah/gqlparser/v2/ast"
+  "github.com/vektah/gqlparser/v2/parser"
+  "strconv"
+)
+
+// listSizes оценка длины списков без аргумента first
+var listSizes = map[string]int{
+  "entries":  20,
+  "worktime": 20,
+}
+
+// complexity оценивает размер ответа: каждое поле стоит 1, поля списков умножают стоимость вложенных полей
+// на first (по умолчанию 10) или на оценку из listSizes. graphql-go не отдает разобранный запрос,
+// поэтому он разбирается отдельно
+func complexity(query, operationName string, variables map[string]interface{}) (int, error) {
+  doc, err := parser.ParseQuery(&ast.Source{Input: query})
+  if err != nil {
+    return 0, err
+  }
+  cost := 0
+  for _, op := range doc.Operations {
+    if operationName != "" && op.Name != operationName {
+      continue
+    }
+    if c := selectionCost(doc, op.SelectionSet, variables, 0); c > cost {
+      cost = c
+    }
+  }
+  return cost, nil
+}
+
+func selectionCost(doc *ast.QueryDocument, set ast.SelectionSet, variables map[string]interface{}, depth int) int {
+  // циклы фрагментов отклонит проверка схемы, здесь достаточно не уйти в бесконечную рекурсию
+  if depth > maxDepth*2 {
+    return 0
+  }
+  cost := 0
+  for _, sel := range set {
+    switch sel := sel.(type) {
+    case *ast.Field:
+      cost += 1 + listSize(sel, variables)*selectionCost(doc, sel.SelectionSet, variables, depth+1)
+    case *ast.InlineFragment:
+      cost += selectionCost(doc, sel.SelectionSet, variables, depth+1)
+    case *ast.FragmentSpread:
+      if fragment := doc.Fragments.ForName(sel.Name); fragment != nil {
+        cost += selectionCost(doc, fragment.SelectionSet, variables, depth+1)
+      }
+    }
+  }
+  return cost
+}
+
+func listSize(field *ast.Field, variables map[string]interface{}) int {
+  if arg := field.Arguments.ForName("first"); arg != nil && arg.Value != nil {
+    switch arg.Value.Kind {
+    case ast.IntValue:
+      if n, err := strconv.Atoi(arg.Value.Raw); err == nil {
+        return n
+      }
+    case ast.Variable:
+      switch n := variables[arg.Value.Raw].(type) {
+      case float64:
+        return int(n)
+      case int:
+        return n
+      }
+    }
+    return maxPageLimit
+  }
+  if field.Name == "people" || field.Name == "tasks" {
+    return 10
+  }
+  if n, ok := listSizes[field.Name]; ok {
+    return n
+  }
+  return 1
+}
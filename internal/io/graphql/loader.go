@@ -0,0 +1,171 @@
+package graphql
+
+import (
+  "context"
+  "sync"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+)
+
+// loader загружает записи пачками. Резолвер списка заранее сообщает через want ключи, которые
+// понадобятся полям его элементов, и первый load загружает их все одним вызовом fetch вместо запроса на элемент.
+// Результаты живут до конца запроса
+type loader[K comparable, V any] struct {
+  fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+  mu      sync.Mutex
+  pending []K
+  cache   map[K]*result[V]
+}
+
+type result[V any] struct {
+  done  chan struct{}
+  value V
+  ok    bool
+  err   error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+  return &loader[K, V]{fetch: fetch, cache: make(map[K]*result[V])}
+}
+
+// want добавляет ключи к следующей загрузке
+func (l *loader[K, V]) want(keys ...K) {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  l.pending = append(l.pending, keys...)
+}
+
+// prime кладет уже известную запись, чтобы не загружать ее повторно
+func (l *loader[K, V]) prime(key K, value V) {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  if _, ok := l.cache[key]; ok {
+    return
+  }
+  r := &result[V]{done: make(chan struct{}), value: value, ok: true}
+  close(r.done)
+  l.cache[key] = r
+}
+
+// load возвращает запись по ключу, ok false - записи нет или вызывающему ее нельзя читать
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, bool, error) {
+  l.mu.Lock()
+  if r, ok := l.cache[key]; ok {
+    l.mu.Unlock()
+    <-r.done
+    return r.value, r.ok, r.err
+  }
+  keys := []K{key}
+  batch := map[K]*result[V]{key: {done: make(chan struct{})}}
+  for _, k := range l.pending {
+    if _, ok := l.cache[k]; ok {
+      continue
+    }
+    if _, ok := batch[k]; !ok {
+      keys = append(keys, k)
+      batch[k] = &result[V]{done: make(chan struct{})}
+    }
+  }
+  l.pending = nil
+  for k, r := range batch {
+    l.cache[k] = r
+  }
+  l.mu.Unlock()
+
+  values, err := l.fetch(ctx, keys)
+  for k, r := range batch {
+    r.value, r.ok = values[k]
+    r.err = err
+    close(r.done)
+  }
+  r := batch[key]
+  return r.value, r.ok, r.err
+}
+
+// loaders загрузчики одного запроса
+type loaders struct {
+  people  *loader[string, dto.Person]
+  entries *loader[dto.Task, []dto.TimeTask]
+
+  bl      *bl.BL
+  mu      sync.Mutex
+  persons []string
+  tasks   map[int]*loader[string, taskPage]
+}
+
+// taskPage первая страница задач человека
+type taskPage struct {
+  tasks []dto.Task
+  info  dto.PageInfo
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, bl *bl.BL) context.Context {
+  l := &loaders{bl: bl, tasks: make(map[int]*loader[string, taskPage])}
+  l.people = newLoader(func(ctx context.Context, ids []string) (map[string]dto.Person, error) {
+    people, err := bl.People.GetPeopleByUUIDs(ctx, ids)
+    if err != nil {
+      return nil, err
+    }
+    res := make(map[string]dto.Person, len(people))
+    for _, person := range people {
+      res[person.ID] = person
+    }
+    return res, nil
+  })
+  l.entries = newLoader(func(ctx context.Context, tasks []dto.Task) (map[dto.Task][]dto.TimeTask, error) {
+    entries, err := bl.Task.GetEntries(ctx, tasks)
+    if err != nil {
+      return nil, err
+    }
+    res := make(map[dto.Task][]dto.TimeTask, len(tasks))
+    for _, task := range tasks {
+      if list, ok := entries[task.IdTask]; ok {
+        res[task] = list
+      }
+    }
+    return res, nil
+  })
+  return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromCtx(ctx context.Context) *loaders {
+  return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// wantTasks люди, задачи которых понадобятся, для загрузчиков страниц любого размера
+func (l *loaders) wantTasks(ids ...string) {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  l.persons = append(l.persons, ids...)
+  for _, tl := range l.tasks {
+    tl.want(ids...)
+  }
+}
+
+// taskPages загрузчик первых страниц задач размера limit
+func (l *loaders) taskPages(limit int) *loader[string, taskPage] {
+  l.mu.Lock()
+  defer l.mu.Unlock()
+  if tl, ok := l.tasks[limit]; ok {
+    return tl
+  }
+  tl := newLoader(func(ctx context.Context, ids []string) (map[string]taskPage, error) {
+    tasks, infos, err := l.bl.Task.GetTasksOfPeople(ctx, ids, limit)
+    if err != nil {
+      return nil, err
+    }
+    res := make(map[string]taskPage, len(infos))
+    for id, info := range infos {
+      res[id] = taskPage{tasks: tasks[id], info: info}
+      // интервалы задач всех людей пачки тоже загрузятся вместе
+      l.entries.want(tasks[id]...)
+    }
+    return res, nil
+  })
+  tl.want(l.persons...)
+  l.tasks[limit] = tl
+  return tl
+}
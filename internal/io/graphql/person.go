@@ -0,0 +1,122 @@
+package graphql
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "github.com/graph-gophers/graphql-go"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/repo"
+  "timetracker/internal/utils"
+)
+
+type personResolver struct {
+  bl *bl.BL
+  p  dto.Person
+}
+
+func (r *personResolver) ID() graphql.ID {
+  return graphql.ID(r.p.ID)
+}
+
+func (r *personResolver) Surname() string {
+  return r.p.Surname
+}
+
+func (r *personResolver) Name() string {
+  return r.p.Name
+}
+
+func (r *personResolver) Patronymic() *string {
+  if r.p.Patronymic == "" {
+    return nil
+  }
+  return &r.p.Patronymic
+}
+
+func (r *personResolver) Address() string {
+  return r.p.Address
+}
+
+func (r *personResolver) PassportNumber() string {
+  return r.p.PassportNumber
+}
+
+func (r *personResolver) Version() int32 {
+  return int32(r.p.Version)
+}
+
+// Manager руководитель или null, если его нет или вызывающему его нельзя читать
+func (r *personResolver) Manager(ctx context.Context) (*personResolver, error) {
+  if r.p.ManagerID == "" {
+    return nil, nil
+  }
+  manager, ok, err := loadersFromCtx(ctx).people.load(ctx, r.p.ManagerID)
+  if err != nil || !ok {
+    return nil, err
+  }
+  return &personResolver{bl: r.bl, p: manager}, nil
+}
+
+// Tasks первая страница задач берется вместе с задачами остальных людей списка, следующие - отдельным запросом
+func (r *personResolver) Tasks(ctx context.Context, args pageArgs) (*taskConnection, error) {
+  page, err := args.page()
+  if err != nil {
+    return nil, err
+  }
+  if page.Cursor != "" {
+    tasks, info, err := r.bl.Task.GetTasks(ctx, r.p.ID, page)
+    if err != nil {
+      return nil, err
+    }
+    return newTaskConnection(ctx, r.bl, tasks, info), nil
+  }
+
+  first, ok, err := loadersFromCtx(ctx).taskPages(page.Limit).load(ctx, r.p.ID)
+  if err != nil {
+    return nil, err
+  }
+  if !ok {
+    return nil, fmt.Errorf("%w: нет доступа к задачам %s", repo.ErrForbidden, r.p.ID)
+  }
+  info := first.info
+  if !page.Count {
+    info.Total = nil
+  }
+  return newTaskConnection(ctx, r.bl, first.tasks, info), nil
+}
+
+func (r *personResolver) Worktime(ctx context.Context, args struct{ Start, End string }) ([]*taskTimeResolver, error) {
+  if !utils.IsValidDateTime(args.Start) || !utils.IsValidDateTime(args.End) || args.Start == "" || args.End == "" {
+    return nil, errors.New("проблемма входных данных")
+  }
+  if !utils.IsValidTimeRange(args.Start, args.End) {
+    return nil, errors.New("дата конца диапозона раньше чем начало")
+  }
+  times, err := r.bl.Task.TimeTasks(ctx, r.p.ID, args.Start, args.End)
+  if err != nil {
+    return nil, err
+  }
+  res := make([]*taskTimeResolver, 0, len(times))
+  for _, t := range times {
+    res = append(res, &taskTimeResolver{t: t})
+  }
+  return res, nil
+}
+
+type taskTimeResolver struct {
+  t dto.TaskTimeResult
+}
+
+func (r *taskTimeResolver) TaskId() graphql.ID {
+  return graphql.ID(r.t.IDTask)
+}
+
+func (r *taskTimeResolver) TaskName() string {
+  return r.t.TaskName
+}
+
+func (r *taskTimeResolver) TotalTime() string {
+  return r.t.TotalTime
+}
@@ -0,0 +1,164 @@
+package graphql
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "github.com/graph-gophers/graphql-go"
+  "time"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/utils"
+)
+
+const maxPageLimit = 100
+
+// resolver корень запросов
+type resolver struct {
+  bl *bl.BL
+}
+
+type peopleFilterInput struct {
+  Surname        *string
+  Name           *string
+  Patronymic     *string
+  Address        *string
+  PassportNumber *string
+  TeamId         *graphql.ID
+  HasActiveTask  *bool
+  CreatedAfter   *string
+}
+
+// pageArgs аргументы страницы, значения по умолчанию заданы в схеме
+type pageArgs struct {
+  First int32
+  After *string
+  Count bool
+}
+
+// page параметры страницы с теми же ограничениями, что и в REST
+func (a pageArgs) page() (dto.PageRequest, error) {
+  if a.First < 1 || a.First > maxPageLimit {
+    return dto.PageRequest{}, errors.New("некорректное значение параметра first")
+  }
+  return dto.PageRequest{Cursor: value(a.After), Limit: int(a.First), Count: a.Count}, nil
+}
+
+func (r *resolver) Person(ctx context.Context, args struct{ ID graphql.ID }) (*personResolver, error) {
+  id := string(args.ID)
+  if !utils.IsValidUUID(id) {
+    return nil, fmt.Errorf("uuid %s не валидный", id)
+  }
+  person, err := r.bl.People.GetPeopleUUID(ctx, id)
+  if err != nil {
+    return nil, err
+  }
+  return &personResolver{bl: r.bl, p: *person}, nil
+}
+
+type peopleArgs struct {
+  Filter *peopleFilterInput
+  Sort   *string
+  First  int32
+  After  *string
+  Count  bool
+}
+
+func (r *resolver) People(ctx context.Context, args peopleArgs) (*personConnection, error) {
+  filter := &dto.PeopleFilter{}
+  if f := args.Filter; f != nil {
+    filter.Surname = value(f.Surname)
+    filter.Name = value(f.Name)
+    filter.Patronymic = value(f.Patronymic)
+    filter.Address = value(f.Address)
+    filter.PassportNumber = value(f.PassportNumber)
+    filter.HasActiveTask = f.HasActiveTask
+    if f.TeamId != nil {
+      filter.TeamID = string(*f.TeamId)
+      if !utils.IsValidUUID(filter.TeamID) {
+        return nil, fmt.Errorf("teamId %s не валидный", filter.TeamID)
+      }
+    }
+    if f.CreatedAfter != nil {
+      after, err := time.Parse(time.DateOnly, *f.CreatedAfter)
+      if err != nil {
+        after, err = time.Parse(time.RFC3339, *f.CreatedAfter)
+      }
+      if err != nil {
+        return nil, errors.New("некорректное значение параметра createdAfter")
+      }
+      filter.CreatedAfter = &after
+    }
+  }
+  sort, err := dto.ParseSort(value(args.Sort), dto.PeopleSortFields)
+  if err != nil {
+    return nil, err
+  }
+  filter.Sort = sort
+  page, err := pageArgs{First: args.First, After: args.After, Count: args.Count}.page()
+  if err != nil {
+    return nil, err
+  }
+
+  people, info, err := r.bl.People.GetPeople(ctx, filter, page)
+  if err != nil {
+    return nil, err
+  }
+  return newPersonConnection(ctx, r.bl, people, info), nil
+}
+
+func value(s *string) string {
+  if s == nil {
+    return ""
+  }
+  return *s
+}
+
+type personConnection struct {
+  nodes []*personResolver
+  info  dto.PageInfo
+}
+
+// newPersonConnection готовит загрузку задач и руководителей всех людей страницы одним запросом
+func newPersonConnection(ctx context.Context, bl *bl.BL, people []dto.Person, info dto.PageInfo) *personConnection {
+  l := loadersFromCtx(ctx)
+  c := &personConnection{nodes: make([]*personResolver, 0, len(people)), info: info}
+  ids := make([]string, 0, len(people))
+  for _, person := range people {
+    l.people.prime(person.ID, person)
+    if person.ManagerID != "" {
+      l.people.want(person.ManagerID)
+    }
+    ids = append(ids, person.ID)
+    c.nodes = append(c.nodes, &personResolver{bl: bl, p: person})
+  }
+  l.wantTasks(ids...)
+  return c
+}
+
+func (c *personConnection) Nodes() []*personResolver {
+  return c.nodes
+}
+
+func (c *personConnection) NextCursor() *string {
+  return cursorOf(c.info)
+}
+
+func (c *personConnection) Total() *int32 {
+  return totalOf(c.info)
+}
+
+func cursorOf(info dto.PageInfo) *string {
+  if info.NextCursor == "" {
+    return nil
+  }
+  return &info.NextCursor
+}
+
+func totalOf(info dto.PageInfo) *int32 {
+  if info.Total == nil {
+    return nil
+  }
+  total := int32(*info.Total)
+  return &total
+}
@@ -0,0 +1,45 @@
+package graphql
+
+import (
+  "context"
+  _ "embed"
+  "github.com/graph-gophers/graphql-go"
+  "github.com/graph-gophers/graphql-go/errors"
+  "timetracker/internal/bl"
+)
+
+const (
+  // maxDepth наибольшая вложенность полей запроса
+  maxDepth = 10
+  // maxComplexity наибольшая оценка числа значений в ответе, см. complexity
+  maxComplexity = 10000
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+// Schema схема GraphQL поверх бизнес-логики, права проверяются в ней же, как и для REST
+type Schema struct {
+  schema *graphql.Schema
+  bl     *bl.BL
+}
+
+func New(bl *bl.BL) *Schema {
+  schema := graphql.MustParseSchema(schemaString, &resolver{bl: bl},
+    graphql.MaxDepth(maxDepth),
+    // элементы страницы разрешаются параллельно, чтобы загрузчики собирали их в одну пачку
+    graphql.MaxParallelism(maxPageLimit))
+  return &Schema{schema: schema, bl: bl}
+}
+
+// Exec выполняет запрос, слишком сложный запрос отклоняется до выполнения
+func (s *Schema) Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) *graphql.Response {
+  cost, err := complexity(query, operationName, variables)
+  if err == nil && cost > maxComplexity {
+    return &graphql.Response{Errors: []*errors.QueryError{
+      errors.Errorf("сложность запроса %d больше допустимой %d, уменьшите first или вложенность", cost, maxComplexity),
+    }}
+  }
+  // ошибки разбора запроса вернет сама схема
+  return s.schema.Exec(withLoaders(ctx, s.bl), query, operationName, variables)
+}
@@ -0,0 +1,122 @@
+package graphql
+
+import (
+  "context"
+  "github.com/graph-gophers/graphql-go"
+  "strconv"
+  "time"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+)
+
+type taskConnection struct {
+  nodes []*taskResolver
+  info  dto.PageInfo
+}
+
+// newTaskConnection готовит загрузку интервалов всех задач страницы одним запросом
+func newTaskConnection(ctx context.Context, bl *bl.BL, tasks []dto.Task, info dto.PageInfo) *taskConnection {
+  l := loadersFromCtx(ctx)
+  c := &taskConnection{nodes: make([]*taskResolver, 0, len(tasks)), info: info}
+  for _, task := range tasks {
+    l.entries.want(task)
+    l.people.want(task.IdPerson)
+    c.nodes = append(c.nodes, &taskResolver{bl: bl, t: task})
+  }
+  return c
+}
+
+func (c *taskConnection) Nodes() []*taskResolver {
+  return c.nodes
+}
+
+func (c *taskConnection) NextCursor() *string {
+  return cursorOf(c.info)
+}
+
+func (c *taskConnection) Total() *int32 {
+  return totalOf(c.info)
+}
+
+type taskResolver struct {
+  bl *bl.BL
+  t  dto.Task
+}
+
+func (r *taskResolver) ID() graphql.ID {
+  return graphql.ID(r.t.IdTask)
+}
+
+func (r *taskResolver) Name() string {
+  return r.t.TaskName
+}
+
+func (r *taskResolver) Status() string {
+  return r.t.TaskStatus
+}
+
+func (r *taskResolver) Version() int32 {
+  return int32(r.t.Version)
+}
+
+func (r *taskResolver) Person(ctx context.Context) (*personResolver, error) {
+  person, ok, err := loadersFromCtx(ctx).people.load(ctx, r.t.IdPerson)
+  if err != nil || !ok {
+    return nil, err
+  }
+  return &personResolver{bl: r.bl, p: person}, nil
+}
+
+func (r *taskResolver) Entries(ctx context.Context) ([]*entryResolver, error) {
+  entries, _, err := loadersFromCtx(ctx).entries.load(ctx, r.t)
+  if err != nil {
+    return nil, err
+  }
+  res := make([]*entryResolver, 0, len(entries))
+  for _, entry := range entries {
+    res = append(res, &entryResolver{e: entry})
+  }
+  return res, nil
+}
+
+func (r *taskResolver) TotalSeconds(ctx context.Context) (int32, error) {
+  entries, _, err := loadersFromCtx(ctx).entries.load(ctx, r.t)
+  if err != nil {
+    return 0, err
+  }
+  var total time.Duration
+  for _, entry := range entries {
+    if entry.EndTime != nil {
+      total += entry.EndTime.Sub(entry.StartTime)
+    }
+  }
+  return int32(total / time.Second), nil
+}
+
+type entryResolver struct {
+  e dto.TimeTask
+}
+
+func (r *entryResolver) ID() graphql.ID {
+  return graphql.ID(strconv.Itoa(r.e.ID))
+}
+
+func (r *entryResolver) Start() string {
+  return r.e.StartTime.Format(time.RFC3339)
+}
+
+func (r *entryResolver) End() *string {
+  if r.e.EndTime == nil {
+    return nil
+  }
+  end := r.e.EndTime.Format(time.RFC3339)
+  return &end
+}
+
+func (r *entryResolver) Seconds() *int32 {
+  if r.e.EndTime == nil {
+    return nil
+  }
+  seconds := int32(r.e.EndTime.Sub(r.e.StartTime) / time.Second)
+  return &seconds
+}
@@ -0,0 +1,119 @@
+package grpc
+
+import (
+  "context"
+  "errors"
+  "google.golang.org/grpc"
+  "google.golang.org/grpc/codes"
+  "google.golang.org/grpc/metadata"
+  "google.golang.org/grpc/status"
+  "log/slog"
+  "strings"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/repo"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/cursor"
+)
+
+// newInterceptor повторяет для вызовов gRPC цепочку http: логгер в контексте, проверка
+// API-ключа или JWT из метаданных authorization/x-api-key, организация из x-organization-id.
+// Ошибки бизнес-логики переводятся в коды gRPC
+func newInterceptor(l *slog.Logger, auth repo.IAuthBL) grpc.UnaryServerInterceptor {
+  return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+    startTime := time.Now()
+    log := l.With(slog.String("method", info.FullMethod), slog.Any("startTime", startTime))
+    log.Debug("requestStart")
+    ctx = context.WithValue(ctx, "logger", log)
+    ctx = context.WithValue(ctx, "startTime", startTime)
+
+    md, _ := metadata.FromIncomingContext(ctx)
+    principal, err := authenticate(ctx, auth, md)
+    if err != nil {
+      code := codes.Internal
+      if errors.Is(err, repo.ErrUnauthorized) {
+        code = codes.Unauthenticated
+      }
+      return nil, done(log, startTime, status.Error(code, err.Error()))
+    }
+    org, err := auth.ResolveTenant(ctx, principal, first(md, "x-organization-id"))
+    if err != nil {
+      return nil, done(log, startTime, status.Error(codeFor(err, codes.InvalidArgument), err.Error()))
+    }
+
+    log = log.With(slog.Group("principal",
+      slog.String("id", principal.ID),
+      slog.String("method", principal.Method),
+      slog.String("organization", org)))
+    ctx = utils.WithPrincipal(ctx, principal)
+    ctx = utils.WithTenant(ctx, org)
+    ctx = context.WithValue(ctx, "logger", log)
+
+    res, err := handler(ctx, req)
+    if err != nil {
+      if _, ok := status.FromError(err); !ok {
+        err = status.Error(codeFor(err, codes.Internal), err.Error())
+      }
+    }
+    return res, done(log, startTime, err)
+  }
+}
+
+func authenticate(ctx context.Context, auth repo.IAuthBL, md metadata.MD) (*dto.Principal, error) {
+  if key := first(md, "x-api-key"); key != "" {
+    return auth.AuthenticateKey(ctx, key)
+  }
+  scheme, credential, ok := strings.Cut(first(md, "authorization"), " ")
+  if !ok || !strings.EqualFold(scheme, "Bearer") || credential == "" {
+    return nil, repo.ErrUnauthorized
+  }
+  if repo.IsApiKey(credential) {
+    return auth.AuthenticateKey(ctx, credential)
+  }
+  return auth.AuthenticateToken(ctx, credential)
+}
+
+func first(md metadata.MD, key string) string {
+  if values := md.Get(key); len(values) > 0 {
+    return values[0]
+  }
+  return ""
+}
+
+// done пишет итог вызова в лог так же, как http обертка
+func done(log *slog.Logger, startTime time.Time, err error) error {
+  attrs := []any{slog.Any("duration", time.Since(startTime).String())}
+  if err != nil {
+    st := status.Convert(err)
+    attrs = append(attrs, slog.Group("error", slog.String("msg", st.Message()), slog.String("code", st.Code().String())))
+  }
+  log.Info("reqDone", attrs...)
+  return err
+}
+
+// codeFor подбирает код gRPC для ошибок бизнес-логики, def для остальных
+func codeFor(err error, def codes.Code) codes.Code {
+  switch {
+  case errors.Is(err, repo.ErrUnauthorized):
+    return codes.Unauthenticated
+  case errors.Is(err, repo.ErrForbidden):
+    return codes.PermissionDenied
+  case errors.Is(err, repo.ErrInvalid):
+    return codes.InvalidArgument
+  case errors.Is(err, repo.ErrPreconditionFailed):
+    return codes.FailedPrecondition
+  case errors.Is(err, cursor.ErrInvalid):
+    return codes.InvalidArgument
+  }
+  return def
+}
+
+// failed ошибка вызова: известные ошибки бизнес-логики получают свой код, остальные def
+func failed(err error, def codes.Code) error {
+  return status.Error(codeFor(err, def), err.Error())
+}
+
+// invalid ошибка входных данных вызова
+func invalid(format string, args ...interface{}) error {
+  return status.Errorf(codes.InvalidArgument, format, args...)
+}
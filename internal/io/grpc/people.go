@@ -0,0 +1,157 @@
+package grpc
+
+import (
+  "context"
+  "google.golang.org/grpc/codes"
+  timetrackerv1 "timetracker/api/timetracker/v1"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/utils"
+)
+
+const maxPageLimit = 100
+
+type peopleService struct {
+  timetrackerv1.UnimplementedPeopleServiceServer
+  bl *bl.BL
+}
+
+func (s *peopleService) ListPeople(ctx context.Context, req *timetrackerv1.ListPeopleRequest) (*timetrackerv1.ListPeopleResponse, error) {
+  filter := &dto.PeopleFilter{
+    Person: dto.Person{
+      People: dto.People{
+        Surname:    req.GetSurname(),
+        Name:       req.GetName(),
+        Patronymic: req.GetPatronymic(),
+        Address:    req.GetAddress(),
+      },
+      Passport: dto.Passport{
+        PassportNumber: req.GetPassportNumber(),
+      },
+    },
+    TeamID:        req.GetTeamId(),
+    HasActiveTask: req.HasActiveTask,
+  }
+  if filter.TeamID != "" && !utils.IsValidUUID(filter.TeamID) {
+    return nil, invalid("team_id %s не валидный", filter.TeamID)
+  }
+  if req.GetCreatedAfter() != nil {
+    after := req.GetCreatedAfter().AsTime()
+    filter.CreatedAfter = &after
+  }
+  sort, err := dto.ParseSort(req.GetSort(), dto.PeopleSortFields)
+  if err != nil {
+    return nil, invalid("%s", err.Error())
+  }
+  filter.Sort = sort
+  page, err := pageRequest(req.GetCursor(), req.GetLimit(), req.GetCount())
+  if err != nil {
+    return nil, err
+  }
+
+  people, info, err := s.bl.People.GetPeople(ctx, filter, page)
+  if err != nil {
+    return nil, failed(err, codes.Internal)
+  }
+  resp := &timetrackerv1.ListPeopleResponse{
+    People:     make([]*timetrackerv1.Person, 0, len(people)),
+    NextCursor: info.NextCursor,
+  }
+  if info.Total != nil {
+    total := int32(*info.Total)
+    resp.Total = &total
+  }
+  for i := range people {
+    resp.People = append(resp.People, personFromDto(&people[i]))
+  }
+  return resp, nil
+}
+
+func (s *peopleService) GetPerson(ctx context.Context, req *timetrackerv1.GetPersonRequest) (*timetrackerv1.Person, error) {
+  if !utils.IsValidUUID(req.GetId()) {
+    return nil, invalid("uuid %s не валидный", req.GetId())
+  }
+  person, err := s.bl.People.GetPeopleUUID(ctx, req.GetId())
+  if err != nil {
+    return nil, failed(err, codes.NotFound)
+  }
+  return personFromDto(person), nil
+}
+
+func (s *peopleService) CreatePerson(ctx context.Context, req *timetrackerv1.CreatePersonRequest) (*timetrackerv1.Person, error) {
+  if err := utils.PassportValidate(req.GetPassportNumber()); err != nil {
+    return nil, invalid("%s", err.Error())
+  }
+  person, err := s.bl.People.CreatePeople(ctx, dto.Passport{PassportNumber: req.GetPassportNumber()})
+  if err != nil {
+    return nil, failed(err, codes.AlreadyExists)
+  }
+  return personFromDto(person), nil
+}
+
+func (s *peopleService) UpdatePerson(ctx context.Context, req *timetrackerv1.UpdatePersonRequest) (*timetrackerv1.Person, error) {
+  if !utils.IsValidUUID(req.GetId()) {
+    return nil, invalid("uuid %s не валидный", req.GetId())
+  }
+  patch := dto.PersonPatch{
+    ID:             req.GetId(),
+    Version:        int(req.GetVersion()),
+    Surname:        patchString(req.Surname),
+    Name:           patchString(req.Name),
+    Patronymic:     patchString(req.Patronymic),
+    Address:        patchString(req.Address),
+    PassportNumber: patchString(req.PassportNumber),
+    ManagerID:      patchString(req.ManagerId),
+  }
+  person, err := s.bl.People.UpdatePeople(ctx, patch)
+  if err != nil {
+    return nil, failed(err, codes.NotFound)
+  }
+  return personFromDto(person), nil
+}
+
+func (s *peopleService) DeletePerson(ctx context.Context, req *timetrackerv1.DeletePersonRequest) (*timetrackerv1.DeletePersonResponse, error) {
+  if !utils.IsValidUUID(req.GetId()) {
+    return nil, invalid("uuid %s не валидный", req.GetId())
+  }
+  if err := s.bl.People.DeletePeople(ctx, req.GetId(), int(req.GetVersion())); err != nil {
+    return nil, failed(err, codes.NotFound)
+  }
+  return &timetrackerv1.DeletePersonResponse{}, nil
+}
+
+// patchString переводит optional поле в изменение по RFC 7396: пустая строка равна null
+func patchString(value *string) dto.PatchString {
+  switch {
+  case value == nil:
+    return dto.PatchString{}
+  case *value == "":
+    return dto.PatchString{Set: true, Null: true}
+  }
+  return dto.PatchString{Set: true, Value: *value}
+}
+
+// pageRequest параметры страницы с теми же ограничениями, что и в http
+func pageRequest(cursor string, limit int32, count bool) (dto.PageRequest, error) {
+  page := dto.PageRequest{Cursor: cursor, Limit: 10, Count: count}
+  if limit != 0 {
+    if limit < 1 || limit > maxPageLimit {
+      return page, invalid("некорректное значение параметра limit")
+    }
+    page.Limit = int(limit)
+  }
+  return page, nil
+}
+
+func personFromDto(p *dto.Person) *timetrackerv1.Person {
+  return &timetrackerv1.Person{
+    Id:             p.ID,
+    Surname:        p.Surname,
+    Name:           p.Name,
+    Patronymic:     p.Patronymic,
+    Address:        p.Address,
+    PassportNumber: p.PassportNumber,
+    ManagerId:      p.ManagerID,
+    Version:        int32(p.Version),
+  }
+}
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+  "context"
+  "google.golang.org/grpc/codes"
+  timetrackerv1 "timetracker/api/timetracker/v1"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/utils"
+)
+
+type reportService struct {
+  timetrackerv1.UnimplementedReportServiceServer
+  bl *bl.BL
+}
+
+func (s *reportService) PersonWorktime(ctx context.Context, req *timetrackerv1.PersonWorktimeRequest) (*timetrackerv1.PersonWorktimeResponse, error) {
+  if !utils.IsValidUUID(req.GetPersonId()) {
+    return nil, invalid("uuid %s не валидный", req.GetPersonId())
+  }
+  if err := timeRange(req.GetStart(), req.GetEnd()); err != nil {
+    return nil, err
+  }
+  tasks, err := s.bl.Task.TimeTasks(ctx, req.GetPersonId(), req.GetStart(), req.GetEnd())
+  if err != nil {
+    return nil, failed(err, codes.InvalidArgument)
+  }
+  return &timetrackerv1.PersonWorktimeResponse{Tasks: taskTimes(tasks)}, nil
+}
+
+func (s *reportService) TeamWorktime(ctx context.Context, req *timetrackerv1.TeamWorktimeRequest) (*timetrackerv1.TeamWorktimeResponse, error) {
+  if !utils.IsValidUUID(req.GetTeamId()) {
+    return nil, invalid("uuid %s не валидный", req.GetTeamId())
+  }
+  if err := timeRange(req.GetStart(), req.GetEnd()); err != nil {
+    return nil, err
+  }
+  times, err := s.bl.Team.TeamTimes(ctx, req.GetTeamId(), req.GetStart(), req.GetEnd())
+  if err != nil {
+    return nil, failed(err, codes.NotFound)
+  }
+  resp := &timetrackerv1.TeamWorktimeResponse{Members: make([]*timetrackerv1.MemberTime, 0, len(times))}
+  for _, member := range times {
+    resp.Members = append(resp.Members, &timetrackerv1.MemberTime{
+      PersonId:  member.IDPerson,
+      Surname:   member.Surname,
+      Name:      member.Name,
+      TotalTime: member.TotalTime,
+      Tasks:     taskTimes(member.Tasks),
+    })
+  }
+  return resp, nil
+}
+
+// timeRange те же проверки периода, что и у отчетов http
+func timeRange(start, end string) error {
+  if len(start) == 0 || len(end) == 0 || !utils.IsValidDateTime(start) || !utils.IsValidDateTime(end) {
+    return invalid("проблемма входных данных")
+  }
+  if !utils.IsValidTimeRange(start, end) {
+    return invalid("дата конца диапозона раньше чем начало")
+  }
+  return nil
+}
+
+func taskTimes(tasks []dto.TaskTimeResult) []*timetrackerv1.TaskTime {
+  res := make([]*timetrackerv1.TaskTime, 0, len(tasks))
+  for _, task := range tasks {
+    res = append(res, &timetrackerv1.TaskTime{
+      TaskId:    task.IDTask,
+      TaskName:  task.TaskName,
+      TotalTime: task.TotalTime,
+    })
+  }
+  return res
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import (
+  "context"
+  "google.golang.org/grpc"
+  "log/slog"
+  "net"
+  timetrackerv1 "timetracker/api/timetracker/v1"
+  "timetracker/internal/bl"
+)
+
+// serv gRPC API поверх тех же интерфейсов бизнес-логики, что и http контроллер
+type serv struct {
+  l       *slog.Logger
+  address string
+  srv     *grpc.Server
+}
+
+func New(address string, log *slog.Logger, bl *bl.BL) *serv {
+  l := log.With(slog.String("layer", "grpc"))
+  srv := grpc.NewServer(grpc.UnaryInterceptor(newInterceptor(l, bl.Auth)))
+  timetrackerv1.RegisterPeopleServiceServer(srv, &peopleService{bl: bl})
+  timetrackerv1.RegisterTaskServiceServer(srv, &taskService{bl: bl})
+  timetrackerv1.RegisterReportServiceServer(srv, &reportService{bl: bl})
+  return &serv{
+    l:       l,
+    address: address,
+    srv:     srv,
+  }
+}
+
+func (s *serv) Run() error {
+  lis, err := net.Listen("tcp", s.address)
+  if err != nil {
+    return err
+  }
+  go func() {
+    if err := s.srv.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+      s.l.Error("listen", slog.String("error", err.Error()))
+    }
+  }()
+  return nil
+}
+
+// Stop дожидается завершения текущих вызовов, по истечении ctx обрывает их
+func (s *serv) Stop(ctx context.Context) {
+  done := make(chan struct{})
+  go func() {
+    s.srv.GracefulStop()
+    close(done)
+  }()
+  select {
+  case <-done:
+  case <-ctx.Done():
+    s.srv.Stop()
+    s.l.Error("Ошибка выключения gRPC сервера", slog.String("error", ctx.Err().Error()))
+  }
+  s.l.Info("gRPC сервер успешно выключен")
+}
@@ -0,0 +1,111 @@
+package grpc
+
+import (
+  "context"
+  "google.golang.org/grpc/codes"
+  "google.golang.org/protobuf/types/known/timestamppb"
+  timetrackerv1 "timetracker/api/timetracker/v1"
+  "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/utils"
+  "timetracker/internal/utils/const/status"
+)
+
+type taskService struct {
+  timetrackerv1.UnimplementedTaskServiceServer
+  bl *bl.BL
+}
+
+func (s *taskService) CreateTask(ctx context.Context, req *timetrackerv1.CreateTaskRequest) (*timetrackerv1.Task, error) {
+  if !utils.IsValidUUID(req.GetPersonId()) {
+    return nil, invalid("uuid %s не валидный", req.GetPersonId())
+  }
+  task, err := s.bl.Task.CreateTask(ctx, &dto.Task{IdPerson: req.GetPersonId(), TaskName: req.GetName()})
+  if err != nil {
+    return nil, failed(err, codes.InvalidArgument)
+  }
+  return taskFromDto(task), nil
+}
+
+func (s *taskService) ListTasks(ctx context.Context, req *timetrackerv1.ListTasksRequest) (*timetrackerv1.ListTasksResponse, error) {
+  if !utils.IsValidUUID(req.GetPersonId()) {
+    return nil, invalid("uuid %s не валидный", req.GetPersonId())
+  }
+  page, err := pageRequest(req.GetCursor(), req.GetLimit(), req.GetCount())
+  if err != nil {
+    return nil, err
+  }
+
+  tasks, info, err := s.bl.Task.GetTasks(ctx, req.GetPersonId(), page)
+  if err != nil {
+    return nil, failed(err, codes.Internal)
+  }
+  resp := &timetrackerv1.ListTasksResponse{
+    Tasks:      make([]*timetrackerv1.Task, 0, len(tasks)),
+    NextCursor: info.NextCursor,
+  }
+  if info.Total != nil {
+    total := int32(*info.Total)
+    resp.Total = &total
+  }
+  for i := range tasks {
+    resp.Tasks = append(resp.Tasks, taskFromDto(&tasks[i]))
+  }
+  return resp, nil
+}
+
+func (s *taskService) StartTimer(ctx context.Context, req *timetrackerv1.TimerRequest) (*timetrackerv1.TimerResponse, error) {
+  return s.timer(ctx, req, s.bl.Task.StartTask, status.Work)
+}
+
+func (s *taskService) PauseTimer(ctx context.Context, req *timetrackerv1.TimerRequest) (*timetrackerv1.TimerResponse, error) {
+  return s.timer(ctx, req, s.bl.Task.PauseTask, status.Pause)
+}
+
+func (s *taskService) CompleteTask(ctx context.Context, req *timetrackerv1.TimerRequest) (*timetrackerv1.TimerResponse, error) {
+  return s.timer(ctx, req, s.bl.Task.CompleteTask, status.Complete)
+}
+
+// timer общая часть действий над таймером, задача адресуется без человека, как в /v1
+func (s *taskService) timer(ctx context.Context, req *timetrackerv1.TimerRequest,
+  action func(ctx context.Context, idP, idT string, version int) error, next string) (*timetrackerv1.TimerResponse, error) {
+  if !utils.IsValidUUID(req.GetTaskId()) {
+    return nil, invalid("uuid %s не валидный", req.GetTaskId())
+  }
+  if err := action(ctx, "", req.GetTaskId(), int(req.GetVersion())); err != nil {
+    return nil, failed(err, codes.InvalidArgument)
+  }
+  return &timetrackerv1.TimerResponse{TaskId: req.GetTaskId(), Status: next}, nil
+}
+
+func (s *taskService) AddEntry(ctx context.Context, req *timetrackerv1.AddEntryRequest) (*timetrackerv1.TimeEntry, error) {
+  if !utils.IsValidUUID(req.GetTaskId()) {
+    return nil, invalid("uuid %s не валидный", req.GetTaskId())
+  }
+  if req.GetStart() == nil || req.GetEnd() == nil {
+    return nil, invalid("не заданы начало и конец интервала")
+  }
+  entry, err := s.bl.Task.AddEntry(ctx, req.GetTaskId(), req.GetStart().AsTime(), req.GetEnd().AsTime())
+  if err != nil {
+    return nil, failed(err, codes.InvalidArgument)
+  }
+  resp := &timetrackerv1.TimeEntry{
+    Id:     int64(entry.ID),
+    TaskId: entry.IDTask,
+    Start:  timestamppb.New(entry.StartTime),
+  }
+  if entry.EndTime != nil {
+    resp.End = timestamppb.New(*entry.EndTime)
+  }
+  return resp, nil
+}
+
+func taskFromDto(t *dto.Task) *timetrackerv1.Task {
+  return &timetrackerv1.Task{
+    Id:       t.IdTask,
+    PersonId: t.IdPerson,
+    Name:     t.TaskName,
+    Status:   t.TaskStatus,
+    Version:  int32(t.Version),
+  }
+}
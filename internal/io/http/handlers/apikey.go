@@ -0,0 +1,95 @@
+package handlers
+
+import (
+  "errors"
+  "fmt"
+  "log/slog"
+  "net/http"
+  "strings"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// CreateApiKey выпускает новый API-ключ
+// @Summary Создание API-ключа
+// @Description Выпускает API-ключ, открытое значение ключа возвращается только в этом ответе. Доступ определяется действием apikey.manage политики
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body models.ApiKeyCreate true "Параметры ключа"
+// @Success 200 {object} dto.ApiKey
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 401 {object} models.ErrorResponse "Требуется аутентификация"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/api-keys [post]
+func (c *Controller) CreateApiKey(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  var keyReq models.ApiKeyCreate
+  body, err := utils.DecodeRequestBody(req, &keyReq)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+  if len(strings.TrimSpace(keyReq.Name)) == 0 {
+    return nil, http.StatusBadRequest, slog.Attr{}, errors.New("не заполнено имя ключа")
+  }
+  if keyReq.PersonID != "" && !utils.IsValidUUID(keyReq.PersonID) {
+    attr := slog.String("not uuid", keyReq.PersonID)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", keyReq.PersonID)
+  }
+
+  key, err := c.bl.Auth.CreateApiKey(req.Context(), dto.ApiKey{
+    Name:     keyReq.Name,
+    PersonID: keyReq.PersonID,
+    Role:     keyReq.Role,
+  })
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return key, http.StatusOK, slog.String("keyId", key.ID), nil
+}
+
+// GetApiKeys возвращает список API-ключей
+// @Summary Список API-ключей
+// @Description Возвращает выпущенные API-ключи без открытых значений. Доступ определяется действием apikey.manage политики
+// @Tags admin
+// @Produce json
+// @Success 200 {object} []dto.ApiKey
+// @Failure 401 {object} models.ErrorResponse "Требуется аутентификация"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/api-keys [get]
+func (c *Controller) GetApiKeys(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  keys, err := c.bl.Auth.GetApiKeys(req.Context())
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  return keys, http.StatusOK, slog.Int("total", len(keys)), nil
+}
+
+// DeleteApiKey отзывает API-ключ
+// @Summary Отзыв API-ключа
+// @Description Удаляет API-ключ по идентификатору. Доступ определяется действием apikey.manage политики
+// @Tags admin
+// @Produce json
+// @Param id path string true "Идентификатор ключа"
+// @Success 200 {object} models.Ok
+// @Failure 400 {object} models.ErrorResponse "Неверный идентификатор"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Ключ не найден"
+// @Router /v1/admin/api-keys/{id} [delete]
+func (c *Controller) DeleteApiKey(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("id")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+
+  err := c.bl.Auth.DeleteApiKey(req.Context(), id)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return models.Ok{
+    Msg:    fmt.Sprintf("ключ %s отозван", id),
+    Status: http.StatusOK,
+  }, http.StatusOK, slog.Attr{}, nil
+}
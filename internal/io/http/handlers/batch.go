@@ -0,0 +1,73 @@
+package handlers
+
+import (
+  "log/slog"
+  "net/http"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// Batch выполняет пакет операций
+// @Summary Пакет операций
+// @Description Выполняет по порядку операции create_person, create_task, start, pause, complete и entry.
+// @Description При atomic все операции идут в одной транзакции и при первой ошибке откатываются, ответ получает статус этой ошибки.
+// @Description Без atomic операции независимы, у каждой свой статус в results.
+// @Description В person_id и task_id можно сослаться на запись, созданную ранее в пакете: "$0" - id результата операции 0
+// @Tags batch
+// @Accept json
+// @Produce json
+// @Param batch body models.BatchReq true "Операции пакета"
+// @Param Idempotency-Key header string false "Ключ идемпотентности"
+// @Success 200 {object} models.BatchResp "Результаты операций"
+// @Failure 400 {object} models.ErrorResponse "Некорректный пакет"
+// @Failure 403 {object} models.BatchResp "Пакет atomic откатан: операции не хватило прав"
+// @Failure 409 {object} models.BatchResp "Пакет atomic откатан из-за ошибки операции"
+// @Router /v1/batch [post]
+func (c *Controller) Batch(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  var batch models.BatchReq
+  body, err := utils.DecodeRequestBody(req, &batch)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  results, err := c.bl.Batch.Run(req.Context(), batch.Operations, batch.Atomic)
+  if results == nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  resp := models.BatchResp{
+    Atomic:    batch.Atomic,
+    Committed: batch.Atomic && err == nil,
+    Results:   make([]models.BatchOpResult, 0, len(results)),
+  }
+  for i, res := range results {
+    opRes := models.BatchOpResult{Index: i, Status: http.StatusOK}
+    if res.Err != nil {
+      opRes.Status = statusFor(res.Err, batchStatus(batch.Operations[i].Op))
+      opRes.Error = res.Err.Error()
+    } else if task, ok := res.Result.(*dto.Task); ok {
+      urls, err := c.taskUrls(req, task)
+      if err != nil {
+        return nil, http.StatusInternalServerError, slog.Attr{}, err
+      }
+      opRes.Result = models.TaskFromDto(task, urls)
+    } else if res.Result != nil {
+      opRes.Result = res.Result
+    }
+    resp.Results = append(resp.Results, opRes)
+  }
+  if err != nil {
+    attr := slog.Group("error", slog.String("msg", err.Error()))
+    return resp, statusFor(err, http.StatusConflict), attr, nil
+  }
+  return resp, http.StatusOK, slog.Int("operations", len(results)), nil
+}
+
+// batchStatus статус ошибки операции по умолчанию, как у отдельного маршрута
+func batchStatus(op string) int {
+  if op == dto.BatchCreatePerson {
+    return http.StatusConflict
+  }
+  return http.StatusBadRequest
+}
@@ -1,19 +1,94 @@
 package handlers
 
 import (
+  "errors"
   "log/slog"
+  "net/http"
+  "net/url"
+  "strconv"
+  "strings"
   "timetracker/internal/bl"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/repo"
+  "timetracker/internal/io/graphql"
+  "timetracker/internal/io/http/links"
+  "timetracker/internal/utils/cursor"
 )
 
+const maxPageLimit = 100
+
 //import (
 //  //"auth-ms/internal/bl"
 //)
 
 type Controller struct {
-  bl *bl.BL
-  l  *slog.Logger
+  bl    *bl.BL
+  gql   *graphql.Schema
+  links *links.Links
+  l     *slog.Logger
+  // closing закрывается при выключении сервера, потоки событий по нему завершаются
+  closing <-chan struct{}
+}
+
+func NewController(bl *bl.BL, links *links.Links, log *slog.Logger, closing <-chan struct{}) *Controller {
+  return &Controller{bl: bl, gql: graphql.New(bl), links: links, l: log, closing: closing}
+}
+
+// statusFor подбирает http статус для ошибок бизнес-логики, def для остальных
+func statusFor(err error, def int) int {
+  switch {
+  case errors.Is(err, repo.ErrUnauthorized):
+    return http.StatusUnauthorized
+  case errors.Is(err, repo.ErrForbidden):
+    return http.StatusForbidden
+  case errors.Is(err, repo.ErrInvalid):
+    return http.StatusBadRequest
+  case errors.Is(err, repo.ErrPreconditionFailed):
+    return http.StatusPreconditionFailed
+  case errors.Is(err, repo.ErrKeyReused):
+    return http.StatusUnprocessableEntity
+  case errors.Is(err, repo.ErrKeyInProgress):
+    return http.StatusConflict
+  case errors.Is(err, cursor.ErrInvalid):
+    return http.StatusBadRequest
+  }
+  return def
+}
+
+// isLegacy true для запроса к маршруту без версии
+func isLegacy(req *http.Request) bool {
+  return !strings.HasPrefix(req.URL.Path, "/v1/")
 }
 
-func NewController(bl *bl.BL, log *slog.Logger) *Controller {
-  return &Controller{bl: bl, l: log}
+// parsePage читает параметры страницы: cursor и limit, page для старых клиентов и count
+func parsePage(query url.Values) (dto.PageRequest, error) {
+  page := dto.PageRequest{
+    Cursor: query.Get("cursor"),
+    Limit:  10,
+  }
+  if limitStr := query.Get("limit"); limitStr != "" {
+    limit, err := strconv.Atoi(limitStr)
+    if err != nil || limit < 1 || limit > maxPageLimit {
+      return page, errors.New("некорректное значение параметра limit")
+    }
+    page.Limit = limit
+  }
+  if pageStr := query.Get("page"); pageStr != "" {
+    if page.Cursor != "" {
+      return page, errors.New("параметры cursor и page нельзя использовать вместе")
+    }
+    number, err := strconv.Atoi(pageStr)
+    if err != nil || number < 1 {
+      return page, errors.New("некорректное значение параметра page")
+    }
+    page.Offset = (number - 1) * page.Limit
+  }
+  if countStr := query.Get("count"); countStr != "" {
+    count, err := strconv.ParseBool(countStr)
+    if err != nil {
+      return page, errors.New("некорректное значение параметра count")
+    }
+    page.Count = count
+  }
+  return page, nil
 }
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+  "fmt"
+  "net/http"
+  "slices"
+  "strconv"
+  "strings"
+  "timetracker/internal/bl/repo"
+)
+
+// etag строгий ETag записи по ее версии
+func etag(version int) string {
+  return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatch возвращает версию из заголовка If-Match, 0 если заголовка нет или он равен *.
+// Теги сравниваются строго: слабый тег не совпадает ни с одной версией, и без строгих тегов ответ 412.
+// Для списка из нескольких строгих тегов текущую версию дает current, дальше передается она,
+// так изменение записи после проверки все равно отклоняется
+func ifMatch(req *http.Request, current func() (int, error)) (int, error) {
+  header := strings.TrimSpace(req.Header.Get("If-Match"))
+  if header == "" || header == "*" {
+    return 0, nil
+  }
+  var versions []int
+  for _, tag := range strings.Split(header, ",") {
+    tag = strings.TrimSpace(tag)
+    if strings.HasPrefix(tag, "W/") {
+      continue
+    }
+    version, err := strconv.Atoi(strings.Trim(tag, `"`))
+    if err != nil || version < 1 {
+      return 0, fmt.Errorf("%w: некорректный заголовок If-Match", repo.ErrInvalid)
+    }
+    versions = append(versions, version)
+  }
+  switch len(versions) {
+  case 0:
+    return 0, fmt.Errorf("%w: в If-Match только слабые теги", repo.ErrPreconditionFailed)
+  case 1:
+    return versions[0], nil
+  }
+  version, err := current()
+  if err != nil {
+    return 0, err
+  }
+  if !slices.Contains(versions, version) {
+    return 0, fmt.Errorf("%w: текущая версия %d", repo.ErrPreconditionFailed, version)
+  }
+  return version, nil
+}
+
+// personVersion текущая версия человека для ifMatch
+func (c *Controller) personVersion(req *http.Request, id string) func() (int, error) {
+  return func() (int, error) {
+    person, err := c.bl.People.GetPeopleUUID(req.Context(), id)
+    if err != nil {
+      return 0, err
+    }
+    return person.Version, nil
+  }
+}
+
+// taskVersion текущая версия задачи для ifMatch
+func (c *Controller) taskVersion(req *http.Request, idP, idT string) func() (int, error) {
+  return func() (int, error) {
+    task, err := c.bl.Task.GetTask(req.Context(), idP, idT)
+    if err != nil {
+      return 0, err
+    }
+    return task.Version, nil
+  }
+}
+
+// notModified true, если тег совпадает с одним из тегов If-None-Match
+func notModified(req *http.Request, tag string) bool {
+  header := req.Header.Get("If-None-Match")
+  if header == "" {
+    return false
+  }
+  for _, candidate := range strings.Split(header, ",") {
+    candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+    if candidate == "*" || candidate == tag {
+      return true
+    }
+  }
+  return false
+}
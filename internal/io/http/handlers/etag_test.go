@@ -0,0 +1,59 @@
+package handlers
+
+import (
+  "errors"
+  "net/http/httptest"
+  "testing"
+  "timetracker/internal/bl/repo"
+)
+
+func TestIfMatch(t *testing.T) {
+  const current = 3
+  cases := map[string]struct {
+    header string
+    want   int
+    err    error
+  }{
+    "без заголовка":                    {"", 0, nil},
+    "любая версия":                     {"*", 0, nil},
+    "строгий тег":                      {`"2"`, 2, nil},
+    "слабый тег":                       {`W/"3"`, 0, repo.ErrPreconditionFailed},
+    "только слабые теги":               {`W/"2", W/"3"`, 0, repo.ErrPreconditionFailed},
+    "слабый и строгий тег":             {`W/"3", "2"`, 2, nil},
+    "список с текущей версией":         {`"1", "3", "5"`, current, nil},
+    "список без текущей версии":        {`"1","2"`, 0, repo.ErrPreconditionFailed},
+    "список со слабой текущей версией": {`W/"3", "1", "2"`, 0, repo.ErrPreconditionFailed},
+    "не тег":                           {`"abc"`, 0, repo.ErrInvalid},
+    "пустой элемент списка":            {`"1",,"3"`, 0, repo.ErrInvalid},
+  }
+  for name, c := range cases {
+    t.Run(name, func(t *testing.T) {
+      req := httptest.NewRequest("DELETE", "/v1/people/id", nil)
+      if c.header != "" {
+        req.Header.Set("If-Match", c.header)
+      }
+      got, err := ifMatch(req, func() (int, error) { return current, nil })
+      if c.err != nil {
+        if !errors.Is(err, c.err) {
+          t.Fatalf("ожидалась ошибка %v, получено %d, %v", c.err, got, err)
+        }
+        return
+      }
+      if err != nil || got != c.want {
+        t.Fatalf("ожидалась версия %d, получено %d, %v", c.want, got, err)
+      }
+    })
+  }
+}
+
+func TestIfMatchSingleTagWithoutLookup(t *testing.T) {
+  req := httptest.NewRequest("DELETE", "/v1/people/id", nil)
+  req.Header.Set("If-Match", `"4"`)
+  got, err := ifMatch(req, func() (int, error) {
+    t.Fatal("для одного тега версия проверяется при изменении, без отдельного чтения")
+    return 0, nil
+  })
+  if err != nil || got != 4 {
+    t.Fatalf("ожидалась версия 4, получено %d, %v", got, err)
+  }
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+  "encoding/json"
+  "fmt"
+  "log/slog"
+  "net/http"
+  "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// heartbeat интервал комментариев, которые не дают прокси закрыть простаивающий поток
+const heartbeat = 15 * time.Second
+
+// Events поток событий задач
+// @Summary Поток событий задач (Server-Sent Events)
+// @Description Отдает события task.status, timer.started, timer.stopped и entry.added по задачам, которые вызывающему разрешено читать.
+// @Description Поле id события можно передать в Last-Event-ID при переподключении, чтобы получить пропущенные события
+// @Tags events
+// @Produce text/event-stream
+// @Param person_id query string false "Только события задач человека"
+// @Param team_id query string false "Только события задач участников команды"
+// @Param Last-Event-ID header string false "id последнего полученного события"
+// @Success 200 {object} dto.Event "Поток событий"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/events [get]
+func (c *Controller) Events(w http.ResponseWriter, req *http.Request) {
+  ctxLogger := req.Context().Value("logger").(*slog.Logger)
+  startTime := req.Context().Value("startTime").(time.Time)
+
+  query := req.URL.Query()
+  filter := dto.EventFilter{PersonID: query.Get("person_id"), TeamID: query.Get("team_id")}
+  for _, id := range []string{filter.PersonID, filter.TeamID} {
+    if id != "" && !utils.IsValidUUID(id) {
+      writeStreamError(w, ctxLogger, http.StatusBadRequest, fmt.Errorf("uuid %s не валидный", id))
+      return
+    }
+  }
+  flusher, ok := w.(http.Flusher)
+  if !ok {
+    writeStreamError(w, ctxLogger, http.StatusInternalServerError, fmt.Errorf("поток событий не поддерживается"))
+    return
+  }
+
+  sub, err := c.bl.Events.Subscribe(req.Context(), filter, req.Header.Get("Last-Event-ID"))
+  if err != nil {
+    writeStreamError(w, ctxLogger, statusFor(err, http.StatusInternalServerError), err)
+    return
+  }
+  defer sub.Close()
+
+  w.Header().Set("Content-Type", "text/event-stream")
+  w.Header().Set("Cache-Control", "no-cache")
+  w.Header().Set("Connection", "keep-alive")
+  w.Header().Set("X-Accel-Buffering", "no")
+  w.WriteHeader(http.StatusOK)
+  _, _ = fmt.Fprint(w, "retry: 3000\n\n")
+  flusher.Flush()
+
+  ticker := time.NewTicker(heartbeat)
+  defer ticker.Stop()
+  sent := 0
+  defer func() {
+    ctxLogger.Info("reqDone", slog.Any("duration", time.Since(startTime).String()), slog.Int("events", sent))
+  }()
+  for {
+    select {
+    case <-req.Context().Done():
+      return
+    case <-c.closing:
+      // сервер выключается, клиент переподключится к другому экземпляру с Last-Event-ID
+      return
+    case <-ticker.C:
+      _, err = fmt.Fprint(w, ": ping\n\n")
+    case e, ok := <-sub.C:
+      if !ok {
+        // очередь переполнена, клиент переподключится с Last-Event-ID
+        return
+      }
+      data, _ := json.Marshal(e)
+      _, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+      sent++
+    }
+    if err != nil {
+      return
+    }
+    flusher.Flush()
+  }
+}
+
+func writeStreamError(w http.ResponseWriter, log *slog.Logger, status int, err error) {
+  log.Info("reqDone", slog.Group("error", slog.String("msg", err.Error()), slog.Any("status", status)))
+  resBytes, _ := json.Marshal(models.ErrorResponse{Error: err.Error()})
+  w.Header().Add("Content-Type", "application/json")
+  w.WriteHeader(status)
+  _, _ = w.Write(resBytes)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+  "errors"
+  "log/slog"
+  "net/http"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// GraphQL выполняет запрос GraphQL
+// @Summary Запрос GraphQL
+// @Description Люди, их задачи, интервалы работы и итоги за один запрос, схема - internal/io/graphql/schema.graphql.
+// @Description Списки постраничные, как в REST: first до 100, after - nextCursor, total при count: true.
+// @Description Вложенность запроса не больше 10 полей, слишком большой по оценке запрос отклоняется до выполнения.
+// @Description Ошибки отдельных полей возвращаются в errors со статусом 200
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param query body models.GraphQLReq true "Запрос"
+// @Success 200 {object} models.GraphQLResp "Результат запроса"
+// @Failure 400 {object} models.ErrorResponse "Некорректное тело запроса"
+// @Router /graphql [post]
+func (c *Controller) GraphQL(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  var query models.GraphQLReq
+  body, err := utils.DecodeRequestBody(req, &query)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+  if query.Query == "" {
+    return nil, http.StatusBadRequest, slog.Attr{}, errors.New("не задан запрос query")
+  }
+
+  resp := c.gql.Exec(req.Context(), query.Query, query.OperationName, query.Variables)
+  return resp, http.StatusOK, slog.Group("graphql", slog.String("operation", query.OperationName), slog.Int("errors", len(resp.Errors))), nil
+}
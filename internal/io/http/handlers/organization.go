@@ -0,0 +1,50 @@
+package handlers
+
+import (
+  "log/slog"
+  "net/http"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// CreateOrganization создает организацию
+// @Summary Создание организации
+// @Description Создает организацию, данные которой изолированы от остальных. Доступно администраторам без привязки к организации
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param org body models.OrganizationCreate true "Название организации"
+// @Success 200 {object} dto.Organization
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/organizations [post]
+func (c *Controller) CreateOrganization(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  var orgReq models.OrganizationCreate
+  body, err := utils.DecodeRequestBody(req, &orgReq)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  org, err := c.bl.Org.CreateOrganization(req.Context(), orgReq.Name)
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return org, http.StatusOK, slog.String("organizationId", org.ID), nil
+}
+
+// GetOrganizations возвращает список организаций
+// @Summary Список организаций
+// @Description Возвращает все организации. Доступно администраторам без привязки к организации
+// @Tags admin
+// @Produce json
+// @Success 200 {object} []dto.Organization
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/organizations [get]
+func (c *Controller) GetOrganizations(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  orgs, err := c.bl.Org.GetOrganizations(req.Context())
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  return orgs, http.StatusOK, slog.Int("total", len(orgs)), nil
+}
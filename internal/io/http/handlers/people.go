@@ -3,9 +3,12 @@ package handlers
 import (
   "errors"
   "fmt"
+  "io"
   "log/slog"
   "net/http"
   "strconv"
+  "strings"
+  "time"
   "timetracker/internal/bl/dto"
   "timetracker/internal/io/http/models"
   "timetracker/internal/utils"
@@ -21,7 +24,8 @@ import (
 // @Success 200 {object} dto.Person
 // @Failure 400 {object} models.ErrorResponse "Неверный запрос"
 // @Failure 409 {object} models.ErrorResponse "Конфликт данных"
-// @Router /people [post]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people [post]
 func (c *Controller) CreatePeople(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   var passport dto.Passport
   body, err := utils.DecodeRequestBody(req, &passport)
@@ -36,7 +40,7 @@ func (c *Controller) CreatePeople(_ http.ResponseWriter, req *http.Request) (int
 
   people, err := c.bl.People.CreatePeople(req.Context(), passport)
   if err != nil {
-    return nil, http.StatusConflict, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusConflict), slog.Attr{}, err
   }
 
   return people, http.StatusOK, slog.Attr{}, nil
@@ -49,20 +53,27 @@ func (c *Controller) CreatePeople(_ http.ResponseWriter, req *http.Request) (int
 // @Accept json
 // @Produce json
 // @Param uuid path string true "UUID человека для удаления"
+// @Param If-Match header string false "ETag записи, удаление только если она не менялась"
 // @Success 200 {object} models.Ok
 // @Failure 400 {object} models.ErrorResponse "Неверный UUID"
 // @Failure 404 {object} models.ErrorResponse "Человек не найден"
-// @Router /people/{uuid} [delete]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 412 {object} models.ErrorResponse "Запись изменена, ETag не совпадает"
+// @Router /v1/people/{uuid} [delete]
 func (c *Controller) DeletePeople(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   id := req.PathValue("uuid")
   if !utils.IsValidUUID(id) {
     attr := slog.String("not uuid", id)
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
   }
+  version, err := ifMatch(req, c.personVersion(req, id))
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
 
-  err := c.bl.People.DeletePeople(req.Context(), id)
+  err = c.bl.People.DeletePeople(req.Context(), id, version)
   if err != nil {
-    return nil, http.StatusNotFound, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
   }
   return models.Ok{
     Msg:    fmt.Sprintf("id: %s удален", id),
@@ -112,11 +123,15 @@ func (c *Controller) InfoPeople(_ http.ResponseWriter, req *http.Request) (inter
 // @Accept json
 // @Produce json
 // @Param uuid path string true "Уникальный идентификатор человека (UUID)"
+// @Param If-None-Match header string false "ETag из предыдущего ответа"
 // @Success 200 {object} dto.Person "Информация о человеке"
+// @Success 304 "Запись не изменилась"
+// @Header 200 {string} ETag "Версия записи"
 // @Failure 400 {object} models.ErrorResponse "Неверный формат UUID"
 // @Failure 404 {object} models.ErrorResponse "Человек с указанным UUID не найден"
-// @Router /people/{uuid} [get]
-func (c *Controller) GetPeopleByUUID(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/{uuid} [get]
+func (c *Controller) GetPeopleByUUID(w http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   id := req.PathValue("uuid")
   if !utils.IsValidUUID(id) {
     attr := slog.String("not uuid", id)
@@ -125,43 +140,59 @@ func (c *Controller) GetPeopleByUUID(_ http.ResponseWriter, req *http.Request) (
 
   people, err := c.bl.People.GetPeopleUUID(req.Context(), id)
   if err != nil {
-    return nil, http.StatusNotFound, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  tag := etag(people.Version)
+  w.Header().Set("ETag", tag)
+  if notModified(req, tag) {
+    return nil, http.StatusNotModified, slog.Attr{}, nil
   }
   return people, http.StatusOK, slog.Attr{}, nil
 }
 
 // UpdatePeopleByUUID обновляет информацию о человеке по его UUID
 // @Summary Обновление информации о человеке
-// @Description Обновляет информацию о человеке по его UUID
+// @Description Частичное обновление по JSON Merge Patch (RFC 7396): непереданные поля не меняются, null очищает отчество или руководителя. Неизвестные поля и некорректные значения дают 400 со списком ошибок
 // @Tags people
 // @Accept json
+// @Accept application/merge-patch+json
 // @Produce json
 // @Param uuid path string true "UUID человека"
-// @Param body body dto.Person true "Информация о человеке для обновления"
+// @Param body body models.PersonPatch true "Изменяемые поля"
+// @Param If-Match header string false "ETag записи, обновление только если она не менялась"
 // @Success 200 {object} dto.Person "Обновленная информация о человеке"
+// @Header 200 {string} ETag "Новая версия записи"
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
 // @Failure 404 {object} models.ErrorResponse "Человек с указанным UUID не найден"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people/{uuid} [patch]
-func (c *Controller) UpdatePeopleByUUID(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 412 {object} models.ErrorResponse "Запись изменена, ETag не совпадает"
+// @Router /v1/people/{uuid} [patch]
+func (c *Controller) UpdatePeopleByUUID(w http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   id := req.PathValue("uuid")
   if !utils.IsValidUUID(id) {
     attr := slog.String("not uuid", id)
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
   }
 
-  var people dto.Person
-  body, err := utils.DecodeRequestBody(req, &people)
+  body, err := io.ReadAll(req.Body)
   if err != nil {
-    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, slog.Attr{}, err
+  }
+  patch, err := models.DecodePersonPatch(body, id)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", string(body)))
     return nil, http.StatusBadRequest, attr, err
   }
-
-  people.ID = id
-  person, err := c.bl.People.UpdatePeople(req.Context(), people)
+  patch.Version, err = ifMatch(req, c.personVersion(req, id))
   if err != nil {
-    return nil, http.StatusNotFound, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
   }
+  person, err := c.bl.People.UpdatePeople(req.Context(), patch)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  w.Header().Set("ETag", etag(person.Version))
   return person, http.StatusOK, slog.Attr{}, nil
 }
 
@@ -171,56 +202,189 @@ func (c *Controller) UpdatePeopleByUUID(_ http.ResponseWriter, req *http.Request
 // @Tags people
 // @Accept json
 // @Produce json
-// @Param surname query string false "Фамилия человека"
-// @Param name query string false "Имя человека"
-// @Param patronymic query string false "Отчество человека"
-// @Param address query string false "Адрес человека"
-// @Param passport_number query string false "Номер паспорта человека"
-// @Param page query int false "Номер страницы (по умолчанию 1)"
-// @Param limit query int false "Количество записей на странице (по умолчанию 10)"
+// @Param surname query string false "Фамилия человека, * - любые символы без учета регистра, несколько значений через запятую"
+// @Param name query string false "Имя человека, * - любые символы без учета регистра, несколько значений через запятую"
+// @Param patronymic query string false "Отчество человека, * - любые символы без учета регистра, несколько значений через запятую"
+// @Param address query string false "Адрес человека, * - любые символы без учета регистра"
+// @Param passport_number query string false "Номер паспорта человека, несколько значений через запятую"
+// @Param team_id query string false "UUID команды, в которой состоит человек"
+// @Param has_active_task query bool false "Есть задача в работе"
+// @Param created_after query string false "Добавлен после даты (2006-01-02 или RFC3339)"
+// @Param sort query string false "Сортировка: поля surname, name, patronymic, created_at через запятую, - перед полем для убывания"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor предыдущего ответа"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10, не больше 100)"
+// @Param count query bool false "Посчитать общее количество записей"
+// @Param page query int false "Номер страницы для постраничного вывода по смещению (устарело, используйте cursor)"
 // @Success 200 {object} models.PeopleResp "Список людей"
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people [get]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people [get]
 func (c *Controller) GetPeoples(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   queryParams := req.URL.Query()
-  filter := &dto.Person{
-    People: dto.People{
-      Surname:    queryParams.Get("surname"),
-      Name:       queryParams.Get("name"),
-      Patronymic: queryParams.Get("patronymic"),
-      Address:    queryParams.Get("address"),
-    },
-    Passport: dto.Passport{
-      PassportNumber: queryParams.Get("passport_number"),
+  filter := &dto.PeopleFilter{
+    Person: dto.Person{
+      People: dto.People{
+        Surname:    queryParams.Get("surname"),
+        Name:       queryParams.Get("name"),
+        Patronymic: queryParams.Get("patronymic"),
+        Address:    queryParams.Get("address"),
+      },
+      Passport: dto.Passport{
+        PassportNumber: queryParams.Get("passport_number"),
+      },
     },
+    TeamID: queryParams.Get("team_id"),
   }
-  pageStr := queryParams.Get("page")
-  if pageStr == "" {
-    pageStr = "1"
+  if filter.TeamID != "" && !utils.IsValidUUID(filter.TeamID) {
+    attr := slog.String("not uuid", filter.TeamID)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("team_id %s не валидный", filter.TeamID)
   }
-  page, err := strconv.Atoi(pageStr)
-  if err != nil || page < 1 {
-    return nil, http.StatusBadRequest, slog.Attr{}, errors.New("некорректное значение параметра page")
+  if activeStr := queryParams.Get("has_active_task"); activeStr != "" {
+    active, err := strconv.ParseBool(activeStr)
+    if err != nil {
+      return nil, http.StatusBadRequest, slog.Attr{}, errors.New("некорректное значение параметра has_active_task")
+    }
+    filter.HasActiveTask = &active
   }
-  limitStr := queryParams.Get("limit")
-  if limitStr == "" {
-    limitStr = "10"
+  if afterStr := queryParams.Get("created_after"); afterStr != "" {
+    after, err := time.Parse(time.DateOnly, afterStr)
+    if err != nil {
+      after, err = time.Parse(time.RFC3339, afterStr)
+    }
+    if err != nil {
+      return nil, http.StatusBadRequest, slog.Attr{}, errors.New("некорректное значение параметра created_after")
+    }
+    filter.CreatedAfter = &after
   }
-  limit, err := strconv.Atoi(limitStr)
-  if err != nil || limit < 1 {
-    return nil, http.StatusBadRequest, slog.Attr{}, errors.New("некорректное значение параметра limit")
+  sort, err := dto.ParseSort(queryParams.Get("sort"), dto.PeopleSortFields)
+  if err != nil {
+    return nil, http.StatusBadRequest, slog.Attr{}, err
+  }
+  filter.Sort = sort
+  page, err := parsePage(queryParams)
+  if err != nil {
+    return nil, http.StatusBadRequest, slog.Attr{}, err
   }
-  offset := (page - 1) * limit
 
-  people, total, err := c.bl.People.GetPeople(req.Context(), filter, offset, limit)
+  people, info, err := c.bl.People.GetPeople(req.Context(), filter, page)
   if err != nil {
-    return nil, http.StatusInternalServerError, slog.Attr{}, fmt.Errorf("ошибка при получении данных пользователей: %s", err.Error())
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, fmt.Errorf("ошибка при получении данных пользователей: %s", err.Error())
   }
   return models.PeopleResp{
-    Total:  total,
-    Limit:  limit,
-    Offset: offset,
-    People: people,
-  }, http.StatusOK, slog.Int("total", total), nil
+    Total:      info.Total,
+    Limit:      page.Limit,
+    Offset:     page.Offset,
+    NextCursor: info.NextCursor,
+    People:     people,
+  }, http.StatusOK, slog.Int("count", len(people)), nil
+}
+
+// SearchPeople полнотекстовый и нечеткий поиск людей
+// @Summary Поиск людей
+// @Description Поиск по словам фамилии, имени, отчества и адреса с учетом опечаток, результаты упорядочены по релевантности, совпадения выделены тегом <b>
+// @Tags people
+// @Produce json
+// @Param q query string true "Строка поиска, например 'Иванов Петр'"
+// @Param limit query int false "Количество результатов (по умолчанию 10, не больше 100). Постраничного вывода нет: cursor, page и count отклоняются с 400"
+// @Success 200 {object} models.PeopleSearchResp "Найденные люди"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/search [get]
+func (c *Controller) SearchPeople(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  queryParams := req.URL.Query()
+  q := strings.TrimSpace(queryParams.Get("q"))
+  if q == "" {
+    return nil, http.StatusBadRequest, slog.Attr{}, errors.New("не задан параметр q")
+  }
+  // результаты упорядочены по релевантности, стабильного порядка для страниц нет
+  for _, param := range []string{"cursor", "page", "count"} {
+    if queryParams.Has(param) {
+      return nil, http.StatusBadRequest, slog.Attr{}, fmt.Errorf("поиск не поддерживает параметр %s, только limit", param)
+    }
+  }
+  page, err := parsePage(queryParams)
+  if err != nil {
+    return nil, http.StatusBadRequest, slog.Attr{}, err
+  }
+
+  results, err := c.bl.People.SearchPeople(req.Context(), q, page.Limit)
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, fmt.Errorf("ошибка поиска: %s", err.Error())
+  }
+  return models.PeopleSearchResp{
+    Query:   q,
+    Results: results,
+  }, http.StatusOK, slog.Int("count", len(results)), nil
+}
+
+// GetDuplicates ищет вероятные дубликаты людей
+// @Summary Поиск дубликатов
+// @Description Пары людей с одинаковыми фамилией, именем и отчеством и похожим адресом
+// @Tags people
+// @Produce json
+// @Param threshold query number false "Минимальная похожесть адресов от 0 до 1 (по умолчанию 0.5)"
+// @Param limit query int false "Количество пар (по умолчанию 10, не больше 100)"
+// @Success 200 {array} dto.Duplicate "Вероятные дубликаты"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/duplicates [get]
+func (c *Controller) GetDuplicates(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  queryParams := req.URL.Query()
+  threshold := 0.5
+  if thresholdStr := queryParams.Get("threshold"); thresholdStr != "" {
+    var err error
+    threshold, err = strconv.ParseFloat(thresholdStr, 64)
+    if err != nil || threshold < 0 || threshold > 1 {
+      return nil, http.StatusBadRequest, slog.Attr{}, errors.New("некорректное значение параметра threshold")
+    }
+  }
+  page, err := parsePage(queryParams)
+  if err != nil {
+    return nil, http.StatusBadRequest, slog.Attr{}, err
+  }
+
+  duplicates, err := c.bl.People.FindDuplicates(req.Context(), threshold, page.Limit)
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  return duplicates, http.StatusOK, slog.Int("count", len(duplicates)), nil
+}
+
+// MergePeople сливает дубликат в человека с указанным UUID
+// @Summary Слияние дубликатов
+// @Description Переносит задачи и время работы человека source_id к человеку из пути в одной транзакции, source_id помечается удаленным, слияние пишется в журнал аудита
+// @Tags people
+// @Accept json
+// @Produce json
+// @Param uuid path string true "UUID человека, который остается"
+// @Param merge body models.PeopleMerge true "UUID дубликата"
+// @Success 200 {object} dto.MergeResult "Итог слияния"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/{uuid}/merge [post]
+func (c *Controller) MergePeople(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("uuid")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+  var merge models.PeopleMerge
+  body, err := utils.DecodeRequestBody(req, &merge)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+  if !utils.IsValidUUID(merge.SourceID) {
+    attr := slog.String("not uuid", merge.SourceID)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", merge.SourceID)
+  }
+
+  res, err := c.bl.People.MergePeople(req.Context(), merge.SourceID, id)
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return res, http.StatusOK, slog.Int64("movedTasks", res.MovedTasks), nil
 }
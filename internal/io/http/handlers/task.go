@@ -4,6 +4,7 @@ import (
   "fmt"
   "log/slog"
   "net/http"
+  "timetracker/internal/bl/dto"
   "timetracker/internal/io/http/models"
   "timetracker/internal/utils"
 )
@@ -17,43 +18,57 @@ import (
 // @Param uuid path string true "UUID человека"
 // @Param task body models.TaskCreate true "Данные для создания задачи"
 // @Success 200 {object} models.Ok "Созданная задача"
+// @Header 200 {string} ETag "Версия задачи"
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
 // @Failure 404 {object} models.ErrorResponse "Человек с указанным UUID не найден"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people/{uuid}/create-task [post]
-func (c *Controller) CreateTask(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/{uuid}/tasks [post]
+func (c *Controller) CreateTask(w http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   id := req.PathValue("uuid")
   if !utils.IsValidUUID(id) {
     attr := slog.String("not uuid", id)
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
   }
   var task models.TaskCreate
-  utils.DecodeRequestBody(req, &task)
+  body, err := utils.DecodeRequestBody(req, &task)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
   task.IdPerson = id
   createTask, err := c.bl.Task.CreateTask(req.Context(), task.ToDto())
   if err != nil {
-    return nil, http.StatusBadRequest, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  urls, err := c.taskUrls(req, createTask)
+  if err != nil {
+    return nil, http.StatusInternalServerError, slog.Attr{}, err
   }
-  resp := models.TaskFromDto(createTask)
+  resp := models.TaskFromDto(createTask, urls)
+  w.Header().Set("ETag", etag(createTask.Version))
   return resp, http.StatusOK, slog.Attr{}, nil
 }
 
-// CompleteTask завершает задачу для указанного человека по UUID человека и UUID задачи
+// CompleteTask завершает задачу по UUID задачи
 // @Summary Завершение задачи для человека
-// @Description Завершает задачу для человека по его UUID и UUID задачи
+// @Description Завершает задачу по ее UUID
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param uuidP path string true "UUID человека"
 // @Param uuidT path string true "UUID задачи"
+// @Param If-Match header string false "ETag задачи, действие только если она не менялась"
 // @Success 200 {object} models.Ok "Успешное завершение задачи"
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
-// @Failure 404 {object} models.ErrorResponse "Человек или задача с указанным UUID не найдены"
+// @Failure 404 {object} models.ErrorResponse "Задача с указанным UUID не найдена"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people/{uuidP}/{uuidT}/complete [get]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 412 {object} models.ErrorResponse "Задача изменена, ETag не совпадает"
+// @Router /v1/tasks/{uuidT}/complete [post]
 func (c *Controller) CompleteTask(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  // в /v1 задача адресуется без человека
   idPerson := req.PathValue("uuidP")
-  if !utils.IsValidUUID(idPerson) {
+  if idPerson != "" && !utils.IsValidUUID(idPerson) {
     attr := slog.String("not uuid", idPerson)
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", idPerson)
   }
@@ -64,9 +79,14 @@ func (c *Controller) CompleteTask(_ http.ResponseWriter, req *http.Request) (int
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", idTask)
   }
 
-  err := c.bl.Task.CompleteTask(req.Context(), idPerson, idTask)
+  version, err := ifMatch(req, c.taskVersion(req, idPerson, idTask))
   if err != nil {
-    return nil, http.StatusBadRequest, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+
+  err = c.bl.Task.CompleteTask(req.Context(), idPerson, idTask, version)
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
   }
 
   return models.Ok{
@@ -74,3 +94,68 @@ func (c *Controller) CompleteTask(_ http.ResponseWriter, req *http.Request) (int
     Status: http.StatusOK,
   }, http.StatusOK, slog.Attr{}, nil
 }
+
+// GetTasks возвращает задачи человека по UUID в порядке создания
+// @Summary Получение списка задач человека
+// @Description Получение задач человека в порядке создания с постраничным выводом по курсору
+// @Tags tasks
+// @Produce json
+// @Param uuid path string true "UUID человека"
+// @Param cursor query string false "Курсор следующей страницы из next_cursor предыдущего ответа"
+// @Param limit query int false "Количество записей на странице (по умолчанию 10, не больше 100)"
+// @Param count query bool false "Посчитать общее количество задач"
+// @Success 200 {object} models.TasksResp "Список задач"
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/{uuid}/tasks [get]
+func (c *Controller) GetTasks(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("uuid")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+  page, err := parsePage(req.URL.Query())
+  if err != nil {
+    return nil, http.StatusBadRequest, slog.Attr{}, err
+  }
+
+  tasks, info, err := c.bl.Task.GetTasks(req.Context(), id, page)
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  resp := models.TasksResp{
+    Total:      info.Total,
+    Limit:      page.Limit,
+    NextCursor: info.NextCursor,
+    Tasks:      make([]models.TaskResp, 0, len(tasks)),
+  }
+  for i := range tasks {
+    urls, err := c.taskUrls(req, &tasks[i])
+    if err != nil {
+      return nil, http.StatusInternalServerError, slog.Attr{}, err
+    }
+    resp.Tasks = append(resp.Tasks, *models.TaskFromDto(&tasks[i], urls))
+  }
+  return resp, http.StatusOK, slog.Int("count", len(tasks)), nil
+}
+
+// taskUrls ссылки действий с задачей на маршруты того же варианта API, что и запрос
+func (c *Controller) taskUrls(req *http.Request, task *dto.Task) (models.UrlTask, error) {
+  prefix, params := "task.", []string{"uuidT", task.IdTask}
+  if isLegacy(req) {
+    prefix, params = "legacy.task.", append(params, "uuidP", task.IdPerson)
+  }
+  var urls models.UrlTask
+  for _, link := range []struct {
+    action string
+    url    *string
+  }{{"start", &urls.Start}, {"pause", &urls.Pause}, {"complete", &urls.Complete}} {
+    url, err := c.links.URL(req, prefix+link.action, params...)
+    if err != nil {
+      return models.UrlTask{}, err
+    }
+    *link.url = url
+  }
+  return urls, nil
+}
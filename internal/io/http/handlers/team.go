@@ -0,0 +1,245 @@
+package handlers
+
+import (
+  "fmt"
+  "log/slog"
+  "net/http"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// CreateTeam создает команду
+// @Summary Создание команды
+// @Description Создает команду с руководителем
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param team body models.TeamCreate true "Название и руководитель команды"
+// @Success 200 {object} dto.Team
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/teams [post]
+func (c *Controller) CreateTeam(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  var teamReq models.TeamCreate
+  body, err := utils.DecodeRequestBody(req, &teamReq)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+  if teamReq.ManagerID != "" && !utils.IsValidUUID(teamReq.ManagerID) {
+    attr := slog.String("not uuid", teamReq.ManagerID)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", teamReq.ManagerID)
+  }
+
+  team, err := c.bl.Team.CreateTeam(req.Context(), dto.Team{Name: teamReq.Name, ManagerID: teamReq.ManagerID})
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return team, http.StatusOK, slog.String("teamId", team.ID), nil
+}
+
+// GetTeams возвращает список команд
+// @Summary Список команд
+// @Description Возвращает команды организации, руководителю только его команды
+// @Tags teams
+// @Produce json
+// @Success 200 {object} []dto.Team
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/teams [get]
+func (c *Controller) GetTeams(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  teams, err := c.bl.Team.GetTeams(req.Context())
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  return teams, http.StatusOK, slog.Int("total", len(teams)), nil
+}
+
+// GetTeam возвращает команду с участниками
+// @Summary Получение команды
+// @Description Возвращает команду и UUID ее участников
+// @Tags teams
+// @Produce json
+// @Param uuid path string true "UUID команды"
+// @Success 200 {object} dto.Team
+// @Failure 400 {object} models.ErrorResponse "Неверный формат UUID"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Команда не найдена"
+// @Router /v1/teams/{uuid} [get]
+func (c *Controller) GetTeam(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("uuid")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+
+  team, err := c.bl.Team.GetTeam(req.Context(), id)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return team, http.StatusOK, slog.Attr{}, nil
+}
+
+// UpdateTeam изменяет название или руководителя команды
+// @Summary Обновление команды
+// @Description Изменяет название и руководителя команды, пустые поля не меняются
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param uuid path string true "UUID команды"
+// @Param team body models.TeamCreate true "Новые значения"
+// @Success 200 {object} dto.Team
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Команда не найдена"
+// @Router /v1/teams/{uuid} [patch]
+func (c *Controller) UpdateTeam(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("uuid")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+  var teamReq models.TeamCreate
+  body, err := utils.DecodeRequestBody(req, &teamReq)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+  if teamReq.ManagerID != "" && !utils.IsValidUUID(teamReq.ManagerID) {
+    attr := slog.String("not uuid", teamReq.ManagerID)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", teamReq.ManagerID)
+  }
+
+  team, err := c.bl.Team.UpdateTeam(req.Context(), dto.Team{ID: id, Name: teamReq.Name, ManagerID: teamReq.ManagerID})
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return team, http.StatusOK, slog.Attr{}, nil
+}
+
+// DeleteTeam удаляет команду
+// @Summary Удаление команды
+// @Description Удаляет команду, люди из нее не удаляются
+// @Tags teams
+// @Produce json
+// @Param uuid path string true "UUID команды"
+// @Success 200 {object} models.Ok
+// @Failure 400 {object} models.ErrorResponse "Неверный формат UUID"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Команда не найдена"
+// @Router /v1/teams/{uuid} [delete]
+func (c *Controller) DeleteTeam(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("uuid")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+
+  err := c.bl.Team.DeleteTeam(req.Context(), id)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return models.Ok{
+    Msg:    fmt.Sprintf("команда %s удалена", id),
+    Status: http.StatusOK,
+  }, http.StatusOK, slog.Attr{}, nil
+}
+
+// AddTeamMember добавляет человека в команду
+// @Summary Добавление участника команды
+// @Description Добавляет человека в команду, повторное добавление не является ошибкой
+// @Tags teams
+// @Produce json
+// @Param uuid path string true "UUID команды"
+// @Param uuidP path string true "UUID человека"
+// @Success 200 {object} models.Ok
+// @Failure 400 {object} models.ErrorResponse "Неверный формат UUID"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Команда или человек не найдены"
+// @Router /v1/teams/{uuid}/members/{uuidP} [put]
+func (c *Controller) AddTeamMember(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  idTeam, idPerson, attr, err := teamMemberPath(req)
+  if err != nil {
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  err = c.bl.Team.AddMember(req.Context(), idTeam, idPerson)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return models.Ok{
+    Msg:    "участник добавлен",
+    Status: http.StatusOK,
+  }, http.StatusOK, slog.Attr{}, nil
+}
+
+// RemoveTeamMember исключает человека из команды
+// @Summary Исключение участника команды
+// @Description Исключает человека из команды
+// @Tags teams
+// @Produce json
+// @Param uuid path string true "UUID команды"
+// @Param uuidP path string true "UUID человека"
+// @Success 200 {object} models.Ok
+// @Failure 400 {object} models.ErrorResponse "Неверный формат UUID"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Человек не состоит в команде"
+// @Router /v1/teams/{uuid}/members/{uuidP} [delete]
+func (c *Controller) RemoveTeamMember(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  idTeam, idPerson, attr, err := teamMemberPath(req)
+  if err != nil {
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  err = c.bl.Team.RemoveMember(req.Context(), idTeam, idPerson)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return models.Ok{
+    Msg:    "участник исключен",
+    Status: http.StatusOK,
+  }, http.StatusOK, slog.Attr{}, nil
+}
+
+// TeamWorkTime возвращает трудозатраты участников команды за период
+// @Summary Трудозатраты команды
+// @Description Возвращает время работы каждого участника команды по задачам в указанном диапазоне
+// @Tags teams
+// @Accept json
+// @Produce json
+// @Param uuid path string true "UUID команды"
+// @Param body body models.DateStartEnd true "Временной диапазон"
+// @Success 200 {object} []dto.TeamTimeResult
+// @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Команда не найдена"
+// @Router /v1/teams/{uuid}/worktime [post]
+func (c *Controller) TeamWorkTime(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("uuid")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+  tm, attr, err := decodeTimeRange(req)
+  if err != nil {
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  times, err := c.bl.Team.TeamTimes(req.Context(), id, tm.Start, tm.End)
+  if err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return times, http.StatusOK, slog.Attr{}, nil
+}
+
+func teamMemberPath(req *http.Request) (string, string, slog.Attr, error) {
+  idTeam := req.PathValue("uuid")
+  if !utils.IsValidUUID(idTeam) {
+    return "", "", slog.String("not uuid", idTeam), fmt.Errorf("uuid %s не валидный", idTeam)
+  }
+  idPerson := req.PathValue("uuidP")
+  if !utils.IsValidUUID(idPerson) {
+    return "", "", slog.String("not uuidP", idPerson), fmt.Errorf("uuidP %s не валидный", idPerson)
+  }
+  return idTeam, idPerson, slog.Attr{}, nil
+}
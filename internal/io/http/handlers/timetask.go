@@ -8,22 +8,25 @@ import (
   "timetracker/internal/utils"
 )
 
-// StartTimer начинает таймер для задачи указанного человека по UUID человека и UUID задачи
+// StartTimer начинает таймер для задачи по UUID задачи
 // @Summary Начало таймера для задачи
-// @Description Начинает таймер для задачи указанного человека по его UUID и UUID задачи
+// @Description Начинает таймер для задачи по ее UUID
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param uuidP path string true "UUID человека"
 // @Param uuidT path string true "UUID задачи"
+// @Param If-Match header string false "ETag задачи, действие только если она не менялась"
 // @Success 200 {object} models.Ok "Успешное начало таймера для задачи"
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
-// @Failure 404 {object} models.ErrorResponse "Человек или задача с указанным UUID не найдены"
+// @Failure 404 {object} models.ErrorResponse "Задача с указанным UUID не найдена"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people/{uuidP}/{uuidT}/start [get]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 412 {object} models.ErrorResponse "Задача изменена, ETag не совпадает"
+// @Router /v1/tasks/{uuidT}/start [post]
 func (c *Controller) StartTimer(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  // в /v1 задача адресуется без человека
   idPerson := req.PathValue("uuidP")
-  if !utils.IsValidUUID(idPerson) {
+  if idPerson != "" && !utils.IsValidUUID(idPerson) {
     attr := slog.String("not uuidP", idPerson)
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuidP %s не валидный", idPerson)
   }
@@ -34,9 +37,14 @@ func (c *Controller) StartTimer(_ http.ResponseWriter, req *http.Request) (inter
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuidT %s не валидный", idTask)
   }
 
-  err := c.bl.Task.StartTask(req.Context(), idPerson, idTask)
+  version, err := ifMatch(req, c.taskVersion(req, idPerson, idTask))
   if err != nil {
-    return nil, http.StatusBadRequest, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+
+  err = c.bl.Task.StartTask(req.Context(), idPerson, idTask, version)
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
   }
   return models.Ok{
     Msg:    "задача в работе",
@@ -44,22 +52,25 @@ func (c *Controller) StartTimer(_ http.ResponseWriter, req *http.Request) (inter
   }, http.StatusOK, slog.Attr{}, nil
 }
 
-// PauseTimer приостанавливает таймер для задачи указанного человека по UUID человека и UUID задачи
+// PauseTimer приостанавливает таймер для задачи по UUID задачи
 // @Summary Приостановка таймера для задачи
-// @Description Приостанавливает таймер для задачи указанного человека по его UUID и UUID задачи
+// @Description Приостанавливает таймер для задачи по ее UUID
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Param uuidP path string true "UUID человека"
 // @Param uuidT path string true "UUID задачи"
+// @Param If-Match header string false "ETag задачи, действие только если она не менялась"
 // @Success 200 {object} models.Ok "Успешная приостановка таймера для задачи"
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
-// @Failure 404 {object} models.ErrorResponse "Человек или задача с указанным UUID не найдены"
+// @Failure 404 {object} models.ErrorResponse "Задача с указанным UUID не найдена"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people/{uuidP}/{uuidT}/pause [get]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 412 {object} models.ErrorResponse "Задача изменена, ETag не совпадает"
+// @Router /v1/tasks/{uuidT}/pause [post]
 func (c *Controller) PauseTimer(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  // в /v1 задача адресуется без человека
   idPerson := req.PathValue("uuidP")
-  if !utils.IsValidUUID(idPerson) {
+  if idPerson != "" && !utils.IsValidUUID(idPerson) {
     attr := slog.String("not uuid", idPerson)
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", idPerson)
   }
@@ -70,9 +81,14 @@ func (c *Controller) PauseTimer(_ http.ResponseWriter, req *http.Request) (inter
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", idTask)
   }
 
-  err := c.bl.Task.PauseTask(req.Context(), idPerson, idTask)
+  version, err := ifMatch(req, c.taskVersion(req, idPerson, idTask))
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+
+  err = c.bl.Task.PauseTask(req.Context(), idPerson, idTask, version)
   if err != nil {
-    return nil, http.StatusBadRequest, slog.Attr{}, err
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
   }
   return models.Ok{
     Msg:    "задача на паузе",
@@ -80,6 +96,38 @@ func (c *Controller) PauseTimer(_ http.ResponseWriter, req *http.Request) (inter
   }, http.StatusOK, slog.Attr{}, nil
 }
 
+// AddEntry вносит вручную интервал работы над задачей
+// @Summary Ручной учет времени
+// @Description Добавляет завершенный интервал работы над задачей, интервал не должен пересекаться с уже учтенным временем
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param uuidT path string true "UUID задачи"
+// @Param entry body models.TimeEntry true "Начало и конец интервала"
+// @Success 200 {object} dto.TimeTask "Добавленный интервал"
+// @Failure 400 {object} models.ErrorResponse "Некорректный интервал"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/tasks/{uuidT}/entries [post]
+func (c *Controller) AddEntry(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  idTask := req.PathValue("uuidT")
+  if !utils.IsValidUUID(idTask) {
+    attr := slog.String("not uuidT", idTask)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuidT %s не валидный", idTask)
+  }
+  var entry models.TimeEntry
+  body, err := utils.DecodeRequestBody(req, &entry)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  created, err := c.bl.Task.AddEntry(req.Context(), idTask, entry.Start, entry.End)
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return created, http.StatusOK, slog.Attr{}, nil
+}
+
 // WorkTime возвращает список задач с временем работы в указанном диапазоне для указанного человека по UUID
 // @Summary Получение списка задач с временем работы
 // @Description Возвращает список задач с временем работы в указанном диапазоне для указанного человека по его UUID
@@ -92,7 +140,8 @@ func (c *Controller) PauseTimer(_ http.ResponseWriter, req *http.Request) (inter
 // @Failure 400 {object} models.ErrorResponse "Некорректные параметры запроса"
 // @Failure 404 {object} models.ErrorResponse "Человек с указанным UUID не найден или нет задач в указанном диапазоне"
 // @Failure 500 {object} models.ErrorResponse "Внутренняя ошибка сервера"
-// @Router /people/{uuid}/worktime [post]
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/people/{uuid}/worktime [post]
 func (c *Controller) WorkTime(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
   id := req.PathValue("uuid")
   if !utils.IsValidUUID(id) {
@@ -100,11 +149,24 @@ func (c *Controller) WorkTime(_ http.ResponseWriter, req *http.Request) (interfa
     return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
   }
 
+  tm, attr, err := decodeTimeRange(req)
+  if err != nil {
+    return nil, http.StatusBadRequest, attr, err
+  }
+  tasks, err := c.bl.Task.TimeTasks(req.Context(), id, tm.Start, tm.End)
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return tasks, http.StatusOK, slog.Attr{}, nil
+}
+
+// decodeTimeRange читает и проверяет временной диапазон отчета из тела запроса
+func decodeTimeRange(req *http.Request) (models.DateStartEnd, slog.Attr, error) {
   var tm models.DateStartEnd
   body, err := utils.DecodeRequestBody(req, &tm)
   if err != nil {
     attr := slog.Group("body", slog.String("reqBody", body))
-    return nil, http.StatusBadRequest, attr, err
+    return tm, attr, err
   }
 
   if !utils.IsValidDateTime(tm.Start) ||
@@ -112,17 +174,12 @@ func (c *Controller) WorkTime(_ http.ResponseWriter, req *http.Request) (interfa
       len(tm.Start) == 0 ||
       len(tm.End) == 0 {
     attr := slog.Group("body", slog.String("start", tm.Start), slog.String("end", tm.End))
-    return nil, http.StatusBadRequest, attr, fmt.Errorf("проблемма входных данных")
+    return tm, attr, fmt.Errorf("проблемма входных данных")
   }
 
   if !utils.IsValidTimeRange(tm.Start, tm.End) {
     attr := slog.Group("body", slog.String("start", tm.Start), slog.String("end", tm.End))
-    return nil, http.StatusBadRequest, attr, fmt.Errorf("дата конца диапозона раньше чем начало")
-
+    return tm, attr, fmt.Errorf("дата конца диапозона раньше чем начало")
   }
-  tasks, err := c.bl.Task.TimeTasks(req.Context(), id, tm.Start, tm.End)
-  if err != nil {
-    return nil, http.StatusBadRequest, slog.Attr{}, err
-  }
-  return tasks, http.StatusOK, slog.Attr{}, nil
+  return tm, slog.Attr{}, nil
 }
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+  "fmt"
+  "log/slog"
+  "net/http"
+  "strconv"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
+)
+
+// CreateWebhook регистрирует подписку на события
+// @Summary Создание вебхука
+// @Description Регистрирует адрес, на который отправляются события выбранных типов: person.created, person.updated, person.deleted,
+// @Description task.created, task.started, task.paused, task.completed, timer.started, timer.stopped, entry.added.
+// @Description Запрос подписывается заголовком X-Webhook-Signature: sha256=hex(HMAC-SHA256(secret, "<X-Webhook-Timestamp>.<тело>")).
+// @Description Без secret он генерируется, секрет возвращается только в этом ответе. Доступ определяется действием webhook.manage политики
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param webhook body models.WebhookCreate true "Адрес, секрет и типы событий"
+// @Success 200 {object} dto.Webhook
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/webhooks [post]
+func (c *Controller) CreateWebhook(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  var hookReq models.WebhookCreate
+  body, err := utils.DecodeRequestBody(req, &hookReq)
+  if err != nil {
+    attr := slog.Group("body", slog.String("reqBody", body))
+    return nil, http.StatusBadRequest, attr, err
+  }
+
+  hook, err := c.bl.Webhook.CreateWebhook(req.Context(), dto.Webhook{
+    URL:        hookReq.URL,
+    Secret:     hookReq.Secret,
+    EventTypes: hookReq.EventTypes,
+  })
+  if err != nil {
+    return nil, statusFor(err, http.StatusBadRequest), slog.Attr{}, err
+  }
+  return hook, http.StatusOK, slog.String("webhookId", hook.ID), nil
+}
+
+// GetWebhooks возвращает подписки организации
+// @Summary Список вебхуков
+// @Description Возвращает подписки организации без секретов. Доступ определяется действием webhook.manage политики
+// @Tags admin
+// @Produce json
+// @Success 200 {object} []dto.Webhook
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/webhooks [get]
+func (c *Controller) GetWebhooks(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  hooks, err := c.bl.Webhook.GetWebhooks(req.Context())
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  return hooks, http.StatusOK, slog.Int("total", len(hooks)), nil
+}
+
+// DeleteWebhook удаляет подписку
+// @Summary Удаление вебхука
+// @Description Удаляет подписку вместе с журналом доставок. Доступ определяется действием webhook.manage политики
+// @Tags admin
+// @Produce json
+// @Param id path string true "Идентификатор вебхука"
+// @Success 200 {object} models.Ok
+// @Failure 400 {object} models.ErrorResponse "Неверный идентификатор"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Failure 404 {object} models.ErrorResponse "Вебхук не найден"
+// @Router /v1/admin/webhooks/{id} [delete]
+func (c *Controller) DeleteWebhook(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("id")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+
+  if err := c.bl.Webhook.DeleteWebhook(req.Context(), id); err != nil {
+    return nil, statusFor(err, http.StatusNotFound), slog.Attr{}, err
+  }
+  return models.Ok{
+    Msg:    fmt.Sprintf("вебхук %s удален", id),
+    Status: http.StatusOK,
+  }, http.StatusOK, slog.Attr{}, nil
+}
+
+// GetWebhookDeliveries возвращает журнал доставок подписки
+// @Summary Журнал доставок вебхука
+// @Description Возвращает последние доставки событий подписке: статус pending, delivered или failed, число попыток,
+// @Description код последнего ответа и ошибку. Неудачные попытки повторяются с растущей паузой, после 10 попыток доставка failed.
+// @Description Журнал хранится 30 дней. Доступ определяется действием webhook.manage политики
+// @Tags admin
+// @Produce json
+// @Param id path string true "Идентификатор вебхука"
+// @Param status query string false "Только доставки в статусе pending, delivered или failed"
+// @Param limit query int false "Количество записей, по умолчанию и не больше 200"
+// @Success 200 {object} []dto.WebhookDelivery
+// @Failure 400 {object} models.ErrorResponse "Неверный запрос"
+// @Failure 403 {object} models.ErrorResponse "Недостаточно прав"
+// @Router /v1/admin/webhooks/{id}/deliveries [get]
+func (c *Controller) GetWebhookDeliveries(_ http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+  id := req.PathValue("id")
+  if !utils.IsValidUUID(id) {
+    attr := slog.String("not uuid", id)
+    return nil, http.StatusBadRequest, attr, fmt.Errorf("uuid %s не валидный", id)
+  }
+  query := req.URL.Query()
+  limit := 0
+  if value := query.Get("limit"); value != "" {
+    var err error
+    if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+      return nil, http.StatusBadRequest, slog.Attr{}, fmt.Errorf("limit должен быть положительным числом")
+    }
+  }
+
+  deliveries, err := c.bl.Webhook.GetDeliveries(req.Context(), id, query.Get("status"), limit)
+  if err != nil {
+    return nil, statusFor(err, http.StatusInternalServerError), slog.Attr{}, err
+  }
+  return deliveries, http.StatusOK, slog.Int("total", len(deliveries)), nil
+}
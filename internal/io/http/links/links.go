@@ -0,0 +1,165 @@
+package links
+
+import (
+  "fmt"
+  "net"
+  "net/http"
+  "net/url"
+  "regexp"
+  "strings"
+)
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Links строит внешние ссылки на именованные маршруты. Шаблоны берутся из тех же
+// вызовов, что регистрируют маршруты в роутере, поэтому ссылки не расходятся с ними
+type Links struct {
+  base    *url.URL
+  trusted []*net.IPNet
+  routes  map[string]string
+}
+
+// New base - внешний адрес сервиса (https://tt.example.com), если пуст, адрес
+// берется из запроса. trusted - адреса и подсети прокси через запятую, только их
+// заголовкам X-Forwarded-Proto и X-Forwarded-Host доверяем
+func New(base, trusted string) (*Links, error) {
+  l := &Links{routes: map[string]string{}}
+  if base != "" {
+    u, err := url.Parse(base)
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+      return nil, fmt.Errorf("некорректный внешний адрес %q", base)
+    }
+    u.Path = strings.TrimSuffix(u.Path, "/")
+    l.base = u
+  }
+  for _, part := range strings.Split(trusted, ",") {
+    part = strings.TrimSpace(part)
+    if part == "" {
+      continue
+    }
+    if !strings.Contains(part, "/") {
+      ip := net.ParseIP(part)
+      if ip == nil {
+        return nil, fmt.Errorf("некорректный адрес прокси %q", part)
+      }
+      bits := 8 * net.IPv6len
+      if ip.To4() != nil {
+        ip, bits = ip.To4(), 8*net.IPv4len
+      }
+      l.trusted = append(l.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+      continue
+    }
+    _, network, err := net.ParseCIDR(part)
+    if err != nil {
+      return nil, fmt.Errorf("некорректная подсеть прокси %q", part)
+    }
+    l.trusted = append(l.trusted, network)
+  }
+  return l, nil
+}
+
+// Route запоминает шаблон маршрута под именем и возвращает его для регистрации в роутере
+func (l *Links) Route(name, pattern string) string {
+  if _, ok := l.routes[name]; ok {
+    panic("маршрут " + name + " уже зарегистрирован")
+  }
+  path := pattern
+  if i := strings.IndexByte(pattern, ' '); i >= 0 {
+    path = pattern[i+1:]
+  }
+  l.routes[name] = path
+  return pattern
+}
+
+// URL полная ссылка на маршрут name, params - пары имя параметра пути, значение
+func (l *Links) URL(req *http.Request, name string, params ...string) (string, error) {
+  path, err := l.path(name, params...)
+  if err != nil {
+    return "", err
+  }
+  return l.Base(req) + path, nil
+}
+
+// Local полная ссылка на маршрут name для запросов, которые сервис отправляет сам себе:
+// от внешнего адреса, а если он не задан, от address, который слушает сервер
+func (l *Links) Local(address, name string, params ...string) (string, error) {
+  path, err := l.path(name, params...)
+  if err != nil {
+    return "", err
+  }
+  if l.base != nil {
+    return l.base.String() + path, nil
+  }
+  return "http://" + address + path, nil
+}
+
+// path путь маршрута name с подставленными параметрами
+func (l *Links) path(name string, params ...string) (string, error) {
+  path, ok := l.routes[name]
+  if !ok {
+    return "", fmt.Errorf("маршрут %s не зарегистрирован", name)
+  }
+  values := map[string]string{}
+  for i := 0; i+1 < len(params); i += 2 {
+    values[params[i]] = params[i+1]
+  }
+  var missing []string
+  path = pathParam.ReplaceAllStringFunc(path, func(param string) string {
+    key := param[1 : len(param)-1]
+    value, ok := values[key]
+    if !ok {
+      missing = append(missing, key)
+    }
+    return url.PathEscape(value)
+  })
+  if len(missing) > 0 {
+    return "", fmt.Errorf("для маршрута %s не заданы параметры %s", name, strings.Join(missing, ", "))
+  }
+  return path, nil
+}
+
+// Base внешний адрес сервиса для запроса без завершающего /
+func (l *Links) Base(req *http.Request) string {
+  if l.base != nil {
+    return l.base.String()
+  }
+  scheme, host := "http", req.Host
+  if req.TLS != nil {
+    scheme = "https"
+  }
+  if l.fromProxy(req) {
+    if proto := first(req.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+      scheme = proto
+    }
+    if fwdHost := first(req.Header.Get("X-Forwarded-Host")); fwdHost != "" {
+      host = fwdHost
+    }
+  }
+  return scheme + "://" + host
+}
+
+// fromProxy true, если запрос пришел напрямую от доверенного прокси
+func (l *Links) fromProxy(req *http.Request) bool {
+  host, _, err := net.SplitHostPort(req.RemoteAddr)
+  if err != nil {
+    host = req.RemoteAddr
+  }
+  ip := net.ParseIP(host)
+  if ip == nil {
+    return false
+  }
+  for _, network := range l.trusted {
+    if network.Contains(ip) {
+      return true
+    }
+  }
+  return false
+}
+
+// first первое значение из списка через запятую, который дописывают цепочки прокси
+func first(value string) string {
+  if i := strings.IndexByte(value, ','); i >= 0 {
+    value = value[:i]
+  }
+  return strings.TrimSpace(value)
+}
@@ -2,17 +2,26 @@ package middlewares
 
 import (
   "context"
+  "encoding/json"
+  "errors"
   "log/slog"
   "net/http"
+  "slices"
+  "strings"
   "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/repo"
+  "timetracker/internal/io/http/models"
+  "timetracker/internal/utils"
 )
 
 type Mw struct {
-  l *slog.Logger
+  l    *slog.Logger
+  auth repo.IAuthBL
 }
 
-func New(log *slog.Logger) *Mw {
-  return &Mw{l: log}
+func New(log *slog.Logger, auth repo.IAuthBL) *Mw {
+  return &Mw{l: log, auth: auth}
 }
 
 func (m *Mw) WithLogger(next http.Handler) http.Handler {
@@ -31,3 +40,70 @@ func (m *Mw) WithLogger(next http.Handler) http.Handler {
     next.ServeHTTP(w, r.WithContext(ctx))
   })
 }
+
+// WithAuth пропускает запрос дальше только с валидным API-ключом или JWT,
+// вызывающий и его организация кладутся в контекст запроса и в логгер
+func (m *Mw) WithAuth(public ...string) func(next http.Handler) http.Handler {
+  return func(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+      if slices.Contains(public, r.URL.Path) {
+        next.ServeHTTP(w, r)
+        return
+      }
+      ctxLogger := r.Context().Value("logger").(*slog.Logger)
+
+      principal, err := m.authenticate(r)
+      if err != nil {
+        status := http.StatusInternalServerError
+        if errors.Is(err, repo.ErrUnauthorized) {
+          status = http.StatusUnauthorized
+          w.Header().Set("WWW-Authenticate", `Bearer realm="timetracker"`)
+        }
+        ctxLogger.Info("reqDone", slog.Group("error", slog.String("msg", err.Error()), slog.Any("status", status)))
+        writeError(w, status, err)
+        return
+      }
+
+      org, err := m.auth.ResolveTenant(r.Context(), principal, r.Header.Get("X-Organization-ID"))
+      if err != nil {
+        status := http.StatusBadRequest
+        if errors.Is(err, repo.ErrForbidden) {
+          status = http.StatusForbidden
+        }
+        ctxLogger.Info("reqDone", slog.Group("error", slog.String("msg", err.Error()), slog.Any("status", status)))
+        writeError(w, status, err)
+        return
+      }
+
+      log := ctxLogger.With(slog.Group("principal",
+        slog.String("id", principal.ID),
+        slog.String("method", principal.Method),
+        slog.String("organization", org)))
+      ctx := utils.WithPrincipal(r.Context(), principal)
+      ctx = utils.WithTenant(ctx, org)
+      ctx = context.WithValue(ctx, "logger", log)
+      next.ServeHTTP(w, r.WithContext(ctx))
+    })
+  }
+}
+
+func (m *Mw) authenticate(r *http.Request) (*dto.Principal, error) {
+  if key := r.Header.Get("X-API-Key"); key != "" {
+    return m.auth.AuthenticateKey(r.Context(), key)
+  }
+  scheme, credential, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+  if !ok || !strings.EqualFold(scheme, "Bearer") || credential == "" {
+    return nil, repo.ErrUnauthorized
+  }
+  if repo.IsApiKey(credential) {
+    return m.auth.AuthenticateKey(r.Context(), credential)
+  }
+  return m.auth.AuthenticateToken(r.Context(), credential)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+  resBytes, _ := json.Marshal(models.ErrorResponse{Error: err.Error()})
+  w.Header().Add("Content-Type", "application/json")
+  w.WriteHeader(status)
+  _, _ = w.Write(resBytes)
+}
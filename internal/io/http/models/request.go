@@ -1,6 +1,14 @@
 package models
 
-import "timetracker/internal/bl/dto"
+import (
+  "encoding/json"
+  "errors"
+  "fmt"
+  "sort"
+  "strings"
+  "time"
+  "timetracker/internal/bl/dto"
+)
 
 type TaskCreate struct {
   IdPerson string
@@ -21,3 +29,106 @@ type DateStartEnd struct {
   Start string `json:"start"`
   End   string `json:"end"`
 }
+
+type ApiKeyCreate struct {
+  Name     string `json:"name"`
+  PersonID string `json:"person_id"`
+  Role     string `json:"role"`
+}
+
+type OrganizationCreate struct {
+  Name string `json:"name"`
+}
+
+type TeamCreate struct {
+  Name      string `json:"name"`
+  ManagerID string `json:"manager_id"`
+}
+
+type TimeEntry struct {
+  Start time.Time `json:"start" example:"2024-05-01T09:00:00Z"`
+  End   time.Time `json:"end" example:"2024-05-01T12:30:00Z"`
+}
+
+type WebhookCreate struct {
+  URL        string   `json:"url" example:"https://payroll.example.com/hooks/timetracker"`
+  Secret     string   `json:"secret,omitempty"`
+  EventTypes []string `json:"event_types" example:"task.completed,timer.started"`
+}
+
+// BatchReq пакет операций, при atomic все или ничего
+type BatchReq struct {
+  Atomic     bool          `json:"atomic"`
+  Operations []dto.BatchOp `json:"operations"`
+}
+
+type PeopleMerge struct {
+  SourceID string `json:"source_id"`
+}
+
+// PersonPatch тело PATCH /people/{uuid} в формате JSON Merge Patch (RFC 7396):
+// непереданное поле не меняется, null очищает необязательное поле
+type PersonPatch struct {
+  Surname        *string `json:"surname"`
+  Name           *string `json:"name"`
+  Patronymic     *string `json:"patronymic"`
+  Address        *string `json:"address"`
+  PassportNumber *string `json:"passportNumber"`
+  ManagerID      *string `json:"manager_id"`
+}
+
+// DecodePersonPatch разбирает merge patch человека id. Поля ID и version из ответа GET
+// допускаются и не применяются, остальные неизвестные поля перечисляются в ошибке
+func DecodePersonPatch(body []byte, id string) (dto.PersonPatch, error) {
+  patch := dto.PersonPatch{ID: id}
+  var raw map[string]json.RawMessage
+  if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+    return patch, errors.New("тело запроса должно быть JSON объектом")
+  }
+  fields := map[string]*dto.PatchString{
+    "surname":        &patch.Surname,
+    "name":           &patch.Name,
+    "patronymic":     &patch.Patronymic,
+    "address":        &patch.Address,
+    "passportNumber": &patch.PassportNumber,
+    "manager_id":     &patch.ManagerID,
+  }
+
+  var unknown []string
+  for key, value := range raw {
+    field, ok := fields[key]
+    if !ok {
+      if key == "version" {
+        continue
+      }
+      if key == "ID" {
+        if string(value) != fmt.Sprintf("%q", id) {
+          return patch, errors.New("поле ID не совпадает с uuid из пути")
+        }
+        continue
+      }
+      unknown = append(unknown, key)
+      continue
+    }
+    field.Set = true
+    if string(value) == "null" {
+      field.Null = true
+      continue
+    }
+    if err := json.Unmarshal(value, &field.Value); err != nil {
+      return patch, fmt.Errorf("поле %s должно быть строкой или null", key)
+    }
+  }
+  if len(unknown) > 0 {
+    sort.Strings(unknown)
+    return patch, fmt.Errorf("неизвестные поля: %s", strings.Join(unknown, ", "))
+  }
+  return patch, nil
+}
+
+// GraphQLReq запрос GraphQL по соглашению GraphQL over HTTP
+type GraphQLReq struct {
+  Query         string                 `json:"query"`
+  OperationName string                 `json:"operationName"`
+  Variables     map[string]interface{} `json:"variables"`
+}
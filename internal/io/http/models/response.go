@@ -1,9 +1,7 @@
 package models
 
 import (
-  "fmt"
   "timetracker/internal/bl/dto"
-  "timetracker/internal/utils/var/endpoint"
 )
 
 type ErrorResponse struct {
@@ -20,6 +18,7 @@ type TaskResp struct {
   IdPerson   string  `json:"id_person"`
   TaskName   string  `json:"task_name,omitempty"`
   TaskStatus string  `json:"task_status"`
+  Version    int     `json:"version"`
   Urls       UrlTask `json:"urls"`
 }
 
@@ -29,27 +28,62 @@ type UrlTask struct {
   Complete string `json:"complete_task"`
 }
 
-func TaskFromDto(model *dto.Task) *TaskResp {
+// TaskFromDto ответ с задачей, urls - ссылки действий, построенные по маршрутам
+func TaskFromDto(model *dto.Task, urls UrlTask) *TaskResp {
   if model == nil {
     return nil
   }
-  Urls := UrlTask{
-    Start:    fmt.Sprintf("http://%s/people/%s/%s/start", endpoint.SRV, model.IdPerson, model.IdTask),
-    Pause:    fmt.Sprintf("http://%s/people/%s/%s/pause", endpoint.SRV, model.IdPerson, model.IdTask),
-    Complete: fmt.Sprintf("http://%s/people/%s/%s/complete", endpoint.SRV, model.IdPerson, model.IdTask),
-  }
   return &TaskResp{
     IdTask:     model.IdTask,
     IdPerson:   model.IdPerson,
     TaskName:   model.TaskName,
     TaskStatus: model.TaskStatus,
-    Urls:       Urls,
+    Version:    model.Version,
+    Urls:       urls,
   }
 }
 
 type PeopleResp struct {
-  Total  int          `json:"total"`
-  Limit  int          `json:"limit"`
-  Offset int          `json:"offset"`
-  People []dto.Person `json:"people"`
+  Total      *int         `json:"total,omitempty"`
+  Limit      int          `json:"limit"`
+  Offset     int          `json:"offset"`
+  NextCursor string       `json:"next_cursor,omitempty"`
+  People     []dto.Person `json:"people"`
+}
+
+type PeopleSearchResp struct {
+  Query   string            `json:"query"`
+  Results []dto.PersonMatch `json:"results"`
+}
+
+type TasksResp struct {
+  Total      *int       `json:"total,omitempty"`
+  Limit      int        `json:"limit"`
+  NextCursor string     `json:"next_cursor,omitempty"`
+  Tasks      []TaskResp `json:"tasks"`
+}
+
+type BatchResp struct {
+  Atomic    bool            `json:"atomic"`
+  Committed bool            `json:"committed"`
+  Results   []BatchOpResult `json:"results"`
+}
+
+// BatchOpResult итог операции пакета со статусом, который вернул бы отдельный запрос
+type BatchOpResult struct {
+  Index  int         `json:"index"`
+  Status int         `json:"status"`
+  Result interface{} `json:"result,omitempty"`
+  Error  string      `json:"error,omitempty"`
+}
+
+// GraphQLResp ответ GraphQL: данные и ошибки отдельных полей
+type GraphQLResp struct {
+  Data   interface{}    `json:"data"`
+  Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type GraphQLError struct {
+  Message string        `json:"message"`
+  Path    []interface{} `json:"path,omitempty"`
 }
@@ -4,7 +4,9 @@ import (
   "log/slog"
   "net/http"
   "timetracker/internal/bl"
+  "timetracker/internal/bl/repo"
   "timetracker/internal/io/http/handlers"
+  "timetracker/internal/io/http/links"
   "timetracker/internal/io/http/middlewares"
 )
 
@@ -12,35 +14,102 @@ type router struct {
   logger      *slog.Logger
   router      *http.ServeMux
   middlewares *middlewares.Mw
+  idem        repo.IIdempotencyBL
+  links       *links.Links
 }
 
-func InitRoutes(bl *bl.BL, logger *slog.Logger) http.Handler {
+// InitRoutes closing закрывается при выключении сервера и завершает открытые потоки событий
+func InitRoutes(bl *bl.BL, links *links.Links, logger *slog.Logger, closing <-chan struct{}) http.Handler {
   r := &router{
     logger:      logger,
     router:      http.NewServeMux(),
-    middlewares: middlewares.New(logger),
+    middlewares: middlewares.New(logger, bl.Auth),
+    idem:        bl.Idem,
+    links:       links,
   }
-  controller := handlers.NewController(bl, r.logger)
+  controller := handlers.NewController(bl, links, r.logger, closing)
   r.logger.Debug("init handler")
 
-  r.router.HandleFunc("GET /people", r.wrapHandler(controller.GetPeoples))
-  r.router.HandleFunc("POST /people", r.wrapHandler(controller.CreatePeople))
-  r.router.HandleFunc("DELETE /people/{uuid}", r.wrapHandler(controller.DeletePeople))
-  r.router.HandleFunc("GET /people/{uuid}", r.wrapHandler(controller.GetPeopleByUUID))
-  r.router.HandleFunc("PATCH /people/{uuid}", r.wrapHandler(controller.UpdatePeopleByUUID))
+  r.router.HandleFunc("GET /v1/people", r.wrapHandler(controller.GetPeoples))
+  r.router.HandleFunc("POST /v1/people", r.wrapHandler(controller.CreatePeople))
+  r.router.HandleFunc("GET /v1/people/search", r.wrapHandler(controller.SearchPeople))
+  r.router.HandleFunc("GET /v1/people/duplicates", r.wrapHandler(controller.GetDuplicates))
+  r.router.HandleFunc("GET /v1/people/{uuid}", r.wrapHandler(controller.GetPeopleByUUID))
+  r.router.HandleFunc("PATCH /v1/people/{uuid}", r.wrapHandler(controller.UpdatePeopleByUUID))
+  r.router.HandleFunc("DELETE /v1/people/{uuid}", r.wrapHandler(controller.DeletePeople))
+  r.router.HandleFunc("POST /v1/people/{uuid}/merge", r.wrapHandler(controller.MergePeople))
+  r.router.HandleFunc("GET /v1/people/{uuid}/tasks", r.wrapHandler(controller.GetTasks))
+  r.router.HandleFunc("POST /v1/people/{uuid}/tasks", r.wrapHandler(controller.CreateTask))
+  r.router.HandleFunc("POST /v1/people/{uuid}/worktime", r.wrapHandler(controller.WorkTime))
 
-  r.router.HandleFunc("POST /people/{uuid}/create-task", r.wrapHandler(controller.CreateTask))
+  r.router.HandleFunc(r.links.Route("task.start", "POST /v1/tasks/{uuidT}/start"), r.wrapHandler(controller.StartTimer))
+  r.router.HandleFunc(r.links.Route("task.pause", "POST /v1/tasks/{uuidT}/pause"), r.wrapHandler(controller.PauseTimer))
+  r.router.HandleFunc(r.links.Route("task.complete", "POST /v1/tasks/{uuidT}/complete"), r.wrapHandler(controller.CompleteTask))
+  r.router.HandleFunc("POST /v1/tasks/{uuidT}/entries", r.wrapHandler(controller.AddEntry))
 
-  r.router.HandleFunc("GET /people/{uuidP}/{uuidT}/start", r.wrapHandler(controller.StartTimer))
-  r.router.HandleFunc("GET /people/{uuidP}/{uuidT}/pause", r.wrapHandler(controller.PauseTimer))
-  r.router.HandleFunc("GET /people/{uuidP}/{uuidT}/complete", r.wrapHandler(controller.CompleteTask))
+  r.router.HandleFunc("POST /v1/batch", r.wrapHandler(controller.Batch))
+  r.router.HandleFunc("GET /v1/events", controller.Events)
+  r.router.HandleFunc("POST /graphql", r.wrapHandler(controller.GraphQL))
 
-  r.router.HandleFunc("POST /people/{uuid}/worktime", r.wrapHandler(controller.WorkTime))
+  r.router.HandleFunc("POST /v1/teams", r.wrapHandler(controller.CreateTeam))
+  r.router.HandleFunc("GET /v1/teams", r.wrapHandler(controller.GetTeams))
+  r.router.HandleFunc("GET /v1/teams/{uuid}", r.wrapHandler(controller.GetTeam))
+  r.router.HandleFunc("PATCH /v1/teams/{uuid}", r.wrapHandler(controller.UpdateTeam))
+  r.router.HandleFunc("DELETE /v1/teams/{uuid}", r.wrapHandler(controller.DeleteTeam))
+  r.router.HandleFunc("PUT /v1/teams/{uuid}/members/{uuidP}", r.wrapHandler(controller.AddTeamMember))
+  r.router.HandleFunc("DELETE /v1/teams/{uuid}/members/{uuidP}", r.wrapHandler(controller.RemoveTeamMember))
+  r.router.HandleFunc("POST /v1/teams/{uuid}/worktime", r.wrapHandler(controller.TeamWorkTime))
 
-  r.router.HandleFunc("GET /info", r.wrapHandler(controller.InfoPeople))
+  r.router.HandleFunc("POST /v1/admin/api-keys", r.wrapHandler(controller.CreateApiKey))
+  r.router.HandleFunc("GET /v1/admin/api-keys", r.wrapHandler(controller.GetApiKeys))
+  r.router.HandleFunc("DELETE /v1/admin/api-keys/{id}", r.wrapHandler(controller.DeleteApiKey))
+  r.router.HandleFunc("POST /v1/admin/organizations", r.wrapHandler(controller.CreateOrganization))
+  r.router.HandleFunc("GET /v1/admin/organizations", r.wrapHandler(controller.GetOrganizations))
+  r.router.HandleFunc("POST /v1/admin/webhooks", r.wrapHandler(controller.CreateWebhook))
+  r.router.HandleFunc("GET /v1/admin/webhooks", r.wrapHandler(controller.GetWebhooks))
+  r.router.HandleFunc("DELETE /v1/admin/webhooks/{id}", r.wrapHandler(controller.DeleteWebhook))
+  r.router.HandleFunc("GET /v1/admin/webhooks/{id}/deliveries", r.wrapHandler(controller.GetWebhookDeliveries))
+
+  r.router.HandleFunc(r.links.Route("people.info", "GET /info"), r.wrapHandler(controller.InfoPeople))
+
+  // маршруты без версии остаются для старых клиентов до удаления
+  r.router.HandleFunc("GET /people", r.legacy(controller.GetPeoples, "/v1/people"))
+  r.router.HandleFunc("POST /people", r.legacy(controller.CreatePeople, "/v1/people"))
+  r.router.HandleFunc("GET /people/search", r.legacy(controller.SearchPeople, "/v1/people/search"))
+  r.router.HandleFunc("GET /people/duplicates", r.legacy(controller.GetDuplicates, "/v1/people/duplicates"))
+  r.router.HandleFunc("POST /people/{uuid}/merge", r.legacy(controller.MergePeople, "/v1/people/{uuid}/merge"))
+  r.router.HandleFunc("DELETE /people/{uuid}", r.legacy(controller.DeletePeople, "/v1/people/{uuid}"))
+  r.router.HandleFunc("GET /people/{uuid}", r.legacy(controller.GetPeopleByUUID, "/v1/people/{uuid}"))
+  r.router.HandleFunc("PATCH /people/{uuid}", r.legacy(controller.UpdatePeopleByUUID, "/v1/people/{uuid}"))
+
+  r.router.HandleFunc("POST /people/{uuid}/create-task", r.legacy(controller.CreateTask, "/v1/people/{uuid}/tasks"))
+  r.router.HandleFunc("GET /people/{uuid}/tasks", r.legacy(controller.GetTasks, "/v1/people/{uuid}/tasks"))
+
+  r.router.HandleFunc(r.links.Route("legacy.task.start", "GET /people/{uuidP}/{uuidT}/start"), r.legacy(controller.StartTimer, "/v1/tasks/{uuidT}/start"))
+  r.router.HandleFunc(r.links.Route("legacy.task.pause", "GET /people/{uuidP}/{uuidT}/pause"), r.legacy(controller.PauseTimer, "/v1/tasks/{uuidT}/pause"))
+  r.router.HandleFunc(r.links.Route("legacy.task.complete", "GET /people/{uuidP}/{uuidT}/complete"), r.legacy(controller.CompleteTask, "/v1/tasks/{uuidT}/complete"))
+
+  r.router.HandleFunc("POST /people/{uuid}/worktime", r.legacy(controller.WorkTime, "/v1/people/{uuid}/worktime"))
+
+  r.router.HandleFunc("POST /teams", r.legacy(controller.CreateTeam, "/v1/teams"))
+  r.router.HandleFunc("GET /teams", r.legacy(controller.GetTeams, "/v1/teams"))
+  r.router.HandleFunc("GET /teams/{uuid}", r.legacy(controller.GetTeam, "/v1/teams/{uuid}"))
+  r.router.HandleFunc("PATCH /teams/{uuid}", r.legacy(controller.UpdateTeam, "/v1/teams/{uuid}"))
+  r.router.HandleFunc("DELETE /teams/{uuid}", r.legacy(controller.DeleteTeam, "/v1/teams/{uuid}"))
+  r.router.HandleFunc("PUT /teams/{uuid}/members/{uuidP}", r.legacy(controller.AddTeamMember, "/v1/teams/{uuid}/members/{uuidP}"))
+  r.router.HandleFunc("DELETE /teams/{uuid}/members/{uuidP}", r.legacy(controller.RemoveTeamMember, "/v1/teams/{uuid}/members/{uuidP}"))
+  r.router.HandleFunc("POST /teams/{uuid}/worktime", r.legacy(controller.TeamWorkTime, "/v1/teams/{uuid}/worktime"))
+
+  r.router.HandleFunc("POST /admin/api-keys", r.legacy(controller.CreateApiKey, "/v1/admin/api-keys"))
+  r.router.HandleFunc("GET /admin/api-keys", r.legacy(controller.GetApiKeys, "/v1/admin/api-keys"))
+  r.router.HandleFunc("DELETE /admin/api-keys/{id}", r.legacy(controller.DeleteApiKey, "/v1/admin/api-keys/{id}"))
+
+  r.router.HandleFunc("POST /admin/organizations", r.legacy(controller.CreateOrganization, "/v1/admin/organizations"))
+  r.router.HandleFunc("GET /admin/organizations", r.legacy(controller.GetOrganizations, "/v1/admin/organizations"))
 
   r.router.HandleFunc("/", r.wrapHandler(controller.NotFound))
-  muxN := use(r.router, r.middlewares.WithLogger)
+  // /info вызывается самим сервисом при создании человека
+  muxN := use(r.router, r.middlewares.WithAuth("/info"), r.middlewares.WithLogger)
 
   return muxN
 }
@@ -4,7 +4,9 @@ import (
   "context"
   "log/slog"
   "net/http"
+  "time"
   "timetracker/internal/bl"
+  "timetracker/internal/io/http/links"
 )
 
 type serv struct {
@@ -15,11 +17,23 @@ type serv struct {
   fin chan struct{}
 }
 
-func New(address string, log *slog.Logger, bl *bl.BL, fin chan struct{}) *serv {
+// Timeouts сроки соединений HTTP. Срока записи ответа нет: поток событий держит ответ открытым
+type Timeouts struct {
+  Read time.Duration
+  Idle time.Duration
+}
+
+func New(address string, timeouts Timeouts, log *slog.Logger, bl *bl.BL, links *links.Links, fin chan struct{}) *serv {
+  // Shutdown не отменяет контексты идущих запросов, и открытый поток событий
+  // держал бы выключение до конца срока. Потоки завершаются по закрытию closing
+  closing := make(chan struct{})
   srv := &http.Server{
-    Addr:    address,
-    Handler: InitRoutes(bl, log),
+    Addr:        address,
+    Handler:     InitRoutes(bl, links, log, closing),
+    ReadTimeout: timeouts.Read,
+    IdleTimeout: timeouts.Idle,
   }
+  srv.RegisterOnShutdown(func() { close(closing) })
   return &serv{
     l:   log.With(slog.String("layer", "serv")),
     srv: srv,
@@ -30,7 +44,7 @@ func New(address string, log *slog.Logger, bl *bl.BL, fin chan struct{}) *serv {
 func (s *serv) Run() {
   go func() {
     if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-      s.l.Error("listen: %s\n", err)
+      s.l.Error("listen", slog.String("error", err.Error()))
     }
   }()
 
@@ -38,7 +52,7 @@ func (s *serv) Run() {
 
 func (s *serv) Stop(ctx context.Context) {
   if err := s.srv.Shutdown(ctx); err != nil {
-    s.l.Error("Ошибка выключения сервера:", err)
+    s.l.Error("Ошибка выключения сервера", slog.String("error", err.Error()))
   }
   s.l.Info("Сервер успешно выключен")
 
@@ -1,20 +1,36 @@
 package http
 
 import (
+  "bytes"
+  "crypto/sha256"
+  "encoding/hex"
   "encoding/json"
+  "errors"
+  "io"
   "log/slog"
   "net/http"
+  "regexp"
   "time"
+  "timetracker/internal/bl/dto"
+  "timetracker/internal/bl/repo"
   "timetracker/internal/io/http/models"
 )
 
-func (r *router) wrapHandler(handler func(w http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error)) http.HandlerFunc {
+type handlerFunc func(w http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error)
+
+func (r *router) wrapHandler(handler handlerFunc) http.HandlerFunc {
   return func(w http.ResponseWriter, req *http.Request) {
 
-    res, status, attr, err := handler(w, req)
+    res, status, attr, err := r.idempotent(handler)(w, req)
     ctxLogger := req.Context().Value("logger").(*slog.Logger)
     startTime := req.Context().Value("startTime").(time.Time)
 
+    if status == http.StatusNotModified {
+      // ответ 304 не содержит тела
+      w.WriteHeader(status)
+      ctxLogger.Info("reqDone", slog.Any("duration", time.Since(startTime).String()), slog.Int("status", status))
+      return
+    }
     w.Header().Add("Content-Type", "application/json")
     w.WriteHeader(status)
     if err != nil {
@@ -33,3 +49,90 @@ func (r *router) wrapHandler(handler func(w http.ResponseWriter, req *http.Reque
     }
   }
 }
+
+var pathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// legacy обертка маршрута без версии: ответ помечается заголовком Deprecation,
+// в Link указывается замена в /v1, successor - ее шаблон с параметрами пути маршрута
+func (r *router) legacy(handler handlerFunc, successor string) http.HandlerFunc {
+  wrapped := r.wrapHandler(handler)
+  return func(w http.ResponseWriter, req *http.Request) {
+    path := pathParam.ReplaceAllStringFunc(successor, func(param string) string {
+      return req.PathValue(param[1 : len(param)-1])
+    })
+    w.Header().Set("Deprecation", "true")
+    w.Header().Set("Link", "<"+path+`>; rel="successor-version"`)
+    wrapped(w, req)
+  }
+}
+
+// idempotent для POST, PATCH и DELETE с заголовком Idempotency-Key сохраняет ответ обработчика
+// и отдает его же на повторы запроса с тем же ключом. Тот же ключ с другим запросом - 422
+func (r *router) idempotent(handler handlerFunc) handlerFunc {
+  return func(w http.ResponseWriter, req *http.Request) (interface{}, int, slog.Attr, error) {
+    key := req.Header.Get("Idempotency-Key")
+    if key == "" || (req.Method != http.MethodPost && req.Method != http.MethodPatch && req.Method != http.MethodDelete) {
+      return handler(w, req)
+    }
+    attr := slog.String("idempotencyKey", key)
+
+    body, err := io.ReadAll(req.Body)
+    if err != nil {
+      return nil, http.StatusBadRequest, attr, err
+    }
+    req.Body = io.NopCloser(bytes.NewReader(body))
+    sum := sha256.Sum256(append([]byte(req.Method+" "+req.URL.RequestURI()+"\n"), body...))
+
+    stored, err := r.idem.Begin(req.Context(), key, hex.EncodeToString(sum[:]))
+    switch {
+    case errors.Is(err, repo.ErrKeyReused):
+      return nil, http.StatusUnprocessableEntity, attr, err
+    case errors.Is(err, repo.ErrKeyInProgress):
+      return nil, http.StatusConflict, attr, err
+    case errors.Is(err, repo.ErrInvalid):
+      return nil, http.StatusBadRequest, attr, err
+    case err != nil:
+      return nil, http.StatusInternalServerError, attr, err
+    case stored != nil:
+      for name, values := range stored.Headers {
+        w.Header()[name] = values
+      }
+      w.Header().Set("Idempotent-Replayed", "true")
+      return json.RawMessage(stored.Body), stored.Status, slog.Group("replay", attr), nil
+    }
+
+    release := func() {
+      if err := r.idem.Release(req.Context(), key); err != nil {
+        r.logger.Error("ошибка освобождения ключа идемпотентности", slog.String("error", err.Error()))
+      }
+    }
+    // при панике обработчика ключ тоже освобождается, иначе повторы получали бы 409
+    // до истечения ключа. Паника идет дальше, как и в запросе без ключа
+    defer func() {
+      if p := recover(); p != nil {
+        release()
+        panic(p)
+      }
+    }()
+
+    res, status, hattr, herr := handler(w, req)
+    // ошибки сервера не сохраняются, такой запрос можно повторить с тем же ключом
+    if status >= http.StatusInternalServerError {
+      release()
+      return res, status, hattr, herr
+    }
+    if herr != nil {
+      res = models.ErrorResponse{Error: herr.Error()}
+    }
+    resBytes, _ := json.Marshal(res)
+    err = r.idem.Complete(req.Context(), key, &dto.IdempotentResponse{
+      Status:  status,
+      Headers: w.Header().Clone(),
+      Body:    resBytes,
+    })
+    if err != nil {
+      r.logger.Error("ошибка сохранения ответа по ключу идемпотентности", slog.String("error", err.Error()))
+    }
+    return res, status, hattr, herr
+  }
+}
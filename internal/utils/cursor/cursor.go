@@ -0,0 +1,29 @@
+package cursor
+
+import (
+  "encoding/base64"
+  "encoding/json"
+  "errors"
+)
+
+// ErrInvalid курсор не удалось разобрать, это ошибка клиента
+var ErrInvalid = errors.New("некорректный курсор")
+
+// Encode упаковывает значения ключа сортировки последней записи страницы в непрозрачную строку
+func Encode(values ...string) string {
+  data, _ := json.Marshal(values)
+  return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode распаковывает курсор, ожидая ровно n значений
+func Decode(cursor string, n int) ([]string, error) {
+  data, err := base64.RawURLEncoding.DecodeString(cursor)
+  if err != nil {
+    return nil, ErrInvalid
+  }
+  var values []string
+  if err = json.Unmarshal(data, &values); err != nil || len(values) != n {
+    return nil, ErrInvalid
+  }
+  return values, nil
+}
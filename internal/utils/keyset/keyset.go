@@ -0,0 +1,132 @@
+package keyset
+
+import (
+  "bytes"
+  "crypto/rsa"
+  "errors"
+  "fmt"
+  "github.com/golang-jwt/jwt/v5"
+  "os"
+  "path/filepath"
+  "strings"
+)
+
+// Claims набор утверждений токена, которые понимает сервис
+type Claims struct {
+  jwt.RegisteredClaims
+  Name string `json:"name"`
+  Role string `json:"role"`
+  Org  string `json:"org"`
+}
+
+// KeySet ключи проверки подписи JWT, загруженные из локального каталога:
+// <kid>.secret содержит секрет HS256, <kid>.pem открытый ключ RS256
+type KeySet struct {
+  hmac     map[string][]byte
+  rsa      map[string]*rsa.PublicKey
+  issuer   string
+  audience string
+}
+
+func Load(dir, issuer, audience string) (*KeySet, error) {
+  ks := &KeySet{
+    hmac:     map[string][]byte{},
+    rsa:      map[string]*rsa.PublicKey{},
+    issuer:   issuer,
+    audience: audience,
+  }
+  if dir == "" {
+    return ks, nil
+  }
+
+  entries, err := os.ReadDir(dir)
+  if err != nil {
+    return nil, fmt.Errorf("ошибка чтения каталога ключей: %w", err)
+  }
+  for _, entry := range entries {
+    if entry.IsDir() {
+      continue
+    }
+    ext := filepath.Ext(entry.Name())
+    kid := strings.TrimSuffix(entry.Name(), ext)
+    if ext != ".secret" && ext != ".pem" {
+      continue
+    }
+    data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+    if err != nil {
+      return nil, fmt.Errorf("ошибка чтения ключа %s: %w", entry.Name(), err)
+    }
+    switch ext {
+    case ".secret":
+      secret := bytes.TrimSpace(data)
+      if len(secret) < 32 {
+        return nil, fmt.Errorf("секрет %s короче 32 байт", entry.Name())
+      }
+      ks.hmac[kid] = secret
+    case ".pem":
+      key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+      if err != nil {
+        return nil, fmt.Errorf("ошибка разбора ключа %s: %w", entry.Name(), err)
+      }
+      ks.rsa[kid] = key
+    }
+  }
+  return ks, nil
+}
+
+// Empty true, если не загружено ни одного ключа и JWT не принимаются
+func (k *KeySet) Empty() bool {
+  return k == nil || len(k.hmac)+len(k.rsa) == 0
+}
+
+// Parse проверяет подпись и срок действия токена
+func (k *KeySet) Parse(token string) (*Claims, error) {
+  if k.Empty() {
+    return nil, errors.New("проверка JWT не настроена")
+  }
+  opts := []jwt.ParserOption{
+    jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+    jwt.WithExpirationRequired(),
+  }
+  if k.issuer != "" {
+    opts = append(opts, jwt.WithIssuer(k.issuer))
+  }
+  if k.audience != "" {
+    opts = append(opts, jwt.WithAudience(k.audience))
+  }
+
+  var claims Claims
+  _, err := jwt.ParseWithClaims(token, &claims, k.key, opts...)
+  if err != nil {
+    return nil, fmt.Errorf("токен не прошел проверку: %w", err)
+  }
+  return &claims, nil
+}
+
+func (k *KeySet) key(token *jwt.Token) (interface{}, error) {
+  kid, _ := token.Header["kid"].(string)
+  switch token.Method.Alg() {
+  case jwt.SigningMethodHS256.Alg():
+    return pick(k.hmac, kid)
+  case jwt.SigningMethodRS256.Alg():
+    return pick(k.rsa, kid)
+  }
+  return nil, fmt.Errorf("алгоритм %s не поддерживается", token.Method.Alg())
+}
+
+func pick[T any](keys map[string]T, kid string) (interface{}, error) {
+  if kid != "" {
+    key, ok := keys[kid]
+    if !ok {
+      return nil, fmt.Errorf("неизвестный kid %s", kid)
+    }
+    return key, nil
+  }
+  if len(keys) != 1 {
+    return nil, errors.New("в заголовке токена нет kid")
+  }
+  for _, key := range keys {
+    return key, nil
+  }
+  return nil, errors.New("ключ не найден")
+}
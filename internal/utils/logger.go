@@ -0,0 +1,15 @@
+package utils
+
+import (
+  "context"
+  "log/slog"
+)
+
+// LoggerFromCtx логгер запроса, который кладут в контекст middleware HTTP и интерцепторы gRPC.
+// Без него, например в пакетных операциях и тестах, возвращается логгер по умолчанию
+func LoggerFromCtx(ctx context.Context) *slog.Logger {
+  if log, ok := ctx.Value("logger").(*slog.Logger); ok {
+    return log
+  }
+  return slog.Default()
+}
@@ -0,0 +1,18 @@
+package utils
+
+import (
+  "context"
+  "timetracker/internal/bl/dto"
+)
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *dto.Principal) context.Context {
+  return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromCtx возвращает вызывающего из контекста, nil для анонимного запроса
+func PrincipalFromCtx(ctx context.Context) *dto.Principal {
+  p, _ := ctx.Value(principalKey{}).(*dto.Principal)
+  return p
+}
@@ -0,0 +1,15 @@
+package utils
+
+import "context"
+
+type tenantKey struct{}
+
+// WithTenant кладет в контекст организацию, которой ограничены запросы к бд
+func WithTenant(ctx context.Context, organizationID string) context.Context {
+  return context.WithValue(ctx, tenantKey{}, organizationID)
+}
+
+func TenantFromCtx(ctx context.Context) string {
+  org, _ := ctx.Value(tenantKey{}).(string)
+  return org
+}
@@ -1,15 +1,5 @@
 package endpoint
 
-import "os"
-
-var SRV string
+// GetInfoUrl адрес /info, у которого сервис запрашивает данные нового человека.
+// Задается при запуске по внешнему адресу сервиса или адресу HTTP сервера
 var GetInfoUrl string
-
-func init() {
-
-  SRV = os.Getenv("HOST") + ":" + os.Getenv("PORT")
-  if SRV == "" {
-    panic("Переменная окружения HOST или PORT не установлены")
-  }
-  GetInfoUrl = "http://" + SRV + "/info"
-}